@@ -14,16 +14,32 @@ package common
 
 import (
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type KratosParameters struct {
-	ClientConfig               *rest.Config
-	Client                     client.Client
-	RestMapper                 meta.RESTMapper
-	EventRecorder              record.EventRecorder
-	DefaultPrometheusUrl       string
-	StabilizationWindowSeconds int32
+	ClientConfig                *rest.Config
+	Client                      client.Client
+	APIReader                   client.Reader
+	RestMapper                  meta.RESTMapper
+	EventRecorder               record.EventRecorder
+	DefaultPrometheusUrl        string
+	DefaultPrometheusAuthSecret types.NamespacedName
+	StabilizationWindowSeconds  int32
+	DryRun                      bool
+	DecisionHistory             int32
+	Clock                       clock.Clock
+}
+
+// GetClock returns the clock used for all time dependent decisions, the real clock when none is set
+func (p *KratosParameters) GetClock() clock.Clock {
+	if p.Clock == nil {
+		return clock.RealClock{}
+	}
+
+	return p.Clock
 }
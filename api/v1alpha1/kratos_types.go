@@ -26,8 +26,9 @@ type KratosSpec struct {
 	Algorithm Algorithm `json:"algorithm" protobuf:"bytes,2,opt,name=algorithm"`
 
 	// minReplicas is the lower limit for the number of replicas to which the autoscaler
-	// can scale down.  It defaults to 1 pod.
-	MinReplicas int32 `json:"minReplicas,omitempty" protobuf:"varint,3,opt,name=minReplicas"`
+	// can scale down.  It defaults to 1 pod, 0 scales the target to zero when its metrics are idle.
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty" protobuf:"varint,3,opt,name=minReplicas"`
 
 	// upper limit for the number of pods that can be set by the autoscaler; cannot be smaller than MinReplicas.
 	MaxReplicas int32 `json:"maxReplicas" protobuf:"varint,4,opt,name=maxReplicas"`
@@ -40,6 +41,25 @@ type KratosSpec struct {
 
 	// Up or Down scaling behavior
 	Behavior *ScaleBehavior `json:"behavior,omitempty" protobuf:"bytes,7,opt,name=behavior"`
+
+	// replicas to use when metrics can't be evaluated. Defaults to holding the current replicas.
+	// +optional
+	Fallback *Fallback `json:"fallback,omitempty" protobuf:"bytes,8,opt,name=fallback"`
+
+	// schedules override the replica limits while they're active. The first active schedule of the list is applied.
+	// +optional
+	Schedules []Schedule `json:"schedules,omitempty" protobuf:"bytes,9,rep,name=schedules"`
+
+	// seconds all metrics must stay at or below their activation threshold before the target is scaled to zero.
+	// Only used when minReplicas is 0. Defaults to 300.
+	// +optional
+	IdleCooldownSeconds int32 `json:"idleCooldownSeconds,omitempty" protobuf:"varint,10,opt,name=idleCooldownSeconds"`
+
+	// mode of the autoscaler, in DryRun mode replicas are calculated and reported but the target is never scaled.
+	// Defaults to Active.
+	// +kubebuilder:validation:Enum=Active;DryRun
+	// +optional
+	Mode AutoscalerMode `json:"mode,omitempty" protobuf:"bytes,11,opt,name=mode,casttype=AutoscalerMode"`
 }
 
 // KratosStatus defines the observed state of Kratos
@@ -62,8 +82,116 @@ type KratosStatus struct {
 
 	//scale down events
 	ScaleDownEvents []ScaleChangeEvent `json:"scaleDownEvents" protobuf:"varint,6,opt,name=scaleDownEvents"`
+
+	//conditions describe the current state of the autoscaler
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" protobuf:"bytes,7,rep,name=conditions"`
+
+	//number of consecutive evaluations in which no metric could be evaluated
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty" protobuf:"varint,8,opt,name=consecutiveFailures"`
+
+	//last evaluation of every metric of the spec
+	// +optional
+	CurrentMetrics []MetricStatus `json:"currentMetrics,omitempty" protobuf:"bytes,9,rep,name=currentMetrics"`
+
+	//name of the schedule overriding the replica limits in the last evaluation
+	// +optional
+	ActiveSchedule string `json:"activeSchedule,omitempty" protobuf:"bytes,10,opt,name=activeSchedule"`
+
+	//time since all metrics are at or below their activation threshold
+	// +optional
+	IdleSince *metav1.Time `json:"idleSince,omitempty" protobuf:"bytes,11,opt,name=idleSince"`
+
+	//true when the target is scaled to zero and waits for a metric above its activation threshold
+	// +optional
+	Parked bool `json:"parked,omitempty" protobuf:"varint,12,opt,name=parked"`
+
+	//last scale operation skipped in DryRun mode
+	// +optional
+	DryRunScale *DryRunScale `json:"dryRunScale,omitempty" protobuf:"bytes,13,opt,name=dryRunScale"`
+
+	//explanation of the last scaling decisions, the newest is last
+	// +optional
+	Decisions []ScalingDecision `json:"decisions,omitempty" protobuf:"bytes,14,rep,name=decisions"`
+
+	//time of the last scale operation applied to the target
+	// +optional
+	LastScaleTime *metav1.Time `json:"lastScaleTime,omitempty" protobuf:"bytes,15,opt,name=lastScaleTime"`
 }
 
+// MetricStatus describes the last evaluation of a single metric
+type MetricStatus struct {
+	// type of the metric
+	Type MetricType `json:"type" protobuf:"bytes,1,name=type"`
+	// name of the metric, the resource name for Resource metrics and the query for Prometheus metrics
+	Name string `json:"name" protobuf:"bytes,2,name=name"`
+	// sum of the last fetched values of the metric
+	// +optional
+	Value *resource.Quantity `json:"value,omitempty" protobuf:"bytes,3,opt,name=value"`
+	// replicas proposed by the algorithm for this metric
+	// +optional
+	ProposedReplicas *int32 `json:"proposedReplicas,omitempty" protobuf:"varint,4,opt,name=proposedReplicas"`
+	// error on fetching or evaluating the metric
+	// +optional
+	Error string `json:"error,omitempty" protobuf:"bytes,5,opt,name=error"`
+	// forecast of the metric when prediction is configured
+	// +optional
+	Forecast *ForecastStatus `json:"forecast,omitempty" protobuf:"bytes,6,opt,name=forecast"`
+	// query of Prometheus metrics with the placeholders of the query template expanded
+	// +optional
+	Query string `json:"query,omitempty" protobuf:"bytes,7,opt,name=query"`
+}
+
+// ForecastStatus describes the last forecast of a metric
+type ForecastStatus struct {
+	// model used for the forecast
+	Model PredictionModel `json:"model" protobuf:"bytes,1,opt,name=model"`
+	// forecasted value of the metric
+	// +optional
+	Value *resource.Quantity `json:"value,omitempty" protobuf:"bytes,2,opt,name=value"`
+	// time the forecasted value is expected at
+	// +optional
+	Time *metav1.Time `json:"time,omitempty" protobuf:"bytes,3,opt,name=time"`
+	// confidence in percent, derived from the error of the model over the last season of history
+	// +optional
+	Confidence int32 `json:"confidence,omitempty" protobuf:"varint,4,opt,name=confidence"`
+	// replicas proposed by the algorithm for the forecasted value
+	// +optional
+	ProposedReplicas *int32 `json:"proposedReplicas,omitempty" protobuf:"varint,5,opt,name=proposedReplicas"`
+	// true when the forecast proposal was combined with the proposal for the current value
+	// +optional
+	Applied bool `json:"applied,omitempty" protobuf:"varint,6,opt,name=applied"`
+	// error on fetching the history or forecasting
+	// +optional
+	Error string `json:"error,omitempty" protobuf:"bytes,7,opt,name=error"`
+}
+
+const (
+	// AbleToScaleCondition indicates that the scale target can be retrieved and updated
+	AbleToScaleCondition = "AbleToScale"
+	// ScalingActiveCondition indicates that the autoscaler is able to calculate replicas for its target
+	ScalingActiveCondition = "ScalingActive"
+	// ScalingLimitedCondition indicates that the desired replicas are limited by min/max replicas or behavior policies
+	ScalingLimitedCondition = "ScalingLimited"
+	// MetricsAvailableCondition indicates that all metrics of the spec were fetched and evaluated
+	MetricsAvailableCondition = "MetricsAvailable"
+	// FallbackCondition indicates that replicas are set by the fallback policy because metrics can't be evaluated
+	FallbackCondition = "Fallback"
+	// ConflictCondition indicates that another autoscaler or a HorizontalPodAutoscaler controls the same scale target
+	ConflictCondition = "Conflict"
+)
+
+// AllowTakeoverAnnotation set to "true" on a Kratos resource or ConfigMap lets it scale a target controlled by a
+// HorizontalPodAutoscaler or by another autoscaler without the annotation
+const AllowTakeoverAnnotation = "scaling.core.adobe.com/allow-takeover"
+
+// KratosSpecLabel set to "true" on a ConfigMap with a 'kratosSpec' key has the spec checked by the validating webhook,
+// the webhook doesn't receive other ConfigMaps
+const KratosSpecLabel = "scaling.core.adobe.com/kratos-spec"
+
 // ScalingTargetReference identifies target to scale
 type ScaleTargetReference struct {
 	Kind string `json:"kind" protobuf:"bytes,1,opt,name=kind"`
@@ -75,10 +203,20 @@ type ScaleTargetReference struct {
 
 // Algorithm to use for scale
 type Algorithm struct {
-	Type    string            `json:"type" protobuf:"bytes,1,opt,name=type"`
+	// type of the algorithm used to calculate replicas from metric values, e.g. hpa or step. Defaults to hpa.
+	Type string `json:"type" protobuf:"bytes,1,opt,name=type"`
+	// options passed to the algorithm, validated by the selected algorithm
+	// +optional
 	Options map[string]string `json:"options,omitempty" protobuf:"bytes,1,opt,name=options"`
 }
 
+const (
+	// HpaAlgorithmType calculates replicas the same way as the HorizontalPodAutoscaler
+	HpaAlgorithmType = "hpa"
+	// StepAlgorithmType calculates replicas like hpa and rounds them up to a multiple of a step
+	StepAlgorithmType = "step"
+)
+
 type MetricType string
 
 const (
@@ -87,6 +225,8 @@ const (
 	ObjectScaleMetricType     MetricType = "Object"
 	ExternalScaleMetricType   MetricType = "External"
 	PrometheusScaleMetricType MetricType = "Prometheus"
+	KafkaScaleMetricType      MetricType = "Kafka"
+	RabbitMQScaleMetricType   MetricType = "RabbitMQ"
 )
 
 type ScaleMetric struct {
@@ -120,6 +260,14 @@ type ScaleMetric struct {
 	External *ExternalMetricSource `json:"external,omitempty" protobuf:"bytes,5,opt,name=external"`
 
 	Prometheus *PrometheusMetricSource `json:"prometheus,omitempty" protobuf:"bytes,6,opt,name=prometheus"`
+
+	// kafka refers to the lag of a consumer group on Kafka topics
+	// +optional
+	Kafka *KafkaMetricSource `json:"kafka,omitempty" protobuf:"bytes,7,opt,name=kafka"`
+
+	// rabbitmq refers to the depth or message rates of a RabbitMQ queue
+	// +optional
+	RabbitMQ *RabbitMQMetricSource `json:"rabbitmq,omitempty" protobuf:"bytes,8,opt,name=rabbitmq"`
 }
 
 // ResourceMetricSource indicates how to scale on a resource metric known to
@@ -173,15 +321,180 @@ type ExternalMetricSource struct {
 type PrometheusMetricSource struct {
 
 	// Metrics query in PromQL language. Must return single value.
+	// Go template placeholders are expanded before the query is run: {{ .Namespace }}, {{ .TargetKind }}, {{ .TargetName }},
+	// {{ .Replicas }} and {{ .Selector }}, the pod selector of the target as PromQL label matchers.
 	MetricQuery string `json:"metricQuery" protobuf:"bytes,1,name=metricQuery"`
 
 	// target specifies the target value for the given metric
 	Target MetricTarget `json:"target" protobuf:"bytes,2,name=target"`
 
 	//Prometheus endpoint for retrieving metrics. Default to global setting set at the Operator level
-	PrometheusEndpoint string `json:"prometheusEndpoint" protobuf:"bytes,3,name=prometheusEndpoint"`
+	// +optional
+	PrometheusEndpoint string `json:"prometheusEndpoint,omitempty" protobuf:"bytes,3,name=prometheusEndpoint"`
+
+	// prediction forecasts the value of the query from its history and proposes replicas for the expected value
+	// +optional
+	Prediction *Prediction `json:"prediction,omitempty" protobuf:"bytes,4,opt,name=prediction"`
+
+	// name of a Secret in the namespace of the autoscaler with the credentials, TLS settings and headers of the Prometheus endpoint.
+	// Keys: token, username, password, ca.crt, tls.crt, tls.key, insecureSkipVerify and header.<name> for extra headers.
+	// Defaults to the secret set at the Operator level when the default endpoint is used.
+	// +optional
+	AuthSecretName string `json:"authSecretName,omitempty" protobuf:"bytes,5,opt,name=authSecretName"`
+
+	// range runs the query as a range query over a window and reduces every returned series to a single value,
+	// e.g. to smooth a noisy signal without a subquery
+	// +optional
+	Range *PrometheusRange `json:"range,omitempty" protobuf:"bytes,6,opt,name=range"`
 }
 
+// PrometheusRange configures the evaluation of a Prometheus query over a window ending at the evaluation time
+type PrometheusRange struct {
+	// length of the window, e.g. 5m
+	Window metav1.Duration `json:"window" protobuf:"bytes,1,name=window"`
+	// resolution of the range query, at most 1000 steps per window. Defaults to a tenth of the window.
+	// +optional
+	Step metav1.Duration `json:"step,omitempty" protobuf:"bytes,2,opt,name=step"`
+	// aggregation reducing the values of every series in the window. Defaults to Avg.
+	// +kubebuilder:validation:Enum=Avg;Max;P95;Last;EWMA
+	// +optional
+	Aggregation Aggregation `json:"aggregation,omitempty" protobuf:"bytes,3,opt,name=aggregation"`
+	// half-life of the EWMA aggregation, the weight of a value halves every half-life. Defaults to a quarter of the window.
+	// +optional
+	HalfLife metav1.Duration `json:"halfLife,omitempty" protobuf:"bytes,4,opt,name=halfLife"`
+}
+
+// Aggregation specifies how the values of a series in a window are reduced to a single value
+type Aggregation string
+
+const (
+	// AvgAggregation is the mean of the values.
+	AvgAggregation Aggregation = "Avg"
+	// MaxAggregation is the highest value.
+	MaxAggregation Aggregation = "Max"
+	// P95Aggregation is the 95th percentile of the values.
+	P95Aggregation Aggregation = "P95"
+	// LastAggregation is the most recent value.
+	LastAggregation Aggregation = "Last"
+	// EWMAAggregation is the exponentially weighted moving average of the values, recent values weigh more.
+	EWMAAggregation Aggregation = "EWMA"
+)
+
+// KafkaMetricSource indicates how to scale on the lag of a consumer group, the number of messages
+// in the topics the group hasn't committed yet. Proposals are capped at the number of partitions
+// of the topics as consumers beyond that don't get a partition assigned.
+type KafkaMetricSource struct {
+	// bootstrap brokers of the cluster, e.g. kafka-0.kafka:9092
+	Brokers []string `json:"brokers" protobuf:"bytes,1,rep,name=brokers"`
+	// consumer group whose lag is fetched
+	ConsumerGroup string `json:"consumerGroup" protobuf:"bytes,2,name=consumerGroup"`
+	// topics consumed by the group
+	Topics []string `json:"topics" protobuf:"bytes,3,rep,name=topics"`
+	// lag calculated from the partitions of the topics, Total sums the lag of all partitions while Partition
+	// is the highest lag of a single partition, e.g. to keep every partition below a lag. Defaults to Total.
+	// +kubebuilder:validation:Enum=Total;Partition
+	// +optional
+	Lag KafkaLagMode `json:"lag,omitempty" protobuf:"bytes,4,opt,name=lag"`
+	// name of a Secret in the namespace of the autoscaler with the SASL and TLS settings of the brokers.
+	// Keys: sasl.mechanism (PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512), username, password, tls, ca.crt, tls.crt, tls.key and insecureSkipVerify.
+	// +optional
+	AuthSecretName string `json:"authSecretName,omitempty" protobuf:"bytes,5,opt,name=authSecretName"`
+	// target specifies the target value for the given metric
+	Target MetricTarget `json:"target" protobuf:"bytes,6,name=target"`
+}
+
+// KafkaLagMode specifies how the lag of the partitions of a consumer group is reported
+type KafkaLagMode string
+
+const (
+	// TotalKafkaLag is the sum of the lag of all partitions.
+	TotalKafkaLag KafkaLagMode = "Total"
+	// PartitionKafkaLag is the highest lag of a single partition.
+	PartitionKafkaLag KafkaLagMode = "Partition"
+)
+
+// RabbitMQMetricSource indicates how to scale on a RabbitMQ queue, its depth or message rates are read from the
+// management HTTP API. Workers are usually scaled on the messages per pod with an AverageValue target.
+type RabbitMQMetricSource struct {
+	// url of the management API, e.g. http://rabbitmq.messaging:15672
+	ManagementUrl string `json:"managementUrl" protobuf:"bytes,1,name=managementUrl"`
+	// name of the queue
+	Queue string `json:"queue" protobuf:"bytes,2,name=queue"`
+	// value of the queue, Messages is the depth including unacknowledged messages, MessagesReady the messages
+	// waiting for a consumer, PublishRate and DeliverRate the messages per second published to and delivered from
+	// the queue. Defaults to Messages.
+	// +kubebuilder:validation:Enum=Messages;MessagesReady;PublishRate;DeliverRate
+	// +optional
+	QueueMetric RabbitMQQueueMetric `json:"queueMetric,omitempty" protobuf:"bytes,3,opt,name=queueMetric"`
+	// name of a Secret in the namespace of the autoscaler with the credentials and vhost of the queue.
+	// Keys: username, password, vhost (defaults to /), ca.crt, tls.crt, tls.key and insecureSkipVerify.
+	AuthSecretName string `json:"authSecretName" protobuf:"bytes,4,name=authSecretName"`
+	// target specifies the target value for the given metric
+	Target MetricTarget `json:"target" protobuf:"bytes,5,name=target"`
+}
+
+// RabbitMQQueueMetric specifies the value of a RabbitMQ queue used for scaling
+type RabbitMQQueueMetric string
+
+const (
+	// MessagesRabbitMQQueueMetric is the number of ready and unacknowledged messages.
+	MessagesRabbitMQQueueMetric RabbitMQQueueMetric = "Messages"
+	// MessagesReadyRabbitMQQueueMetric is the number of messages ready to be delivered.
+	MessagesReadyRabbitMQQueueMetric RabbitMQQueueMetric = "MessagesReady"
+	// PublishRateRabbitMQQueueMetric is the number of messages published to the queue per second.
+	PublishRateRabbitMQQueueMetric RabbitMQQueueMetric = "PublishRate"
+	// DeliverRateRabbitMQQueueMetric is the number of messages delivered or fetched from the queue per second.
+	DeliverRateRabbitMQQueueMetric RabbitMQQueueMetric = "DeliverRate"
+)
+
+// Prediction configures a seasonal forecast of a Prometheus metric from its history fetched with range queries
+type Prediction struct {
+	// mode of the prediction, Observe only reports the forecast in status while Scale also uses its proposal.
+	// Defaults to Observe.
+	// +kubebuilder:validation:Enum=Observe;Scale
+	// +optional
+	Mode PredictionMode `json:"mode,omitempty" protobuf:"bytes,1,opt,name=mode"`
+	// model used for the forecast. Defaults to SeasonalNaive.
+	// +kubebuilder:validation:Enum=SeasonalNaive;HoltWinters
+	// +optional
+	Model PredictionModel `json:"model,omitempty" protobuf:"bytes,2,opt,name=model"`
+	// length of a season of the metric, e.g. 24h for daily or 168h for weekly patterns. Defaults to 24h.
+	// +optional
+	Season metav1.Duration `json:"season,omitempty" protobuf:"bytes,3,opt,name=season"`
+	// number of past seasons fetched as history, at least 2. Defaults to 2.
+	// +optional
+	HistorySeasons int32 `json:"historySeasons,omitempty" protobuf:"varint,4,opt,name=historySeasons"`
+	// resolution of the history, the season must be a multiple of it. Defaults to 5m.
+	// +optional
+	Step metav1.Duration `json:"step,omitempty" protobuf:"bytes,5,opt,name=step"`
+	// how far ahead of the evaluation the value is forecasted, e.g. the time new pods need to get ready. Defaults to the step.
+	// +optional
+	LeadTime metav1.Duration `json:"leadTime,omitempty" protobuf:"bytes,6,opt,name=leadTime"`
+	// minimum confidence in percent required to use the forecast in Scale mode
+	// +optional
+	MinConfidence int32 `json:"minConfidence,omitempty" protobuf:"varint,7,opt,name=minConfidence"`
+}
+
+// PredictionMode specifies whether the forecast is used for scaling
+type PredictionMode string
+
+const (
+	// ObservePredictionMode reports the forecast in status without using it for scaling.
+	ObservePredictionMode PredictionMode = "Observe"
+	// ScalePredictionMode uses the highest of the forecast and the current value proposals.
+	ScalePredictionMode PredictionMode = "Scale"
+)
+
+// PredictionModel specifies the model used to forecast a metric
+type PredictionModel string
+
+const (
+	// SeasonalNaivePredictionModel forecasts the average of the values at the same time in the past seasons.
+	SeasonalNaivePredictionModel PredictionModel = "SeasonalNaive"
+	// HoltWintersPredictionModel forecasts with additive triple exponential smoothing.
+	HoltWintersPredictionModel PredictionModel = "HoltWinters"
+)
+
 // MetricIdentifier defines the name and optionally selector for a metric
 type MetricIdentifier struct {
 	// name is the name of the given metric
@@ -223,6 +536,10 @@ type MetricTarget struct {
 	// Currently only valid for Resource metric source type
 	// +optional
 	AverageUtilization *int32 `json:"averageUtilization,omitempty" protobuf:"bytes,4,opt,name=averageUtilization"`
+	// activationThreshold is the value of the metric above which a target scaled to zero is activated
+	// and below which it's considered idle. Only used when minReplicas is 0. Defaults to 0.
+	// +optional
+	ActivationThreshold *resource.Quantity `json:"activationThreshold,omitempty" protobuf:"bytes,5,opt,name=activationThreshold"`
 }
 
 type ScaleBehavior struct {
@@ -261,6 +578,142 @@ const (
 	DisabledPolicySelect ScalingPolicySelect = "Disabled"
 )
 
+// Fallback defines the replicas used after consecutive evaluations in which no metric could be evaluated
+type Fallback struct {
+	// policy applied once failureThreshold is reached. Until then the current replicas are kept.
+	// +kubebuilder:validation:Enum=Hold;Replicas;Max
+	Policy FallbackPolicy `json:"policy" protobuf:"bytes,1,opt,name=policy"`
+	// replicas to scale to with the Replicas policy, bounded by min and max replicas
+	// +optional
+	Replicas int32 `json:"replicas,omitempty" protobuf:"varint,2,opt,name=replicas"`
+	// number of consecutive failed evaluations before the policy is applied. Defaults to 3.
+	// +optional
+	FailureThreshold int32 `json:"failureThreshold,omitempty" protobuf:"varint,3,opt,name=failureThreshold"`
+}
+
+// Schedule overrides the replica limits during recurring time windows
+type Schedule struct {
+	// name of the schedule, reported in status and events
+	Name string `json:"name" protobuf:"bytes,1,opt,name=name"`
+	// cron expression with 5 fields for the start of every window, e.g. "0 8 * * MON-FRI"
+	Cron string `json:"cron" protobuf:"bytes,2,opt,name=cron"`
+	// IANA time zone of the cron expression, e.g. "Europe/Bucharest". Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty" protobuf:"bytes,3,opt,name=timeZone"`
+	// length of every window, e.g. "10h". Must not be longer than 7 days.
+	Duration metav1.Duration `json:"duration" protobuf:"bytes,4,opt,name=duration"`
+	// minReplicas used while the schedule is active
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty" protobuf:"varint,5,opt,name=minReplicas"`
+	// maxReplicas used while the schedule is active
+	// +optional
+	MaxReplicas *int32 `json:"maxReplicas,omitempty" protobuf:"varint,6,opt,name=maxReplicas"`
+	// replicas pinned while the schedule is active, metrics are still evaluated and reported
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty" protobuf:"varint,7,opt,name=replicas"`
+}
+
+// AutoscalerMode specifies whether the target is scaled
+type AutoscalerMode string
+
+const (
+	// ActiveAutoscalerMode scales the target.
+	ActiveAutoscalerMode AutoscalerMode = "Active"
+	// DryRunAutoscalerMode runs the whole evaluation and reports the replicas the target would be scaled to.
+	DryRunAutoscalerMode AutoscalerMode = "DryRun"
+)
+
+// DryRunScale is a scale operation which was calculated but not applied
+type DryRunScale struct {
+	// replicas of the target at the time of the evaluation
+	FromReplicas int32 `json:"fromReplicas" protobuf:"varint,1,opt,name=fromReplicas"`
+	// replicas the target would have been scaled to
+	ToReplicas int32 `json:"toReplicas" protobuf:"varint,2,opt,name=toReplicas"`
+	// timestamp of the evaluation
+	Timestamp metav1.Time `json:"timestamp" protobuf:"bytes,3,opt,name=timestamp"`
+}
+
+// ScalingDecision explains how the replicas of a single evaluation were decided
+type ScalingDecision struct {
+	// timestamp of the evaluation
+	Timestamp metav1.Time `json:"timestamp" protobuf:"bytes,1,opt,name=timestamp"`
+	// replicas of the target at the time of the evaluation
+	CurrentReplicas int32 `json:"currentReplicas" protobuf:"varint,2,opt,name=currentReplicas"`
+	// evaluation of every metric of the spec
+	// +optional
+	Metrics []MetricDecision `json:"metrics,omitempty" protobuf:"bytes,3,rep,name=metrics"`
+	// name of the metric with the highest proposal
+	// +optional
+	WinningMetric string `json:"winningMetric,omitempty" protobuf:"bytes,4,opt,name=winningMetric"`
+	// highest proposal of all metrics after activation
+	DesiredReplicas int32 `json:"desiredReplicas" protobuf:"varint,5,opt,name=desiredReplicas"`
+	// desired replicas bounded by min and max replicas, recorded for the stabilization window
+	Recommendation int32 `json:"recommendation" protobuf:"varint,6,opt,name=recommendation"`
+	// replicas chosen from the recommendations in the stabilization window
+	StabilizedReplicas int32 `json:"stabilizedReplicas" protobuf:"varint,7,opt,name=stabilizedReplicas"`
+	// replicas the target is scaled to
+	NormalizedReplicas int32 `json:"normalizedReplicas" protobuf:"varint,8,opt,name=normalizedReplicas"`
+	// reason the normalized replicas differ from the desired replicas, e.g. MaxReplicas or ScaleUpLimit
+	// +optional
+	LimitReason string `json:"limitReason,omitempty" protobuf:"bytes,9,opt,name=limitReason"`
+	// scaling policy which limited the replicas, e.g. 'Pods 4 per 60s'
+	// +optional
+	Limit string `json:"limit,omitempty" protobuf:"bytes,10,opt,name=limit"`
+	// error when none of the metrics could be evaluated, the replicas are set by the fallback policy
+	// +optional
+	Error string `json:"error,omitempty" protobuf:"bytes,11,opt,name=error"`
+}
+
+// MetricDecision explains the replica proposal of a single metric
+type MetricDecision struct {
+	// type of the metric
+	Type MetricType `json:"type" protobuf:"bytes,1,name=type"`
+	// name of the metric, the resource name for Resource metrics and the query for Prometheus metrics
+	Name string `json:"name" protobuf:"bytes,2,name=name"`
+	// sum of the fetched values
+	// +optional
+	Value *resource.Quantity `json:"value,omitempty" protobuf:"bytes,3,opt,name=value"`
+	// number of fetched values, one per pod for Resource and Pod metrics
+	// +optional
+	ValueCount int32 `json:"valueCount,omitempty" protobuf:"varint,4,opt,name=valueCount"`
+	// fetched values, at most the first 10 of them, valueCount tells how many were fetched
+	// +optional
+	Values []resource.Quantity `json:"values,omitempty" protobuf:"bytes,12,rep,name=values"`
+	// target of the metric, e.g. 'averageValue 100'
+	// +optional
+	Target string `json:"target,omitempty" protobuf:"bytes,5,opt,name=target"`
+	// ratio of the value to the target with three decimals, empty when the algorithm doesn't use one
+	// +optional
+	UsageRatio string `json:"usageRatio,omitempty" protobuf:"bytes,6,opt,name=usageRatio"`
+	// true when the current replicas are kept because the usage ratio is within the tolerance
+	// +optional
+	WithinTolerance bool `json:"withinTolerance,omitempty" protobuf:"varint,7,opt,name=withinTolerance"`
+	// replicas proposed for this metric, including an applied forecast
+	// +optional
+	ProposedReplicas *int32 `json:"proposedReplicas,omitempty" protobuf:"varint,8,opt,name=proposedReplicas"`
+	// true when the proposal is raised by the forecast of the metric
+	// +optional
+	ForecastApplied bool `json:"forecastApplied,omitempty" protobuf:"varint,9,opt,name=forecastApplied"`
+	// error on fetching or evaluating the metric
+	// +optional
+	Error string `json:"error,omitempty" protobuf:"bytes,10,opt,name=error"`
+	// upper bound of the proposal set by the metric source, e.g. the partition count of Kafka topics
+	// +optional
+	ReplicaLimit *int32 `json:"replicaLimit,omitempty" protobuf:"varint,11,opt,name=replicaLimit"`
+}
+
+// FallbackPolicy specifies the replicas used when metrics can't be evaluated
+type FallbackPolicy string
+
+const (
+	// HoldFallbackPolicy keeps the current replicas.
+	HoldFallbackPolicy FallbackPolicy = "Hold"
+	// ReplicasFallbackPolicy scales to the fixed number of fallback replicas.
+	ReplicasFallbackPolicy FallbackPolicy = "Replicas"
+	// MaxFallbackPolicy scales to maxReplicas.
+	MaxFallbackPolicy FallbackPolicy = "Max"
+)
+
 // ScalingPolicyType is the type of the policy which could be used while making scaling decisions.
 type ScalingPolicyType string
 
@@ -302,6 +755,16 @@ type ScaleChangeEvent struct {
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Target",type=string,JSONPath=`.spec.target.name`
+// +kubebuilder:printcolumn:name="Min",type=integer,JSONPath=`.spec.minReplicas`
+// +kubebuilder:printcolumn:name="Max",type=integer,JSONPath=`.spec.maxReplicas`
+// +kubebuilder:printcolumn:name="Current",type=integer,JSONPath=`.status.currentReplicas`
+// +kubebuilder:printcolumn:name="Desired",type=integer,JSONPath=`.status.desiredReplicas`
+// +kubebuilder:printcolumn:name="Active",type=string,JSONPath=`.status.conditions[?(@.type=="ScalingActive")].status`
+// +kubebuilder:printcolumn:name="Mode",type=string,JSONPath=`.spec.mode`
+// +kubebuilder:printcolumn:name="Parked",type=boolean,JSONPath=`.status.parked`,priority=1
+// +kubebuilder:printcolumn:name="Schedule",type=string,JSONPath=`.status.activeSchedule`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // Kratos is the Schema for the kratos API
 type Kratos struct {
@@ -321,6 +784,18 @@ type KratosList struct {
 	Items           []Kratos `json:"items"`
 }
 
+// DefaultMinReplicas is the min replicas of a spec without minReplicas, scaling to zero is opt-in
+const DefaultMinReplicas int32 = 1
+
+// GetMinReplicas returns the min replicas of the spec, or the default when they're not set
+func (s *KratosSpec) GetMinReplicas() int32 {
+	if s.MinReplicas == nil {
+		return DefaultMinReplicas
+	}
+
+	return *s.MinReplicas
+}
+
 func init() {
 	SchemeBuilder.Register(&Kratos{}, &KratosList{})
 }
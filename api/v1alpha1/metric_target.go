@@ -15,15 +15,63 @@ package v1alpha1
 
 import (
 	"fmt"
+	"strings"
 )
 
 func (sm *ScaleMetric) GetMetricTarget() (*MetricTarget, error) {
 	switch sm.Type {
 	case PrometheusScaleMetricType:
-		return &sm.Prometheus.Target, nil
+		if sm.Prometheus != nil {
+			return &sm.Prometheus.Target, nil
+		}
 	case ResourceScaleMetricType:
-		return &sm.Resource.Target, nil
+		if sm.Resource != nil {
+			return &sm.Resource.Target, nil
+		}
+	case PodScaleMetricType:
+		if sm.Pods != nil {
+			return &sm.Pods.Target, nil
+		}
+	case ObjectScaleMetricType:
+		if sm.Object != nil {
+			return &sm.Object.Target, nil
+		}
+	case ExternalScaleMetricType:
+		if sm.External != nil {
+			return &sm.External.Target, nil
+		}
+	case KafkaScaleMetricType:
+		if sm.Kafka != nil {
+			return &sm.Kafka.Target, nil
+		}
+	case RabbitMQScaleMetricType:
+		if sm.RabbitMQ != nil {
+			return &sm.RabbitMQ.Target, nil
+		}
 	default:
 		return nil, fmt.Errorf("unknown metric type %s", sm.Type)
 	}
+	return nil, fmt.Errorf("metric source is not defined for metric type %s", sm.Type)
+}
+
+// GetMetricName returns a name identifying the metric in status and events
+func (sm *ScaleMetric) GetMetricName() string {
+	switch {
+	case sm.Type == ResourceScaleMetricType && sm.Resource != nil:
+		return string(sm.Resource.Name)
+	case sm.Type == PodScaleMetricType && sm.Pods != nil:
+		return sm.Pods.Metric.Name
+	case sm.Type == ObjectScaleMetricType && sm.Object != nil:
+		return sm.Object.Metric.Name
+	case sm.Type == ExternalScaleMetricType && sm.External != nil:
+		return sm.External.Metric.Name
+	case sm.Type == PrometheusScaleMetricType && sm.Prometheus != nil:
+		return sm.Prometheus.MetricQuery
+	case sm.Type == KafkaScaleMetricType && sm.Kafka != nil:
+		return fmt.Sprintf("%s/%s", sm.Kafka.ConsumerGroup, strings.Join(sm.Kafka.Topics, ","))
+	case sm.Type == RabbitMQScaleMetricType && sm.RabbitMQ != nil:
+		return sm.RabbitMQ.Queue
+	default:
+		return ""
+	}
 }
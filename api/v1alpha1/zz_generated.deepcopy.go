@@ -18,6 +18,7 @@ written permission of Adobe.
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
@@ -44,6 +45,22 @@ func (in *Algorithm) DeepCopy() *Algorithm {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DryRunScale) DeepCopyInto(out *DryRunScale) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DryRunScale.
+func (in *DryRunScale) DeepCopy() *DryRunScale {
+	if in == nil {
+		return nil
+	}
+	out := new(DryRunScale)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExternalMetricSource) DeepCopyInto(out *ExternalMetricSource) {
 	*out = *in
@@ -61,6 +78,76 @@ func (in *ExternalMetricSource) DeepCopy() *ExternalMetricSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Fallback) DeepCopyInto(out *Fallback) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Fallback.
+func (in *Fallback) DeepCopy() *Fallback {
+	if in == nil {
+		return nil
+	}
+	out := new(Fallback)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ForecastStatus) DeepCopyInto(out *ForecastStatus) {
+	*out = *in
+	if in.Value != nil {
+		in, out := &in.Value, &out.Value
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Time != nil {
+		in, out := &in.Time, &out.Time
+		*out = (*in).DeepCopy()
+	}
+	if in.ProposedReplicas != nil {
+		in, out := &in.ProposedReplicas, &out.ProposedReplicas
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ForecastStatus.
+func (in *ForecastStatus) DeepCopy() *ForecastStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ForecastStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaMetricSource) DeepCopyInto(out *KafkaMetricSource) {
+	*out = *in
+	if in.Brokers != nil {
+		in, out := &in.Brokers, &out.Brokers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Topics != nil {
+		in, out := &in.Topics, &out.Topics
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Target.DeepCopyInto(&out.Target)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaMetricSource.
+func (in *KafkaMetricSource) DeepCopy() *KafkaMetricSource {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaMetricSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Kratos) DeepCopyInto(out *Kratos) {
 	*out = *in
@@ -125,6 +212,11 @@ func (in *KratosSpec) DeepCopyInto(out *KratosSpec) {
 	*out = *in
 	out.Target = in.Target
 	in.Algorithm.DeepCopyInto(&out.Algorithm)
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
 	if in.Metrics != nil {
 		in, out := &in.Metrics, &out.Metrics
 		*out = make([]ScaleMetric, len(*in))
@@ -137,6 +229,18 @@ func (in *KratosSpec) DeepCopyInto(out *KratosSpec) {
 		*out = new(ScaleBehavior)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Fallback != nil {
+		in, out := &in.Fallback, &out.Fallback
+		*out = new(Fallback)
+		**out = **in
+	}
+	if in.Schedules != nil {
+		in, out := &in.Schedules, &out.Schedules
+		*out = make([]Schedule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KratosSpec.
@@ -177,6 +281,40 @@ func (in *KratosStatus) DeepCopyInto(out *KratosStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CurrentMetrics != nil {
+		in, out := &in.CurrentMetrics, &out.CurrentMetrics
+		*out = make([]MetricStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.IdleSince != nil {
+		in, out := &in.IdleSince, &out.IdleSince
+		*out = (*in).DeepCopy()
+	}
+	if in.DryRunScale != nil {
+		in, out := &in.DryRunScale, &out.DryRunScale
+		*out = new(DryRunScale)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Decisions != nil {
+		in, out := &in.Decisions, &out.Decisions
+		*out = make([]ScalingDecision, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastScaleTime != nil {
+		in, out := &in.LastScaleTime, &out.LastScaleTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KratosStatus.
@@ -189,6 +327,43 @@ func (in *KratosStatus) DeepCopy() *KratosStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricDecision) DeepCopyInto(out *MetricDecision) {
+	*out = *in
+	if in.Value != nil {
+		in, out := &in.Value, &out.Value
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]resource.Quantity, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ProposedReplicas != nil {
+		in, out := &in.ProposedReplicas, &out.ProposedReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ReplicaLimit != nil {
+		in, out := &in.ReplicaLimit, &out.ReplicaLimit
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricDecision.
+func (in *MetricDecision) DeepCopy() *MetricDecision {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricDecision)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MetricIdentifier) DeepCopyInto(out *MetricIdentifier) {
 	*out = *in
@@ -209,6 +384,36 @@ func (in *MetricIdentifier) DeepCopy() *MetricIdentifier {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricStatus) DeepCopyInto(out *MetricStatus) {
+	*out = *in
+	if in.Value != nil {
+		in, out := &in.Value, &out.Value
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.ProposedReplicas != nil {
+		in, out := &in.ProposedReplicas, &out.ProposedReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Forecast != nil {
+		in, out := &in.Forecast, &out.Forecast
+		*out = new(ForecastStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricStatus.
+func (in *MetricStatus) DeepCopy() *MetricStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MetricTarget) DeepCopyInto(out *MetricTarget) {
 	*out = *in
@@ -227,6 +432,11 @@ func (in *MetricTarget) DeepCopyInto(out *MetricTarget) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.ActivationThreshold != nil {
+		in, out := &in.ActivationThreshold, &out.ActivationThreshold
+		x := (*in).DeepCopy()
+		*out = &x
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricTarget.
@@ -274,10 +484,38 @@ func (in *PodsMetricSource) DeepCopy() *PodsMetricSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Prediction) DeepCopyInto(out *Prediction) {
+	*out = *in
+	out.Season = in.Season
+	out.Step = in.Step
+	out.LeadTime = in.LeadTime
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Prediction.
+func (in *Prediction) DeepCopy() *Prediction {
+	if in == nil {
+		return nil
+	}
+	out := new(Prediction)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PrometheusMetricSource) DeepCopyInto(out *PrometheusMetricSource) {
 	*out = *in
 	in.Target.DeepCopyInto(&out.Target)
+	if in.Prediction != nil {
+		in, out := &in.Prediction, &out.Prediction
+		*out = new(Prediction)
+		**out = **in
+	}
+	if in.Range != nil {
+		in, out := &in.Range, &out.Range
+		*out = new(PrometheusRange)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusMetricSource.
@@ -290,6 +528,40 @@ func (in *PrometheusMetricSource) DeepCopy() *PrometheusMetricSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusRange) DeepCopyInto(out *PrometheusRange) {
+	*out = *in
+	out.Window = in.Window
+	out.Step = in.Step
+	out.HalfLife = in.HalfLife
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusRange.
+func (in *PrometheusRange) DeepCopy() *PrometheusRange {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusRange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitMQMetricSource) DeepCopyInto(out *RabbitMQMetricSource) {
+	*out = *in
+	in.Target.DeepCopyInto(&out.Target)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitMQMetricSource.
+func (in *RabbitMQMetricSource) DeepCopy() *RabbitMQMetricSource {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitMQMetricSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Recommendation) DeepCopyInto(out *Recommendation) {
 	*out = *in
@@ -391,6 +663,16 @@ func (in *ScaleMetric) DeepCopyInto(out *ScaleMetric) {
 		*out = new(PrometheusMetricSource)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Kafka != nil {
+		in, out := &in.Kafka, &out.Kafka
+		*out = new(KafkaMetricSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RabbitMQ != nil {
+		in, out := &in.RabbitMQ, &out.RabbitMQ
+		*out = new(RabbitMQMetricSource)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaleMetric.
@@ -438,6 +720,29 @@ func (in *ScaleTargetReference) DeepCopy() *ScaleTargetReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingDecision) DeepCopyInto(out *ScalingDecision) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]MetricDecision, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingDecision.
+func (in *ScalingDecision) DeepCopy() *ScalingDecision {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingDecision)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScalingPolicy) DeepCopyInto(out *ScalingPolicy) {
 	*out = *in
@@ -452,3 +757,34 @@ func (in *ScalingPolicy) DeepCopy() *ScalingPolicy {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Schedule) DeepCopyInto(out *Schedule) {
+	*out = *in
+	out.Duration = in.Duration
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxReplicas != nil {
+		in, out := &in.MaxReplicas, &out.MaxReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Schedule.
+func (in *Schedule) DeepCopy() *Schedule {
+	if in == nil {
+		return nil
+	}
+	out := new(Schedule)
+	in.DeepCopyInto(out)
+	return out
+}
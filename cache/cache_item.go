@@ -16,30 +16,33 @@ package cache
 import (
 	"sync"
 	"time"
+
+	"k8s.io/utils/clock"
 )
 
 type cacheItem struct {
 	mutex   sync.RWMutex
 	value   Closeable
 	expires *time.Time
+	clock   clock.Clock
 }
 
-func newCacheItem(value Closeable, ttl time.Duration) *cacheItem {
-	cacheItem := &cacheItem{value: value}
+func newCacheItem(value Closeable, ttl time.Duration, clock clock.Clock) *cacheItem {
+	cacheItem := &cacheItem{value: value, clock: clock}
 	cacheItem.updateTTL(ttl)
 	return cacheItem
 }
 
 func (item *cacheItem) updateTTL(ttl time.Duration) {
 	item.mutex.Lock()
-	expiration := time.Now().Add(ttl)
+	expiration := item.clock.Now().Add(ttl)
 	item.expires = &expiration
 	item.mutex.Unlock()
 }
 
 func (item *cacheItem) expired() bool {
 	item.mutex.RLock()
-	result := item.expires != nil && item.expires.Before(time.Now())
+	result := item.expires != nil && item.expires.Before(item.clock.Now())
 	item.mutex.RUnlock()
 	return result
 }
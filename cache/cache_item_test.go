@@ -18,6 +18,7 @@ import (
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	testingclock "k8s.io/utils/clock/testing"
 )
 
 type testCloseable struct {
@@ -28,24 +29,32 @@ func (t *testCloseable) Close() error {
 }
 
 var _ = Describe("CacheItem", func() {
+	var fakeClock *testingclock.FakeClock
+
+	BeforeEach(func() {
+		fakeClock = testingclock.NewFakeClock(time.Date(2021, 7, 5, 10, 0, 0, 0, time.UTC))
+	})
+
 	It("Cache item expired", func() {
-		cacheItem := newCacheItem(&testCloseable{}, time.Millisecond*100)
-		<-time.After(time.Millisecond * 400)
+		cacheItem := newCacheItem(&testCloseable{}, time.Millisecond*100, fakeClock)
+		Expect(cacheItem.expired()).To(BeFalse(), "cache item should not be expired before TTL")
+
+		fakeClock.Step(time.Millisecond * 101)
 		Expect(cacheItem.expired()).To(BeTrue(), "cache item should be expired after TTL")
 	})
 
 	It("Empty TTL considered NOT expired", func() {
-		cacheItem := cacheItem{value: &testCloseable{}}
+		cacheItem := cacheItem{value: &testCloseable{}, clock: fakeClock}
 		Expect(cacheItem.expired()).To(BeFalse(), "cache item with Nil TTL is not expired")
 	})
 
 	It("Cache item NOT expired after update", func() {
-		cacheItem := newCacheItem(&testCloseable{}, time.Millisecond*100)
-		<-time.After(time.Millisecond * 400)
+		cacheItem := newCacheItem(&testCloseable{}, time.Millisecond*100, fakeClock)
+		fakeClock.Step(time.Millisecond * 400)
 		Expect(cacheItem.expired()).To(BeTrue(), "cache item should be expired after TTL")
 
 		cacheItem.updateTTL(time.Hour)
-		<-time.After(time.Second)
+		fakeClock.Step(time.Second)
 		Expect(cacheItem.expired()).To(BeFalse(), "cache item should NOT be expired after TTL update")
 	})
 })
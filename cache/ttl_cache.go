@@ -18,6 +18,7 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
+	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -30,13 +31,15 @@ type TTLCache struct {
 	ttl   time.Duration
 	items map[string]*cacheItem
 	log   logr.Logger
+	clock clock.Clock
 }
 
-func NewTTLCache(cacheName string, ttl time.Duration) *TTLCache {
+func NewTTLCache(cacheName string, ttl time.Duration, clock clock.Clock) *TTLCache {
 	cache := &TTLCache{
 		ttl:   ttl,
 		items: make(map[string]*cacheItem),
 		log:   log.Log.WithName(cacheName),
+		clock: clock,
 	}
 	cache.startEvictionThread()
 	return cache
@@ -44,7 +47,7 @@ func NewTTLCache(cacheName string, ttl time.Duration) *TTLCache {
 
 func (cache *TTLCache) Put(key string, value Closeable) {
 	cache.mutex.Lock()
-	cache.items[key] = newCacheItem(value, cache.ttl)
+	cache.items[key] = newCacheItem(value, cache.ttl, cache.clock)
 	cache.mutex.Unlock()
 }
 
@@ -76,7 +79,7 @@ func (cache *TTLCache) evictExpiredItems() {
 
 func (cache *TTLCache) startEvictionThread() {
 	cache.log.Info("starting eviction thread")
-	ticker := time.Tick(cache.ttl)
+	ticker := cache.clock.Tick(cache.ttl)
 	go (func() {
 		for {
 			select {
@@ -18,6 +18,7 @@ import (
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	testingclock "k8s.io/utils/clock/testing"
 )
 
 const (
@@ -26,7 +27,8 @@ const (
 
 var _ = Describe("TTLCache", func() {
 	It("Cache item expired", func() {
-		cache := NewTTLCache("test-cache", time.Millisecond*500)
+		fakeClock := testingclock.NewFakeClock(time.Date(2021, 7, 5, 10, 0, 0, 0, time.UTC))
+		cache := NewTTLCache("test-cache", time.Millisecond*500, fakeClock)
 
 		cache.Put(key, &testCloseable{})
 
@@ -34,9 +36,25 @@ var _ = Describe("TTLCache", func() {
 		Expect(cachedValue).NotTo(BeNil(), "cache item present")
 		Expect(found).To(BeTrue())
 
-		<-time.After(time.Millisecond * 600)
+		fakeClock.Step(time.Millisecond * 600)
 		cachedValue, found = cache.Get(key)
 		Expect(cachedValue).To(BeNil(), "cache item not found after expiration")
 		Expect(found).To(BeFalse())
 	})
+
+	It("Expired items are evicted", func() {
+		fakeClock := testingclock.NewFakeClock(time.Date(2021, 7, 5, 10, 0, 0, 0, time.UTC))
+		cache := NewTTLCache("test-cache", time.Minute, fakeClock)
+
+		cache.Put(key, &testCloseable{})
+		Eventually(fakeClock.HasWaiters).Should(BeTrue(), "eviction thread should wait for the next tick")
+
+		fakeClock.Step(2 * time.Minute)
+
+		Eventually(func() int {
+			cache.mutex.RLock()
+			defer cache.mutex.RUnlock()
+			return len(cache.items)
+		}).Should(Equal(0), "expired item should be evicted on tick")
+	})
 })
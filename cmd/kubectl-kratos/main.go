@@ -0,0 +1,30 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/adobe/kratos/plugin"
+	_ "k8s.io/client-go/plugin/pkg/client/auth/azure"
+)
+
+// kubectl runs binaries named kubectl-<name> found on the PATH as 'kubectl <name>'
+func main() {
+	if err := plugin.Run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
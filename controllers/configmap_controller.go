@@ -0,0 +1,75 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ConfigMapReconciler reconciles ConfigMaps holding a 'kratosSpec' key
+type ConfigMapReconciler struct {
+	client.Client
+	log           logr.Logger
+	scalingWorker *Worker
+}
+
+func newConfigMapReconciler(client client.Client, scalingWorker *Worker) *ConfigMapReconciler {
+	return &ConfigMapReconciler{
+		Client:        client,
+		log:           ctrl.Log.WithName("configmap-reconciler"),
+		scalingWorker: scalingWorker,
+	}
+}
+
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+func (r *ConfigMapReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	name := req.NamespacedName
+	log := r.log.WithValues("name", name)
+
+	configMap := &corev1.ConfigMap{}
+
+	err := r.Get(ctx, req.NamespacedName, configMap)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("ConfigMap not found. Ignoring since object must be deleted.", "item", req.NamespacedName)
+			r.scalingWorker.removeItem(configMapKind, req.NamespacedName)
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get ConfigMap")
+		return ctrl.Result{}, err
+	}
+
+	if _, found := configMap.Data["kratosSpec"]; found {
+		log.Info("ConfigMap has key 'kratosSpec', adding to queue.")
+		r.scalingWorker.addItem(configMapKind, req.NamespacedName)
+	} else {
+		log.Info("ConfigMap missing 'kratosSpec', skipping.")
+		r.scalingWorker.removeItem(configMapKind, req.NamespacedName)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *ConfigMapReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.ConfigMap{}).
+		Complete(r)
+}
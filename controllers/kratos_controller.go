@@ -22,9 +22,9 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-
-	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
 
 // KratosReconciler reconciles a Kratos object
@@ -56,37 +56,42 @@ func NewKratosReconciler(params *common.KratosParameters) (*KratosReconciler, er
 
 // +kubebuilder:rbac:groups=scaling.core.adobe.com,resources=kratos,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=scaling.core.adobe.com,resources=kratos/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=custom.metrics.k8s.io,resources=*,verbs=get;list
+// +kubebuilder:rbac:groups=external.metrics.k8s.io,resources=*,verbs=get;list
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get
 func (r *KratosReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	name := req.NamespacedName
 	log := r.log.WithValues("name", name)
 
-	configMap := &corev1.ConfigMap{}
+	kratos := &scalingv1alpha1.Kratos{}
 
-	err := r.Get(ctx, req.NamespacedName, configMap)
+	err := r.Get(ctx, req.NamespacedName, kratos)
 	if err != nil {
 		if errors.IsNotFound(err) {
-			log.Info("ConfigMap not found. Ignoring since object must be deleted.", "item", req.NamespacedName)
-			r.scalingWorker.removeItem(req.NamespacedName)
+			log.Info("Kratos not found. Ignoring since object must be deleted.", "item", req.NamespacedName)
+			r.scalingWorker.removeItem(kratosKind, req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
-		log.Error(err, "Failed to get ConfigMap")
+		log.Error(err, "Failed to get Kratos")
 		return ctrl.Result{}, err
 	}
 
-	if _, found := configMap.Data["kratosSpec"]; found {
-		log.Info("ConfigMap has key 'kratosSpec', adding to queue.")
-		r.scalingWorker.addItem(req.NamespacedName)
-	} else {
-		log.Info("ConfigMap missing 'kratosSpec', skipping.")
-		r.scalingWorker.removeItem(req.NamespacedName)
-	}
+	log.Info("Kratos found, adding to queue.")
+	r.scalingWorker.addItem(kratosKind, req.NamespacedName)
 
 	return ctrl.Result{}, nil
 }
 
+// SetupWithManager registers the Kratos controller and the ConfigMap controller kept for compatibility
 func (r *KratosReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&scalingv1alpha1.Kratos{}).
-		For(&corev1.ConfigMap{}).
+	err := ctrl.NewControllerManagedBy(mgr).
+		For(&scalingv1alpha1.Kratos{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Complete(r)
+
+	if err != nil {
+		return err
+	}
+
+	return newConfigMapReconciler(r.Client, r.scalingWorker).SetupWithManager(mgr)
 }
@@ -19,6 +19,8 @@ import (
 	"time"
 
 	"github.com/adobe/kratos/api/common"
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/adobe/kratos/monitoring"
 	"github.com/adobe/kratos/scale"
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
@@ -36,6 +38,19 @@ const (
 	NOT_DELETED bool = false
 )
 
+type itemKind string
+
+const (
+	kratosKind    itemKind = "Kratos"
+	configMapKind itemKind = "ConfigMap"
+)
+
+// workItem identifies an autoscaler definition in the queue, either a Kratos resource or a ConfigMap
+type workItem struct {
+	kind itemKind
+	name types.NamespacedName
+}
+
 type Worker struct {
 	client      client.Client
 	log         logr.Logger
@@ -60,13 +75,15 @@ func NewWorker(params *common.KratosParameters) (*Worker, error) {
 	return worker, err
 }
 
-func (s *Worker) addItem(name types.NamespacedName) {
-	s.queue.AddRateLimited(name)
+func (s *Worker) addItem(kind itemKind, name types.NamespacedName) {
+	s.queue.AddRateLimited(workItem{kind: kind, name: name})
 }
 
-func (s *Worker) removeItem(name types.NamespacedName) {
-	s.queue.Forget(name)
-	s.queue.Done(name)
+func (s *Worker) removeItem(kind itemKind, name types.NamespacedName) {
+	item := workItem{kind: kind, name: name}
+	s.queue.Forget(item)
+	s.queue.Done(item)
+	s.scaleFacade.ReleaseTarget(string(kind), name)
 }
 
 func (s *Worker) run(threadiness int, stopCh chan struct{}) {
@@ -100,7 +117,7 @@ func (s *Worker) processNextWorkItem() bool {
 	}
 	defer s.queue.Done(key)
 
-	deleted, err := s.processItem(key.(types.NamespacedName))
+	deleted, err := s.processItem(key.(workItem))
 	if err != nil {
 		utilruntime.HandleError(fmt.Errorf("%v failed with : %v", key, err))
 	}
@@ -112,20 +129,41 @@ func (s *Worker) processNextWorkItem() bool {
 	return true
 }
 
-func (s *Worker) processItem(name types.NamespacedName) (deleted bool, err error) {
-	s.log.Info("scaling ", "item", name)
-	configMap := &corev1.ConfigMap{}
+func (s *Worker) processItem(item workItem) (deleted bool, err error) {
+	s.log.Info("scaling ", "item", item.name, "kind", item.kind)
+
+	switch item.kind {
+	case kratosKind:
+		kratos := &v1alpha1.Kratos{}
+		if deleted, err := s.retrieveItem(item, kratos); deleted || err != nil {
+			return deleted, err
+		}
+		s.scaleFacade.ScaleKratos(kratos)
+	case configMapKind:
+		configMap := &corev1.ConfigMap{}
+		if deleted, err := s.retrieveItem(item, configMap); deleted || err != nil {
+			return deleted, err
+		}
+		s.scaleFacade.ScaleConfigMap(configMap)
+	default:
+		return DELETED, fmt.Errorf("unknown item kind %s", item.kind)
+	}
+
+	return NOT_DELETED, nil
+}
 
-	errRetrieve := s.client.Get(context.TODO(), name, configMap)
+func (s *Worker) retrieveItem(item workItem, object client.Object) (deleted bool, err error) {
+	errRetrieve := s.client.Get(context.TODO(), item.name, object)
 	if errRetrieve != nil {
 		if errors.IsNotFound(errRetrieve) {
-			s.log.Info("ConfigMap not found. Ignoring since object must be deleted.", "item", name)
+			s.log.Info("Item not found. Ignoring since object must be deleted.", "item", item.name, "kind", item.kind)
+			monitoring.Forget(string(item.kind), item.name.Namespace, item.name.Name)
+			s.scaleFacade.ReleaseTarget(string(item.kind), item.name)
 
 			return DELETED, nil
 		}
-		return NOT_DELETED, err
+		return NOT_DELETED, errRetrieve
 	}
 
-	s.scaleFacade.Scale(configMap)
 	return NOT_DELETED, nil
 }
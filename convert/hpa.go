@@ -0,0 +1,430 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package convert
+
+import (
+	"fmt"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+)
+
+// HorizontalPodAutoscaler defaults, applied by the API server when the behavior or one of its directions is missing
+const (
+	hpaDefaultMinReplicas                  = 1
+	hpaDefaultScaleDownStabilizationWindow = 300
+	hpaDefaultPolicyPeriodSeconds          = 15
+	hpaDefaultScaleUpPodsValue             = 4
+	hpaDefaultPercentValue                 = 100
+)
+
+// HPAToSpec converts the spec of an autoscaling/v2 HorizontalPodAutoscaler, autoscaling/v2beta2 has the same schema.
+// The behavior is written out with the HorizontalPodAutoscaler defaults since Kratos defaults missing directions differently.
+// Returns the converted spec and a warning for every field which couldn't be represented exactly.
+func HPAToSpec(hpa *autoscalingv2beta2.HorizontalPodAutoscalerSpec) (*v1alpha1.KratosSpec, []string) {
+	warnings := make([]string, 0)
+
+	spec := &v1alpha1.KratosSpec{
+		Target: v1alpha1.ScaleTargetReference{
+			Kind:       hpa.ScaleTargetRef.Kind,
+			Name:       hpa.ScaleTargetRef.Name,
+			APIVersion: hpa.ScaleTargetRef.APIVersion,
+		},
+		Algorithm:   v1alpha1.Algorithm{Type: v1alpha1.HpaAlgorithmType},
+		MaxReplicas: hpa.MaxReplicas,
+		Metrics:     make([]v1alpha1.ScaleMetric, 0, len(hpa.Metrics)),
+	}
+
+	if hpa.MinReplicas != nil {
+		minReplicas := *hpa.MinReplicas
+		spec.MinReplicas = &minReplicas
+	}
+
+	for i := range hpa.Metrics {
+		metric, err := hpaMetricToScaleMetric(&hpa.Metrics[i])
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("metrics[%d]: %v, the metric is dropped", i, err))
+			continue
+		}
+		spec.Metrics = append(spec.Metrics, *metric)
+	}
+
+	var scaleUp, scaleDown *autoscalingv2beta2.HPAScalingRules
+	if hpa.Behavior != nil {
+		scaleUp = hpa.Behavior.ScaleUp
+		scaleDown = hpa.Behavior.ScaleDown
+	}
+
+	spec.Behavior = &v1alpha1.ScaleBehavior{
+		ScaleUp:   hpaRulesToScaleRules(scaleUp, defaultHPAScaleUpRules(), "behavior.scaleUp", &warnings),
+		ScaleDown: hpaRulesToScaleRules(scaleDown, defaultHPAScaleDownRules(), "behavior.scaleDown", &warnings),
+	}
+
+	// only used by Kratos without behavior, it's set to the scale down window so that it reads the same
+	spec.StabilizationWindowSeconds = spec.Behavior.ScaleDown.StabilizationWindowSeconds
+
+	return spec, warnings
+}
+
+func hpaMetricToScaleMetric(metric *autoscalingv2beta2.MetricSpec) (*v1alpha1.ScaleMetric, error) {
+	switch metric.Type {
+	case autoscalingv2beta2.ResourceMetricSourceType:
+		if metric.Resource == nil {
+			return nil, fmt.Errorf("resource is required for type %s", metric.Type)
+		}
+		return &v1alpha1.ScaleMetric{
+			Type: v1alpha1.ResourceScaleMetricType,
+			Resource: &v1alpha1.ResourceMetricSource{
+				Name:   metric.Resource.Name,
+				Target: hpaTargetToMetricTarget(metric.Resource.Target),
+			},
+		}, nil
+	case autoscalingv2beta2.ContainerResourceMetricSourceType:
+		if metric.ContainerResource == nil {
+			return nil, fmt.Errorf("containerResource is required for type %s", metric.Type)
+		}
+		return &v1alpha1.ScaleMetric{
+			Type: v1alpha1.ResourceScaleMetricType,
+			Resource: &v1alpha1.ResourceMetricSource{
+				Name:      metric.ContainerResource.Name,
+				Container: metric.ContainerResource.Container,
+				Target:    hpaTargetToMetricTarget(metric.ContainerResource.Target),
+			},
+		}, nil
+	case autoscalingv2beta2.PodsMetricSourceType:
+		if metric.Pods == nil {
+			return nil, fmt.Errorf("pods is required for type %s", metric.Type)
+		}
+		return &v1alpha1.ScaleMetric{
+			Type: v1alpha1.PodScaleMetricType,
+			Pods: &v1alpha1.PodsMetricSource{
+				Metric: hpaIdentifierToMetricIdentifier(metric.Pods.Metric),
+				Target: hpaTargetToMetricTarget(metric.Pods.Target),
+			},
+		}, nil
+	case autoscalingv2beta2.ObjectMetricSourceType:
+		if metric.Object == nil {
+			return nil, fmt.Errorf("object is required for type %s", metric.Type)
+		}
+		return &v1alpha1.ScaleMetric{
+			Type: v1alpha1.ObjectScaleMetricType,
+			Object: &v1alpha1.ObjectMetricSource{
+				DescribedObject: v1alpha1.ScaleTargetReference{
+					Kind:       metric.Object.DescribedObject.Kind,
+					Name:       metric.Object.DescribedObject.Name,
+					APIVersion: metric.Object.DescribedObject.APIVersion,
+				},
+				Metric: hpaIdentifierToMetricIdentifier(metric.Object.Metric),
+				Target: hpaTargetToMetricTarget(metric.Object.Target),
+			},
+		}, nil
+	case autoscalingv2beta2.ExternalMetricSourceType:
+		if metric.External == nil {
+			return nil, fmt.Errorf("external is required for type %s", metric.Type)
+		}
+		return &v1alpha1.ScaleMetric{
+			Type: v1alpha1.ExternalScaleMetricType,
+			External: &v1alpha1.ExternalMetricSource{
+				Metric: hpaIdentifierToMetricIdentifier(metric.External.Metric),
+				Target: hpaTargetToMetricTarget(metric.External.Target),
+			},
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported metric type %s", metric.Type)
+}
+
+func hpaTargetToMetricTarget(target autoscalingv2beta2.MetricTarget) v1alpha1.MetricTarget {
+	return v1alpha1.MetricTarget{
+		Type:               v1alpha1.MetricTargetType(target.Type),
+		Value:              target.Value,
+		AverageValue:       target.AverageValue,
+		AverageUtilization: target.AverageUtilization,
+	}
+}
+
+func hpaIdentifierToMetricIdentifier(identifier autoscalingv2beta2.MetricIdentifier) v1alpha1.MetricIdentifier {
+	return v1alpha1.MetricIdentifier{
+		Name:     identifier.Name,
+		Selector: identifier.Selector,
+	}
+}
+
+// hpaRulesToScaleRules converts the rules of a direction, the missing fields are taken from the HorizontalPodAutoscaler defaults
+func hpaRulesToScaleRules(rules *autoscalingv2beta2.HPAScalingRules, defaults *autoscalingv2beta2.HPAScalingRules, path string, warnings *[]string) *v1alpha1.ScaleRules {
+	if rules == nil {
+		rules = defaults
+	}
+
+	result := &v1alpha1.ScaleRules{
+		StabilizationWindowSeconds: *defaults.StabilizationWindowSeconds,
+		SelectPolicy:               v1alpha1.ScalingPolicySelect(*defaults.SelectPolicy),
+	}
+
+	if rules.StabilizationWindowSeconds != nil {
+		result.StabilizationWindowSeconds = *rules.StabilizationWindowSeconds
+	}
+
+	if rules.SelectPolicy != nil {
+		result.SelectPolicy = v1alpha1.ScalingPolicySelect(*rules.SelectPolicy)
+	}
+
+	policies := rules.Policies
+	if len(policies) == 0 {
+		policies = defaults.Policies
+	}
+
+	for _, policy := range policies {
+		result.Policies = append(result.Policies, v1alpha1.ScalingPolicy{
+			Type:          v1alpha1.ScalingPolicyType(policy.Type),
+			Value:         policy.Value,
+			PeriodSeconds: policy.PeriodSeconds,
+		})
+	}
+
+	// Kratos replaces a window of 0 with the default window of the operator, 1 second is the closest it can get
+	if result.StabilizationWindowSeconds == 0 && result.SelectPolicy != v1alpha1.DisabledPolicySelect {
+		result.StabilizationWindowSeconds = 1
+		*warnings = append(*warnings, fmt.Sprintf("%s.stabilizationWindowSeconds: 0 is replaced by the operator default in Kratos, 1 is used", path))
+	}
+
+	return result
+}
+
+func defaultHPAScaleUpRules() *autoscalingv2beta2.HPAScalingRules {
+	stabilizationWindowSeconds := int32(0)
+	selectPolicy := autoscalingv2beta2.MaxPolicySelect
+
+	return &autoscalingv2beta2.HPAScalingRules{
+		StabilizationWindowSeconds: &stabilizationWindowSeconds,
+		SelectPolicy:               &selectPolicy,
+		Policies: []autoscalingv2beta2.HPAScalingPolicy{
+			{Type: autoscalingv2beta2.PodsScalingPolicy, Value: hpaDefaultScaleUpPodsValue, PeriodSeconds: hpaDefaultPolicyPeriodSeconds},
+			{Type: autoscalingv2beta2.PercentScalingPolicy, Value: hpaDefaultPercentValue, PeriodSeconds: hpaDefaultPolicyPeriodSeconds},
+		},
+	}
+}
+
+func defaultHPAScaleDownRules() *autoscalingv2beta2.HPAScalingRules {
+	stabilizationWindowSeconds := int32(hpaDefaultScaleDownStabilizationWindow)
+	selectPolicy := autoscalingv2beta2.MaxPolicySelect
+
+	return &autoscalingv2beta2.HPAScalingRules{
+		StabilizationWindowSeconds: &stabilizationWindowSeconds,
+		SelectPolicy:               &selectPolicy,
+		Policies: []autoscalingv2beta2.HPAScalingPolicy{
+			{Type: autoscalingv2beta2.PercentScalingPolicy, Value: hpaDefaultPercentValue, PeriodSeconds: hpaDefaultPolicyPeriodSeconds},
+		},
+	}
+}
+
+// SpecToHPA converts a Kratos spec to the spec of an autoscaling/v2 HorizontalPodAutoscaler, the spec must have defaults applied.
+// Fields without an equivalent, like Prometheus, Kafka or RabbitMQ metrics, schedules or the DryRun mode, are dropped with a warning.
+func SpecToHPA(spec *v1alpha1.KratosSpec) (*autoscalingv2beta2.HorizontalPodAutoscalerSpec, []string) {
+	warnings := make([]string, 0)
+
+	minReplicas := spec.GetMinReplicas()
+	if minReplicas < hpaDefaultMinReplicas {
+		warnings = append(warnings, fmt.Sprintf("minReplicas: %d isn't supported by HorizontalPodAutoscalers without the HPAScaleToZero feature gate, %d is used", minReplicas, hpaDefaultMinReplicas))
+		minReplicas = hpaDefaultMinReplicas
+	}
+
+	hpa := &autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+		ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{
+			Kind:       spec.Target.Kind,
+			Name:       spec.Target.Name,
+			APIVersion: spec.Target.APIVersion,
+		},
+		MinReplicas: &minReplicas,
+		MaxReplicas: spec.MaxReplicas,
+		Metrics:     make([]autoscalingv2beta2.MetricSpec, 0, len(spec.Metrics)),
+	}
+
+	if spec.Algorithm.Type != v1alpha1.HpaAlgorithmType || len(spec.Algorithm.Options) > 0 {
+		warnings = append(warnings, fmt.Sprintf("algorithm: HorizontalPodAutoscalers always calculate replicas like the %s algorithm without options, %s is dropped", v1alpha1.HpaAlgorithmType, spec.Algorithm.Type))
+	}
+
+	if spec.Mode == v1alpha1.DryRunAutoscalerMode {
+		warnings = append(warnings, fmt.Sprintf("mode: HorizontalPodAutoscalers always scale their target, %s is dropped", spec.Mode))
+	}
+
+	if spec.Fallback != nil && spec.Fallback.Policy != v1alpha1.HoldFallbackPolicy {
+		warnings = append(warnings, fmt.Sprintf("fallback: HorizontalPodAutoscalers always hold the current replicas when metrics can't be evaluated, %s is dropped", spec.Fallback.Policy))
+	}
+
+	if len(spec.Schedules) > 0 {
+		warnings = append(warnings, fmt.Sprintf("schedules: HorizontalPodAutoscalers have no schedules, %d schedules are dropped", len(spec.Schedules)))
+	}
+
+	for i := range spec.Metrics {
+		metric, err := scaleMetricToHPAMetric(&spec.Metrics[i])
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("metrics[%d]: %v, the metric is dropped", i, err))
+			continue
+		}
+
+		if spec.Metrics[i].Type != v1alpha1.PrometheusScaleMetricType && metricTarget(&spec.Metrics[i]).ActivationThreshold != nil {
+			warnings = append(warnings, fmt.Sprintf("metrics[%d]: HorizontalPodAutoscalers don't scale to zero, the activation threshold is dropped", i))
+		}
+
+		hpa.Metrics = append(hpa.Metrics, *metric)
+	}
+
+	if spec.Behavior == nil {
+		// the standard normalizer limits scale up like the HorizontalPodAutoscaler defaults and stabilizes scale down only
+		scaleDown := defaultHPAScaleDownRules()
+		scaleDown.StabilizationWindowSeconds = &spec.StabilizationWindowSeconds
+
+		hpa.Behavior = &autoscalingv2beta2.HorizontalPodAutoscalerBehavior{
+			ScaleUp:   defaultHPAScaleUpRules(),
+			ScaleDown: scaleDown,
+		}
+	} else {
+		hpa.Behavior = &autoscalingv2beta2.HorizontalPodAutoscalerBehavior{
+			ScaleUp:   scaleRulesToHPARules(spec.Behavior.ScaleUp),
+			ScaleDown: scaleRulesToHPARules(spec.Behavior.ScaleDown),
+		}
+	}
+
+	return hpa, warnings
+}
+
+func scaleMetricToHPAMetric(metric *v1alpha1.ScaleMetric) (*autoscalingv2beta2.MetricSpec, error) {
+	switch metric.Type {
+	case v1alpha1.ResourceScaleMetricType:
+		if metric.Resource == nil {
+			return nil, fmt.Errorf("resource is required for type %s", metric.Type)
+		}
+		if metric.Resource.Container != "" {
+			return &autoscalingv2beta2.MetricSpec{
+				Type: autoscalingv2beta2.ContainerResourceMetricSourceType,
+				ContainerResource: &autoscalingv2beta2.ContainerResourceMetricSource{
+					Name:      metric.Resource.Name,
+					Container: metric.Resource.Container,
+					Target:    metricTargetToHPATarget(metric.Resource.Target),
+				},
+			}, nil
+		}
+		return &autoscalingv2beta2.MetricSpec{
+			Type: autoscalingv2beta2.ResourceMetricSourceType,
+			Resource: &autoscalingv2beta2.ResourceMetricSource{
+				Name:   metric.Resource.Name,
+				Target: metricTargetToHPATarget(metric.Resource.Target),
+			},
+		}, nil
+	case v1alpha1.PodScaleMetricType:
+		if metric.Pods == nil {
+			return nil, fmt.Errorf("pods is required for type %s", metric.Type)
+		}
+		return &autoscalingv2beta2.MetricSpec{
+			Type: autoscalingv2beta2.PodsMetricSourceType,
+			Pods: &autoscalingv2beta2.PodsMetricSource{
+				Metric: metricIdentifierToHPAIdentifier(metric.Pods.Metric),
+				Target: metricTargetToHPATarget(metric.Pods.Target),
+			},
+		}, nil
+	case v1alpha1.ObjectScaleMetricType:
+		if metric.Object == nil {
+			return nil, fmt.Errorf("object is required for type %s", metric.Type)
+		}
+		return &autoscalingv2beta2.MetricSpec{
+			Type: autoscalingv2beta2.ObjectMetricSourceType,
+			Object: &autoscalingv2beta2.ObjectMetricSource{
+				DescribedObject: autoscalingv2beta2.CrossVersionObjectReference{
+					Kind:       metric.Object.DescribedObject.Kind,
+					Name:       metric.Object.DescribedObject.Name,
+					APIVersion: metric.Object.DescribedObject.APIVersion,
+				},
+				Metric: metricIdentifierToHPAIdentifier(metric.Object.Metric),
+				Target: metricTargetToHPATarget(metric.Object.Target),
+			},
+		}, nil
+	case v1alpha1.ExternalScaleMetricType:
+		if metric.External == nil {
+			return nil, fmt.Errorf("external is required for type %s", metric.Type)
+		}
+		return &autoscalingv2beta2.MetricSpec{
+			Type: autoscalingv2beta2.ExternalMetricSourceType,
+			External: &autoscalingv2beta2.ExternalMetricSource{
+				Metric: metricIdentifierToHPAIdentifier(metric.External.Metric),
+				Target: metricTargetToHPATarget(metric.External.Target),
+			},
+		}, nil
+	case v1alpha1.PrometheusScaleMetricType:
+		return nil, fmt.Errorf("HorizontalPodAutoscalers can't query Prometheus, expose the query through an external metrics adapter instead")
+	case v1alpha1.KafkaScaleMetricType:
+		return nil, fmt.Errorf("HorizontalPodAutoscalers can't fetch Kafka consumer lag, expose the lag through an external metrics adapter instead")
+	case v1alpha1.RabbitMQScaleMetricType:
+		return nil, fmt.Errorf("HorizontalPodAutoscalers can't read RabbitMQ queues, expose the queue through an external metrics adapter instead")
+	}
+
+	return nil, fmt.Errorf("unsupported metric type %s", metric.Type)
+}
+
+// metricTarget returns the target of a metric which isn't a Prometheus metric, its source must be set
+func metricTarget(metric *v1alpha1.ScaleMetric) *v1alpha1.MetricTarget {
+	switch metric.Type {
+	case v1alpha1.ResourceScaleMetricType:
+		return &metric.Resource.Target
+	case v1alpha1.PodScaleMetricType:
+		return &metric.Pods.Target
+	case v1alpha1.ObjectScaleMetricType:
+		return &metric.Object.Target
+	case v1alpha1.ExternalScaleMetricType:
+		return &metric.External.Target
+	}
+
+	return &v1alpha1.MetricTarget{}
+}
+
+func metricTargetToHPATarget(target v1alpha1.MetricTarget) autoscalingv2beta2.MetricTarget {
+	return autoscalingv2beta2.MetricTarget{
+		Type:               autoscalingv2beta2.MetricTargetType(target.Type),
+		Value:              target.Value,
+		AverageValue:       target.AverageValue,
+		AverageUtilization: target.AverageUtilization,
+	}
+}
+
+func metricIdentifierToHPAIdentifier(identifier v1alpha1.MetricIdentifier) autoscalingv2beta2.MetricIdentifier {
+	return autoscalingv2beta2.MetricIdentifier{
+		Name:     identifier.Name,
+		Selector: identifier.Selector,
+	}
+}
+
+// scaleRulesToHPARules converts the rules of a direction, missing rules are disabled like Kratos does with defaults applied
+func scaleRulesToHPARules(rules *v1alpha1.ScaleRules) *autoscalingv2beta2.HPAScalingRules {
+	if rules == nil {
+		rules = &v1alpha1.ScaleRules{SelectPolicy: v1alpha1.DisabledPolicySelect}
+	}
+
+	stabilizationWindowSeconds := rules.StabilizationWindowSeconds
+	selectPolicy := autoscalingv2beta2.ScalingPolicySelect(rules.SelectPolicy)
+
+	result := &autoscalingv2beta2.HPAScalingRules{
+		StabilizationWindowSeconds: &stabilizationWindowSeconds,
+		SelectPolicy:               &selectPolicy,
+	}
+
+	for _, policy := range rules.Policies {
+		result.Policies = append(result.Policies, autoscalingv2beta2.HPAScalingPolicy{
+			Type:          autoscalingv2beta2.HPAScalingPolicyType(policy.Type),
+			Value:         policy.Value,
+			PeriodSeconds: policy.PeriodSeconds,
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,265 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package convert
+
+import (
+	"github.com/adobe/kratos/api/common"
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/adobe/kratos/scale"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func int32Pointer(value int32) *int32 {
+	return &value
+}
+
+func cpuHPASpec() *autoscalingv2beta2.HorizontalPodAutoscalerSpec {
+	return &autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+		ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{Kind: "Deployment", Name: "nginx", APIVersion: "apps/v1"},
+		MinReplicas:    int32Pointer(2),
+		MaxReplicas:    10,
+		Metrics: []autoscalingv2beta2.MetricSpec{{
+			Type: autoscalingv2beta2.ResourceMetricSourceType,
+			Resource: &autoscalingv2beta2.ResourceMetricSource{
+				Name:   corev1.ResourceCPU,
+				Target: autoscalingv2beta2.MetricTarget{Type: autoscalingv2beta2.UtilizationMetricType, AverageUtilization: int32Pointer(70)},
+			},
+		}},
+	}
+}
+
+func cpuKratosSpec() *v1alpha1.KratosSpec {
+	return &v1alpha1.KratosSpec{
+		Target:      v1alpha1.ScaleTargetReference{Kind: "Deployment", Name: "nginx"},
+		MinReplicas: int32Pointer(2),
+		MaxReplicas: 10,
+		Metrics: []v1alpha1.ScaleMetric{{
+			Type: v1alpha1.ResourceScaleMetricType,
+			Resource: &v1alpha1.ResourceMetricSource{
+				Name:   corev1.ResourceCPU,
+				Target: v1alpha1.MetricTarget{Type: v1alpha1.UtilizationMetricType, AverageUtilization: int32Pointer(70)},
+			},
+		}},
+	}
+}
+
+func withDefaults(spec *v1alpha1.KratosSpec) *v1alpha1.KratosSpec {
+	scale.NewDefaultsUpdater(&common.KratosParameters{StabilizationWindowSeconds: 300}).UpdateSpecWithDefaults(spec)
+	return spec
+}
+
+var _ = Describe("HorizontalPodAutoscaler to Kratos", func() {
+	It("Writes out the behavior defaults of HorizontalPodAutoscalers", func() {
+		hpa := cpuHPASpec()
+		hpa.MinReplicas = nil
+
+		spec, warnings := HPAToSpec(hpa)
+
+		Expect(spec.Target).To(Equal(v1alpha1.ScaleTargetReference{Kind: "Deployment", Name: "nginx", APIVersion: "apps/v1"}))
+		Expect(spec.Algorithm.Type).To(Equal(v1alpha1.HpaAlgorithmType))
+		Expect(spec.GetMinReplicas()).To(Equal(int32(1)), "minReplicas should default to 1 like HorizontalPodAutoscalers")
+		Expect(spec.MaxReplicas).To(Equal(int32(10)))
+		Expect(spec.Metrics).To(HaveLen(1))
+		Expect(spec.Metrics[0].Resource.Name).To(Equal(corev1.ResourceCPU))
+		Expect(spec.Metrics[0].Resource.Container).To(BeEmpty(), "resource metrics should cover the whole pod")
+
+		Expect(spec.Behavior.ScaleUp).To(Equal(&v1alpha1.ScaleRules{
+			StabilizationWindowSeconds: 1,
+			SelectPolicy:               v1alpha1.MaxPolicySelect,
+			Policies: []v1alpha1.ScalingPolicy{
+				{Type: v1alpha1.PodsScalingPolicy, Value: 4, PeriodSeconds: 15},
+				{Type: v1alpha1.PercentScalingPolicy, Value: 100, PeriodSeconds: 15},
+			},
+		}))
+		Expect(spec.Behavior.ScaleDown).To(Equal(&v1alpha1.ScaleRules{
+			StabilizationWindowSeconds: 300,
+			SelectPolicy:               v1alpha1.MaxPolicySelect,
+			Policies:                   []v1alpha1.ScalingPolicy{{Type: v1alpha1.PercentScalingPolicy, Value: 100, PeriodSeconds: 15}},
+		}), "scale down should select the max policy, Kratos defaults to min")
+		Expect(spec.StabilizationWindowSeconds).To(Equal(int32(300)))
+
+		Expect(warnings).To(ConsistOf(ContainSubstring("behavior.scaleUp.stabilizationWindowSeconds")))
+	})
+
+	It("Converts all metric types", func() {
+		hpa := cpuHPASpec()
+		hpa.Metrics = append(hpa.Metrics,
+			autoscalingv2beta2.MetricSpec{
+				Type: autoscalingv2beta2.ContainerResourceMetricSourceType,
+				ContainerResource: &autoscalingv2beta2.ContainerResourceMetricSource{
+					Name:      corev1.ResourceMemory,
+					Container: "nginx",
+					Target:    autoscalingv2beta2.MetricTarget{Type: autoscalingv2beta2.AverageValueMetricType, AverageValue: resource.NewQuantity(100, resource.BinarySI)},
+				},
+			},
+			autoscalingv2beta2.MetricSpec{
+				Type: autoscalingv2beta2.PodsMetricSourceType,
+				Pods: &autoscalingv2beta2.PodsMetricSource{
+					Metric: autoscalingv2beta2.MetricIdentifier{Name: "requests_per_second"},
+					Target: autoscalingv2beta2.MetricTarget{Type: autoscalingv2beta2.AverageValueMetricType, AverageValue: resource.NewQuantity(10, resource.DecimalSI)},
+				},
+			},
+			autoscalingv2beta2.MetricSpec{
+				Type: autoscalingv2beta2.ObjectMetricSourceType,
+				Object: &autoscalingv2beta2.ObjectMetricSource{
+					DescribedObject: autoscalingv2beta2.CrossVersionObjectReference{Kind: "Ingress", Name: "nginx"},
+					Metric:          autoscalingv2beta2.MetricIdentifier{Name: "hits_per_second"},
+					Target:          autoscalingv2beta2.MetricTarget{Type: autoscalingv2beta2.ValueMetricType, Value: resource.NewQuantity(100, resource.DecimalSI)},
+				},
+			},
+			autoscalingv2beta2.MetricSpec{
+				Type: autoscalingv2beta2.ExternalMetricSourceType,
+				External: &autoscalingv2beta2.ExternalMetricSource{
+					Metric: autoscalingv2beta2.MetricIdentifier{Name: "queue_length"},
+					Target: autoscalingv2beta2.MetricTarget{Type: autoscalingv2beta2.AverageValueMetricType, AverageValue: resource.NewQuantity(30, resource.DecimalSI)},
+				},
+			},
+			autoscalingv2beta2.MetricSpec{Type: "Unknown"},
+		)
+
+		spec, warnings := HPAToSpec(hpa)
+
+		Expect(spec.Metrics).To(HaveLen(5))
+		Expect(spec.Metrics[1].Type).To(Equal(v1alpha1.ResourceScaleMetricType))
+		Expect(spec.Metrics[1].Resource.Container).To(Equal("nginx"))
+		Expect(spec.Metrics[2].Type).To(Equal(v1alpha1.PodScaleMetricType))
+		Expect(spec.Metrics[2].Pods.Metric.Name).To(Equal("requests_per_second"))
+		Expect(spec.Metrics[3].Type).To(Equal(v1alpha1.ObjectScaleMetricType))
+		Expect(spec.Metrics[3].Object.DescribedObject.Kind).To(Equal("Ingress"))
+		Expect(spec.Metrics[3].Object.Target.Value.Value()).To(Equal(int64(100)))
+		Expect(spec.Metrics[4].Type).To(Equal(v1alpha1.ExternalScaleMetricType))
+		Expect(spec.Metrics[4].External.Metric.Name).To(Equal("queue_length"))
+
+		Expect(warnings).To(ContainElement(ContainSubstring("metrics[5]: unsupported metric type Unknown")))
+	})
+
+	It("Keeps explicit behavior", func() {
+		hpa := cpuHPASpec()
+		disabled := autoscalingv2beta2.DisabledPolicySelect
+		hpa.Behavior = &autoscalingv2beta2.HorizontalPodAutoscalerBehavior{
+			ScaleUp: &autoscalingv2beta2.HPAScalingRules{
+				StabilizationWindowSeconds: int32Pointer(60),
+				Policies:                   []autoscalingv2beta2.HPAScalingPolicy{{Type: autoscalingv2beta2.PodsScalingPolicy, Value: 2, PeriodSeconds: 30}},
+			},
+			ScaleDown: &autoscalingv2beta2.HPAScalingRules{SelectPolicy: &disabled},
+		}
+
+		spec, warnings := HPAToSpec(hpa)
+
+		Expect(spec.Behavior.ScaleUp).To(Equal(&v1alpha1.ScaleRules{
+			StabilizationWindowSeconds: 60,
+			SelectPolicy:               v1alpha1.MaxPolicySelect,
+			Policies:                   []v1alpha1.ScalingPolicy{{Type: v1alpha1.PodsScalingPolicy, Value: 2, PeriodSeconds: 30}},
+		}))
+		Expect(spec.Behavior.ScaleDown.SelectPolicy).To(Equal(v1alpha1.DisabledPolicySelect))
+		Expect(spec.Behavior.ScaleDown.StabilizationWindowSeconds).To(Equal(int32(300)))
+		Expect(warnings).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Kratos to HorizontalPodAutoscaler", func() {
+	It("Converts the standard normalizer to the HorizontalPodAutoscaler defaults", func() {
+		spec := withDefaults(cpuKratosSpec())
+		spec.StabilizationWindowSeconds = 120
+
+		hpa, warnings := SpecToHPA(spec)
+
+		Expect(warnings).To(BeEmpty())
+		Expect(*hpa.MinReplicas).To(Equal(int32(2)))
+		Expect(hpa.MaxReplicas).To(Equal(int32(10)))
+		Expect(hpa.ScaleTargetRef).To(Equal(autoscalingv2beta2.CrossVersionObjectReference{Kind: "Deployment", Name: "nginx"}))
+		Expect(hpa.Metrics).To(HaveLen(1))
+		Expect(hpa.Metrics[0].Type).To(Equal(autoscalingv2beta2.ResourceMetricSourceType))
+		Expect(hpa.Behavior.ScaleUp).To(Equal(defaultHPAScaleUpRules()))
+		Expect(*hpa.Behavior.ScaleDown.StabilizationWindowSeconds).To(Equal(int32(120)), "scale down should be stabilized with the spec window")
+	})
+
+	It("Disables missing directions", func() {
+		spec := cpuKratosSpec()
+		spec.Behavior = &v1alpha1.ScaleBehavior{
+			ScaleUp: &v1alpha1.ScaleRules{
+				StabilizationWindowSeconds: 30,
+				Policies:                   []v1alpha1.ScalingPolicy{{Type: v1alpha1.PercentScalingPolicy, Value: 50, PeriodSeconds: 60}},
+			},
+		}
+
+		hpa, warnings := SpecToHPA(withDefaults(spec))
+
+		Expect(warnings).To(BeEmpty())
+		Expect(*hpa.Behavior.ScaleUp.SelectPolicy).To(Equal(autoscalingv2beta2.MaxPolicySelect))
+		Expect(*hpa.Behavior.ScaleUp.StabilizationWindowSeconds).To(Equal(int32(30)))
+		Expect(hpa.Behavior.ScaleUp.Policies).To(Equal([]autoscalingv2beta2.HPAScalingPolicy{{Type: autoscalingv2beta2.PercentScalingPolicy, Value: 50, PeriodSeconds: 60}}))
+		Expect(*hpa.Behavior.ScaleDown.SelectPolicy).To(Equal(autoscalingv2beta2.DisabledPolicySelect))
+	})
+
+	It("Reports what HorizontalPodAutoscalers can't represent", func() {
+		spec := cpuKratosSpec()
+		spec.MinReplicas = int32Pointer(0)
+		spec.Mode = v1alpha1.DryRunAutoscalerMode
+		spec.Algorithm = v1alpha1.Algorithm{Type: v1alpha1.StepAlgorithmType, Options: map[string]string{"step": "2"}}
+		spec.Fallback = &v1alpha1.Fallback{Policy: v1alpha1.MaxFallbackPolicy}
+		spec.Schedules = []v1alpha1.Schedule{{Name: "business-hours"}}
+		spec.Metrics[0].Resource.Container = "nginx"
+		spec.Metrics[0].Resource.Target.ActivationThreshold = resource.NewQuantity(1, resource.DecimalSI)
+		spec.Metrics = append(spec.Metrics, v1alpha1.ScaleMetric{
+			Type:       v1alpha1.PrometheusScaleMetricType,
+			Prometheus: &v1alpha1.PrometheusMetricSource{MetricQuery: "sum(rate(requests_total[1m]))"},
+		}, v1alpha1.ScaleMetric{
+			Type:  v1alpha1.KafkaScaleMetricType,
+			Kafka: &v1alpha1.KafkaMetricSource{ConsumerGroup: "processors", Topics: []string{"orders"}},
+		}, v1alpha1.ScaleMetric{
+			Type:     v1alpha1.RabbitMQScaleMetricType,
+			RabbitMQ: &v1alpha1.RabbitMQMetricSource{Queue: "orders"},
+		})
+
+		hpa, warnings := SpecToHPA(withDefaults(spec))
+
+		Expect(*hpa.MinReplicas).To(Equal(int32(1)))
+		Expect(hpa.Metrics).To(HaveLen(1))
+		Expect(hpa.Metrics[0].Type).To(Equal(autoscalingv2beta2.ContainerResourceMetricSourceType))
+		Expect(hpa.Metrics[0].ContainerResource.Container).To(Equal("nginx"))
+
+		Expect(warnings).To(ConsistOf(
+			HavePrefix("minReplicas:"),
+			HavePrefix("algorithm:"),
+			HavePrefix("mode:"),
+			HavePrefix("fallback:"),
+			HavePrefix("schedules:"),
+			HavePrefix("metrics[0]: HorizontalPodAutoscalers don't scale to zero"),
+			HavePrefix("metrics[1]: HorizontalPodAutoscalers can't query Prometheus"),
+			HavePrefix("metrics[2]: HorizontalPodAutoscalers can't fetch Kafka consumer lag"),
+			HavePrefix("metrics[3]: HorizontalPodAutoscalers can't read RabbitMQ queues"),
+		))
+	})
+
+	It("Round trips HorizontalPodAutoscalers", func() {
+		hpa := cpuHPASpec()
+		hpa.Behavior = &autoscalingv2beta2.HorizontalPodAutoscalerBehavior{
+			ScaleUp:   defaultHPAScaleUpRules(),
+			ScaleDown: defaultHPAScaleDownRules(),
+		}
+		hpa.Behavior.ScaleUp.StabilizationWindowSeconds = int32Pointer(30)
+
+		spec, warnings := HPAToSpec(hpa)
+		Expect(warnings).To(BeEmpty())
+
+		converted, warnings := SpecToHPA(withDefaults(spec))
+		Expect(warnings).To(BeEmpty())
+		Expect(converted).To(Equal(hpa))
+	})
+})
@@ -0,0 +1,113 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package convert
+
+import (
+	"github.com/adobe/kratos/api/v1alpha1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// HPAKind is the kind of HorizontalPodAutoscalers
+	HPAKind = "HorizontalPodAutoscaler"
+	// HPAAPIVersion is the API version of HorizontalPodAutoscalers written by default
+	HPAAPIVersion = "autoscaling/v2"
+	// HPABetaAPIVersion is the older API version of HorizontalPodAutoscalers with the same schema
+	HPABetaAPIVersion = "autoscaling/v2beta2"
+
+	specKey = "kratosSpec"
+)
+
+// ToKratos converts a HorizontalPodAutoscaler to a Kratos resource with the same name and namespace
+func ToKratos(hpa *autoscalingv2beta2.HorizontalPodAutoscaler) (*v1alpha1.Kratos, []string) {
+	spec, warnings := HPAToSpec(&hpa.Spec)
+
+	return &v1alpha1.Kratos{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha1.GroupVersion.String(),
+			Kind:       "Kratos",
+		},
+		ObjectMeta: objectMeta(&hpa.ObjectMeta),
+		Spec:       *spec,
+	}, warnings
+}
+
+// ToConfigMap converts a HorizontalPodAutoscaler to a ConfigMap with the spec under the 'kratosSpec' key, the ConfigMap
+// has the KratosSpecLabel so that it's validated on admission
+func ToConfigMap(hpa *autoscalingv2beta2.HorizontalPodAutoscaler) (*corev1.ConfigMap, []string, error) {
+	spec, warnings := HPAToSpec(&hpa.Spec)
+
+	specAsBytes, err := yaml.Marshal(spec)
+	if err != nil {
+		return nil, warnings, err
+	}
+
+	meta := objectMeta(&hpa.ObjectMeta)
+	labels := map[string]string{v1alpha1.KratosSpecLabel: "true"}
+	for key, value := range meta.Labels {
+		labels[key] = value
+	}
+	meta.Labels = labels
+
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: meta,
+		Data: map[string]string{
+			specKey: string(specAsBytes),
+		},
+	}, warnings, nil
+}
+
+// ToHPA converts a Kratos spec to a HorizontalPodAutoscaler with the metadata of the Kratos resource or ConfigMap.
+// The spec must have defaults applied, apiVersion is either autoscaling/v2 or autoscaling/v2beta2.
+func ToHPA(meta *metav1.ObjectMeta, spec *v1alpha1.KratosSpec, apiVersion string) (*autoscalingv2beta2.HorizontalPodAutoscaler, []string) {
+	hpaSpec, warnings := SpecToHPA(spec)
+
+	return &autoscalingv2beta2.HorizontalPodAutoscaler{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: apiVersion,
+			Kind:       HPAKind,
+		},
+		ObjectMeta: objectMeta(meta),
+		Spec:       *hpaSpec,
+	}, warnings
+}
+
+// objectMeta keeps the name, namespace, labels and annotations, server populated fields and the
+// configuration last applied by kubectl are left out
+func objectMeta(meta *metav1.ObjectMeta) metav1.ObjectMeta {
+	var annotations map[string]string
+	for key, value := range meta.Annotations {
+		if key == corev1.LastAppliedConfigAnnotation {
+			continue
+		}
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[key] = value
+	}
+
+	return metav1.ObjectMeta{
+		Name:        meta.Name,
+		Namespace:   meta.Namespace,
+		Labels:      meta.Labels,
+		Annotations: annotations,
+	}
+}
@@ -0,0 +1,256 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var dayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// field describes the allowed values of a single field of the expression
+type field struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: monthNames}
+	// 7 is accepted as sunday like in most cron implementations
+	dowField = field{name: "day of week", min: 0, max: 7, names: dayNames}
+)
+
+// Expression is a parsed standard cron expression with minute, hour, day of month, month and day of week fields
+type Expression struct {
+	minutes  uint64
+	hours    uint64
+	doms     uint64
+	months   uint64
+	dows     uint64
+	domStar  bool
+	dowStar  bool
+	original string
+}
+
+// Parse parses a 5 field cron expression. Fields support '*', lists, ranges, steps and
+// 3 letter month and day names, the predefined @yearly, @monthly, @weekly, @daily and @hourly are supported too.
+func Parse(expression string) (*Expression, error) {
+	spec := strings.TrimSpace(expression)
+	if macro, found := macros[strings.ToLower(spec)]; found {
+		spec = macro
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 fields in cron expression '%s', found %d", expression, len(fields))
+	}
+
+	result := &Expression{original: expression}
+	var err error
+
+	if result.minutes, err = parseField(fields[0], minuteField); err != nil {
+		return nil, err
+	}
+	if result.hours, err = parseField(fields[1], hourField); err != nil {
+		return nil, err
+	}
+	if result.doms, err = parseField(fields[2], domField); err != nil {
+		return nil, err
+	}
+	if result.months, err = parseField(fields[3], monthField); err != nil {
+		return nil, err
+	}
+	if result.dows, err = parseField(fields[4], dowField); err != nil {
+		return nil, err
+	}
+
+	// sunday can be written as 0 or 7
+	if result.dows&(1<<7) != 0 {
+		result.dows |= 1
+	}
+
+	result.domStar = strings.HasPrefix(fields[2], "*")
+	result.dowStar = strings.HasPrefix(fields[4], "*")
+
+	return result, nil
+}
+
+// Matches returns true when the minute of t, in the location of t, is selected by the expression
+func (e *Expression) Matches(t time.Time) bool {
+	return has(e.months, int(t.Month())) && e.dayMatches(t) && has(e.hours, t.Hour()) && has(e.minutes, t.Minute())
+}
+
+func (e *Expression) dayMatches(t time.Time) bool {
+	domMatches := has(e.doms, t.Day())
+	dowMatches := has(e.dows, int(t.Weekday()))
+
+	// when both day fields are restricted a day matching any of them is selected
+	if !e.domStar && !e.dowStar {
+		return domMatches || dowMatches
+	}
+
+	return domMatches && dowMatches
+}
+
+// Previous returns the latest time not after t which matches the expression, the search goes back until limit.
+// False is returned when there's no match in the searched range. Months, days and hours which don't match are
+// skipped as a whole, so only the minutes of a matching hour are checked one by one.
+func (e *Expression) Previous(t time.Time, limit time.Time) (time.Time, bool) {
+	current := t.Truncate(time.Minute)
+
+	for !current.Before(limit) {
+		year, month, day := current.Date()
+		location := current.Location()
+
+		switch {
+		case !has(e.months, int(month)):
+			current = lastMinuteBefore(current, time.Date(year, month, 1, 0, 0, 0, 0, location))
+		case !e.dayMatches(current):
+			current = lastMinuteBefore(current, time.Date(year, month, day, 0, 0, 0, 0, location))
+		case !has(e.hours, current.Hour()):
+			current = lastMinuteBefore(current, time.Date(year, month, day, current.Hour(), 0, 0, 0, location))
+		case !has(e.minutes, current.Minute()):
+			current = current.Add(-time.Minute)
+		default:
+			return current, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// lastMinuteBefore returns the minute before start, the start of the month, day or hour of current. The search goes
+// back one minute when start isn't before current, as the wall clock time of start may be ambiguous on DST changes.
+func lastMinuteBefore(current time.Time, start time.Time) time.Time {
+	if !start.Before(current) {
+		return current.Add(-time.Minute)
+	}
+
+	return start.Add(-time.Minute)
+}
+
+func (e *Expression) String() string {
+	return e.original
+}
+
+func has(bits uint64, value int) bool {
+	return bits&(1<<uint(value)) != 0
+}
+
+func parseField(expression string, f field) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(expression, ",") {
+		partBits, err := parsePart(part, f)
+		if err != nil {
+			return 0, err
+		}
+		bits |= partBits
+	}
+
+	return bits, nil
+}
+
+// parsePart parses a single element of a list: '*', a value or a range, optionally followed by a step
+func parsePart(part string, f field) (uint64, error) {
+	rangeAndStep := strings.Split(part, "/")
+	if len(rangeAndStep) > 2 {
+		return 0, fmt.Errorf("invalid %s '%s': more than one step", f.name, part)
+	}
+
+	start, end := f.min, f.max
+	step := 1
+
+	switch {
+	case rangeAndStep[0] == "*":
+	case strings.Contains(rangeAndStep[0], "-"):
+		bounds := strings.SplitN(rangeAndStep[0], "-", 2)
+		var err error
+		if start, err = parseValue(bounds[0], f); err != nil {
+			return 0, err
+		}
+		if end, err = parseValue(bounds[1], f); err != nil {
+			return 0, err
+		}
+		if start > end {
+			return 0, fmt.Errorf("invalid %s range '%s': start is after end", f.name, rangeAndStep[0])
+		}
+	default:
+		value, err := parseValue(rangeAndStep[0], f)
+		if err != nil {
+			return 0, err
+		}
+		start = value
+		// a single value with a step runs until the end of the field, e.g. 5/15 for minutes
+		if len(rangeAndStep) == 1 {
+			end = value
+		}
+	}
+
+	if len(rangeAndStep) == 2 {
+		var err error
+		if step, err = strconv.Atoi(rangeAndStep[1]); err != nil || step <= 0 {
+			return 0, fmt.Errorf("invalid %s step '%s'", f.name, rangeAndStep[1])
+		}
+	}
+
+	var bits uint64
+	for value := start; value <= end; value += step {
+		bits |= 1 << uint(value)
+	}
+
+	return bits, nil
+}
+
+func parseValue(value string, f field) (int, error) {
+	if named, found := f.names[strings.ToLower(value)]; found {
+		return named, nil
+	}
+
+	result, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s '%s'", f.name, value)
+	}
+
+	if result < f.min || result > f.max {
+		return 0, fmt.Errorf("%s %d out of range [%d, %d]", f.name, result, f.min, f.max)
+	}
+
+	return result, nil
+}
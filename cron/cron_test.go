@@ -0,0 +1,108 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package cron
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cron", func() {
+
+	DescribeTable("Matches",
+		func(expression string, at string, expected bool) {
+			parsed, err := Parse(expression)
+			Expect(err).To(BeNil())
+
+			t, err := time.Parse(time.RFC3339, at)
+			Expect(err).To(BeNil())
+
+			Expect(parsed.Matches(t)).To(Equal(expected))
+		},
+		// 2021-07-05 is a monday
+		Entry("every minute", "* * * * *", "2021-07-05T13:47:00Z", true),
+		Entry("exact time", "30 8 * * *", "2021-07-05T08:30:00Z", true),
+		Entry("other minute", "30 8 * * *", "2021-07-05T08:31:00Z", false),
+		Entry("minute step", "*/15 * * * *", "2021-07-05T08:45:00Z", true),
+		Entry("minute step offset", "5/15 * * * *", "2021-07-05T08:50:00Z", true),
+		Entry("hour range", "0 8-17 * * *", "2021-07-05T18:00:00Z", false),
+		Entry("list", "0 8,12,18 * * *", "2021-07-05T12:00:00Z", true),
+		Entry("day names", "0 8 * * MON-FRI", "2021-07-05T08:00:00Z", true),
+		Entry("weekend", "0 8 * * SAT,SUN", "2021-07-05T08:00:00Z", false),
+		Entry("sunday as 7", "0 8 * * 7", "2021-07-04T08:00:00Z", true),
+		Entry("month names", "0 0 1 jan *", "2021-01-01T00:00:00Z", true),
+		Entry("day of month or day of week", "0 0 13 * FRI", "2021-07-09T00:00:00Z", true),
+		Entry("day of month and any day of week", "0 0 13 * *", "2021-07-09T00:00:00Z", false),
+		Entry("daily macro", "@daily", "2021-07-05T00:00:00Z", true),
+		Entry("hourly macro", "@hourly", "2021-07-05T10:30:00Z", false),
+	)
+
+	DescribeTable("Invalid expressions",
+		func(expression string) {
+			_, err := Parse(expression)
+			Expect(err).NotTo(BeNil())
+		},
+		Entry("too few fields", "* * * *"),
+		Entry("minute out of range", "60 * * * *"),
+		Entry("inverted range", "0 17-8 * * *"),
+		Entry("zero step", "*/0 * * * *"),
+		Entry("unknown name", "0 0 * * MONDAY"),
+		Entry("day name in month field", "0 0 * MON *"),
+	)
+
+	It("Previous match in time zone", func() {
+		parsed, err := Parse("0 8 * * MON-FRI")
+		Expect(err).To(BeNil())
+
+		location, err := time.LoadLocation("Europe/Bucharest")
+		Expect(err).To(BeNil())
+
+		// 09:30 in Bucharest on a monday
+		now := time.Date(2021, 7, 5, 6, 30, 0, 0, time.UTC).In(location)
+
+		previous, found := parsed.Previous(now, now.Add(-2*time.Hour))
+		Expect(found).To(BeTrue())
+		Expect(previous.UTC()).To(Equal(time.Date(2021, 7, 5, 5, 0, 0, 0, time.UTC)))
+
+		_, found = parsed.Previous(now, now.Add(-time.Hour))
+		Expect(found).To(BeFalse(), "no match should be found after the limit")
+	})
+
+	DescribeTable("Previous match",
+		func(expression string, now string, lookBack time.Duration, expected string) {
+			parsed, err := Parse(expression)
+			Expect(err).To(BeNil())
+
+			location, err := time.LoadLocation("Europe/Bucharest")
+			Expect(err).To(BeNil())
+
+			t, err := time.ParseInLocation("2006-01-02 15:04", now, location)
+			Expect(err).To(BeNil())
+
+			previous, found := parsed.Previous(t, t.Add(-lookBack))
+			Expect(found).To(BeTrue())
+			Expect(previous.Format("2006-01-02 15:04")).To(Equal(expected))
+		},
+		Entry("same minute", "*/15 * * * *", "2021-07-05 10:45", time.Hour, "2021-07-05 10:45"),
+		Entry("earlier minute of the hour", "5 * * * *", "2021-07-05 10:45", time.Hour, "2021-07-05 10:05"),
+		Entry("previous day", "30 23 * * *", "2021-07-05 10:45", 24*time.Hour, "2021-07-04 23:30"),
+		Entry("previous week", "0 8 * * FRI", "2021-07-05 07:00", 7*24*time.Hour, "2021-07-02 08:00"),
+		Entry("previous year", "0 0 1 jan *", "2021-07-05 10:45", 366*24*time.Hour, "2021-01-01 00:00"),
+		Entry("day before a DST change", "30 23 * * *", "2021-03-28 05:00", 24*time.Hour, "2021-03-27 23:30"),
+		Entry("repeated hour of a DST change", "59 2 * * *", "2021-10-31 03:30", 2*time.Hour, "2021-10-31 02:59"),
+	)
+})
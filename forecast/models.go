@@ -0,0 +1,157 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package forecast
+
+import (
+	"fmt"
+	"math"
+)
+
+// smoothing factors of Holt-Winters for the level, trend and seasonal components
+const (
+	holtWintersAlpha = 0.5
+	holtWintersBeta  = 0.05
+	holtWintersGamma = 0.3
+)
+
+// Result is a forecast with the confidence of the model, confidence is in [0, 1]
+type Result struct {
+	Value      float64
+	Confidence float64
+}
+
+// Model forecasts the value horizon steps after the last value of a history with seasons of seasonLength steps
+type Model func(values []float64, seasonLength int, horizon int) (Result, error)
+
+// SeasonalNaive forecasts the average of the values at the same time in all past seasons.
+// The confidence is backtested on the last season, every value is predicted from the seasons before it.
+func SeasonalNaive(values []float64, seasonLength int, horizon int) (Result, error) {
+	if err := checkHistory(values, seasonLength, horizon); err != nil {
+		return Result{}, err
+	}
+
+	forecastIndex := len(values) - 1 + horizon
+	predicted, _ := sameTimeAverage(values, seasonLength, forecastIndex)
+
+	actual := values[len(values)-seasonLength:]
+	backtest := make([]float64, seasonLength)
+	for i := range backtest {
+		backtest[i], _ = sameTimeAverage(values, seasonLength, len(values)-seasonLength+i)
+	}
+
+	return Result{Value: math.Max(predicted, 0), Confidence: confidence(actual, backtest)}, nil
+}
+
+// HoltWinters forecasts with additive triple exponential smoothing, components are initialized from the first two seasons.
+// The confidence is derived from the one step ahead predictions over the last season.
+func HoltWinters(values []float64, seasonLength int, horizon int) (Result, error) {
+	if err := checkHistory(values, seasonLength, horizon); err != nil {
+		return Result{}, err
+	}
+
+	// the season means are the levels in the middle of the seasons, the level is moved to the end of the first season
+	firstSeasonMean := mean(values[:seasonLength])
+	trend := (mean(values[seasonLength:2*seasonLength]) - firstSeasonMean) / float64(seasonLength)
+	middle := float64(seasonLength-1) / 2
+	level := firstSeasonMean + trend*middle
+	seasonal := make([]float64, seasonLength)
+	for i := range seasonal {
+		seasonal[i] = values[i] - (firstSeasonMean + trend*(float64(i)-middle))
+	}
+
+	backtestStart := len(values) - seasonLength
+	backtest := make([]float64, 0, seasonLength)
+
+	for t := seasonLength; t < len(values); t++ {
+		season := t % seasonLength
+
+		if t >= backtestStart {
+			backtest = append(backtest, level+trend+seasonal[season])
+		}
+
+		previousLevel := level
+		level = holtWintersAlpha*(values[t]-seasonal[season]) + (1-holtWintersAlpha)*(level+trend)
+		trend = holtWintersBeta*(level-previousLevel) + (1-holtWintersBeta)*trend
+		seasonal[season] = holtWintersGamma*(values[t]-level) + (1-holtWintersGamma)*seasonal[season]
+	}
+
+	predicted := level + float64(horizon)*trend + seasonal[(len(values)-1+horizon)%seasonLength]
+
+	return Result{Value: math.Max(predicted, 0), Confidence: confidence(values[backtestStart:], backtest)}, nil
+}
+
+// checkHistory requires at least two seasons of history so models can be backtested on a season they weren't fitted on
+func checkHistory(values []float64, seasonLength int, horizon int) error {
+	if seasonLength < 1 {
+		return fmt.Errorf("season length must be greater than 0, got: %d", seasonLength)
+	}
+
+	if horizon < 1 || horizon > seasonLength {
+		return fmt.Errorf("horizon must be between 1 and the season length %d, got: %d", seasonLength, horizon)
+	}
+
+	if len(values) < 2*seasonLength {
+		return fmt.Errorf("at least 2 seasons of %d values are required, got: %d values", seasonLength, len(values))
+	}
+
+	return nil
+}
+
+// sameTimeAverage averages the values at the same time of the seasons before index
+func sameTimeAverage(values []float64, seasonLength int, index int) (float64, bool) {
+	sum := float64(0)
+	count := 0
+
+	for i := index - seasonLength; i >= 0; i -= seasonLength {
+		if i < len(values) {
+			sum += values[i]
+			count++
+		}
+	}
+
+	if count == 0 {
+		return 0, false
+	}
+
+	return sum / float64(count), true
+}
+
+// confidence is 1 minus the error of the predictions relative to the actual values, bounded to [0, 1]
+func confidence(actual []float64, predicted []float64) float64 {
+	absoluteError := float64(0)
+	absoluteActual := float64(0)
+
+	for i := range actual {
+		absoluteError += math.Abs(actual[i] - predicted[i])
+		absoluteActual += math.Abs(actual[i])
+	}
+
+	if absoluteActual == 0 {
+		if absoluteError == 0 {
+			return 1
+		}
+		return 0
+	}
+
+	return math.Max(0, 1-absoluteError/absoluteActual)
+}
+
+func mean(values []float64) float64 {
+	sum := float64(0)
+	for _, value := range values {
+		sum += value
+	}
+
+	return sum / float64(len(values))
+}
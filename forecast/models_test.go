@@ -0,0 +1,92 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package forecast
+
+import (
+	"math"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// seasonalValues returns seasons of a sine wave around base with an optional trend per value
+func seasonalValues(seasons int, seasonLength int, base float64, amplitude float64, trend float64) []float64 {
+	values := make([]float64, seasons*seasonLength)
+	for i := range values {
+		values[i] = base + amplitude*math.Sin(2*math.Pi*float64(i%seasonLength)/float64(seasonLength)) + trend*float64(i)
+	}
+	return values
+}
+
+var _ = Describe("Models", func() {
+
+	It("Seasonal naive repeats the last seasons", func() {
+		values := seasonalValues(2, 24, 100, 50, 0)
+
+		result, err := SeasonalNaive(values, 24, 6)
+
+		Expect(err).To(BeNil())
+		Expect(result.Value).To(BeNumerically("~", values[5], 0.001), "value 6 steps ahead should be the one at the same time of the last season")
+		Expect(result.Confidence).To(BeNumerically("~", 1, 0.001), "perfectly seasonal history should have full confidence")
+	})
+
+	It("Seasonal naive averages seasons", func() {
+		values := []float64{10, 20, 30, 40}
+
+		result, err := SeasonalNaive(values, 2, 1)
+
+		Expect(err).To(BeNil())
+		Expect(result.Value).To(Equal(float64(20)), "average of 10 and 30")
+	})
+
+	It("Holt-Winters follows the trend", func() {
+		values := seasonalValues(3, 24, 100, 20, 0.5)
+
+		result, err := HoltWinters(values, 24, 1)
+
+		Expect(err).To(BeNil())
+		expected := 100 + 0.5*float64(len(values))
+		Expect(result.Value).To(BeNumerically("~", expected, 5))
+		Expect(result.Confidence).To(BeNumerically(">", 0.9))
+	})
+
+	It("Noise lowers confidence", func() {
+		values := seasonalValues(2, 24, 100, 50, 0)
+		for i := 24; i < 48; i += 2 {
+			values[i] *= 2
+		}
+
+		result, err := SeasonalNaive(values, 24, 1)
+
+		Expect(err).To(BeNil())
+		Expect(result.Confidence).To(BeNumerically("<", 0.8))
+	})
+
+	It("Forecast is not negative", func() {
+		values := seasonalValues(3, 24, 10, 5, -0.5)
+
+		result, err := HoltWinters(values, 24, 24)
+
+		Expect(err).To(BeNil())
+		Expect(result.Value).To(BeNumerically(">=", 0))
+	})
+
+	It("Not enough history", func() {
+		_, err := SeasonalNaive(make([]float64, 30), 24, 1)
+		Expect(err).NotTo(BeNil())
+
+		_, err = HoltWinters(make([]float64, 48), 24, 25)
+		Expect(err).NotTo(BeNil(), "horizon longer than a season should result in error")
+	})
+})
@@ -0,0 +1,222 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package forecast
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/adobe/kratos/metrics"
+	"github.com/go-logr/logr"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// histories not used for this long belong to deleted autoscalers or changed specs and are dropped
+const historyIdleTimeout = time.Hour
+
+// RangeFetcherProvider resolves the history fetcher of a metric, implemented by metrics.MetricsFactory
+type RangeFetcherProvider interface {
+	GetRangeFetcher(scaleMetric *v1alpha1.ScaleMetric) (metrics.RangeFetcher, error)
+}
+
+// Forecast is the expected value of a metric at a point in time
+type Forecast struct {
+	Model      v1alpha1.PredictionModel
+	Value      float64
+	Time       time.Time
+	Confidence float64
+}
+
+// history is the aligned history of a metric, it's refreshed once a step passed
+type history struct {
+	values   []float64
+	end      time.Time
+	lastUsed time.Time
+}
+
+// Predictor forecasts metrics from their history, histories are cached for a step
+type Predictor struct {
+	rangeFetchers RangeFetcherProvider
+	models        map[v1alpha1.PredictionModel]Model
+	log           logr.Logger
+
+	mutex     sync.Mutex
+	histories map[string]*history
+}
+
+func NewPredictor(rangeFetchers RangeFetcherProvider) *Predictor {
+	return &Predictor{
+		rangeFetchers: rangeFetchers,
+		models: map[v1alpha1.PredictionModel]Model{
+			v1alpha1.SeasonalNaivePredictionModel: SeasonalNaive,
+			v1alpha1.HoltWintersPredictionModel:   HoltWinters,
+		},
+		log:       ctrl.Log.WithName("predictor"),
+		histories: make(map[string]*history),
+	}
+}
+
+// Predict forecasts the metric of the autoscaler of the fetch context at now plus the lead time of its prediction, the prediction must have defaults applied
+func (p *Predictor) Predict(scaleMetric *v1alpha1.ScaleMetric, fetchContext *metrics.FetchContext, now time.Time) (*Forecast, error) {
+	if scaleMetric.Prometheus == nil || scaleMetric.Prometheus.Prediction == nil {
+		return nil, fmt.Errorf("prediction is not defined for metric %s", scaleMetric.GetMetricName())
+	}
+
+	prediction := scaleMetric.Prometheus.Prediction
+
+	model, found := p.models[prediction.Model]
+	if !found {
+		return nil, fmt.Errorf("unknown prediction model: %s", prediction.Model)
+	}
+
+	step := prediction.Step.Duration
+	season := prediction.Season.Duration
+	if step <= 0 || season%step != 0 {
+		return nil, fmt.Errorf("season %s must be a multiple of step %s", season, step)
+	}
+	seasonLength := int(season / step)
+
+	values, end, err := p.getHistory(scaleMetric, fetchContext, now)
+	if err != nil {
+		return nil, err
+	}
+
+	forecastTime := now.Add(prediction.LeadTime.Duration)
+	horizon := int(math.Ceil(float64(forecastTime.Sub(end)) / float64(step)))
+	if horizon < 1 {
+		horizon = 1
+	}
+
+	result, err := model(values, seasonLength, horizon)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Forecast{
+		Model:      prediction.Model,
+		Value:      result.Value,
+		Time:       forecastTime,
+		Confidence: result.Confidence,
+	}, nil
+}
+
+// getHistory returns the cached history of the metric, it's fetched again when a new step is available
+func (p *Predictor) getHistory(scaleMetric *v1alpha1.ScaleMetric, fetchContext *metrics.FetchContext, now time.Time) ([]float64, time.Time, error) {
+	prediction := scaleMetric.Prometheus.Prediction
+	step := prediction.Step.Duration
+	end := now.Truncate(step)
+	key, err := historyKey(scaleMetric, fetchContext)
+	if err != nil {
+		return nil, end, err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.expireHistories(now)
+
+	if cached, found := p.histories[key]; found && !cached.end.Before(end) {
+		cached.lastUsed = now
+		return cached.values, cached.end, nil
+	}
+
+	rangeFetcher, err := p.rangeFetchers.GetRangeFetcher(scaleMetric)
+	if err != nil {
+		return nil, end, err
+	}
+
+	count := int(prediction.HistorySeasons) * int(prediction.Season.Duration/step)
+	start := end.Add(-time.Duration(count-1) * step)
+
+	p.log.V(1).Info("fetching history", "metric", scaleMetric.GetMetricName(), "start", start, "end", end, "step", step)
+
+	samples, err := rangeFetcher.FetchRange(scaleMetric, fetchContext, start, end, step)
+	if err != nil {
+		return nil, end, err
+	}
+
+	values, err := Align(samples, start, step, count)
+	if err != nil {
+		return nil, end, err
+	}
+
+	p.histories[key] = &history{values: values, end: end, lastUsed: now}
+
+	return values, end, nil
+}
+
+func (p *Predictor) expireHistories(now time.Time) {
+	for key, cached := range p.histories {
+		if now.Sub(cached.lastUsed) > historyIdleTimeout {
+			delete(p.histories, key)
+		}
+	}
+}
+
+// historyKey identifies a history by the rendered query, the namespace is part of it since the auth secret of the query is read from it
+func historyKey(scaleMetric *v1alpha1.ScaleMetric, fetchContext *metrics.FetchContext) (string, error) {
+	prediction := scaleMetric.Prometheus.Prediction
+
+	query, err := metrics.RenderQuery(scaleMetric.Prometheus.MetricQuery, fetchContext)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s|%s/%s|%s|%s|%s|%d", scaleMetric.Prometheus.PrometheusEndpoint, fetchContext.Namespace, scaleMetric.Prometheus.AuthSecretName,
+		query, prediction.Step.Duration, prediction.Season.Duration, prediction.HistorySeasons), nil
+}
+
+// Align places the samples on count steps from start. Missing values are filled with the previous value,
+// leading ones with the first available value. An error is returned when less than half of the values are available.
+func Align(samples []metrics.Sample, start time.Time, step time.Duration, count int) ([]float64, error) {
+	values := make([]float64, count)
+	available := make([]bool, count)
+	availableCount := 0
+
+	for _, sample := range samples {
+		offset := sample.Timestamp.Sub(start)
+		index := int((offset + step/2) / step)
+
+		if offset < -step/2 || index >= count || available[index] {
+			continue
+		}
+
+		values[index] = sample.Value
+		available[index] = true
+		availableCount++
+	}
+
+	if availableCount*2 < count {
+		return nil, fmt.Errorf("not enough history: %d of %d values are available", availableCount, count)
+	}
+
+	first := 0
+	for !available[first] {
+		first++
+	}
+
+	for i := range values {
+		switch {
+		case i < first:
+			values[i] = values[first]
+		case !available[i]:
+			values[i] = values[i-1]
+		}
+	}
+
+	return values, nil
+}
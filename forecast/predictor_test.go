@@ -0,0 +1,119 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package forecast
+
+import (
+	"time"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/adobe/kratos/metrics"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type fakeRangeFetcher struct {
+	samples []metrics.Sample
+	calls   int
+}
+
+func (f *fakeRangeFetcher) FetchRange(_ *v1alpha1.ScaleMetric, _ *metrics.FetchContext, start time.Time, end time.Time, step time.Duration) ([]metrics.Sample, error) {
+	f.calls++
+	result := make([]metrics.Sample, 0)
+	for _, sample := range f.samples {
+		if !sample.Timestamp.Before(start) && !sample.Timestamp.After(end) {
+			result = append(result, sample)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeRangeFetcher) GetRangeFetcher(_ *v1alpha1.ScaleMetric) (metrics.RangeFetcher, error) {
+	return f, nil
+}
+
+var _ = Describe("Predictor", func() {
+	now := time.Date(2021, 7, 5, 12, 0, 30, 0, time.UTC)
+	step := 5 * time.Minute
+	scaleMetric := &v1alpha1.ScaleMetric{
+		Type: v1alpha1.PrometheusScaleMetricType,
+		Prometheus: &v1alpha1.PrometheusMetricSource{
+			MetricQuery: "sum(rate(nginx_server_requests[1m]))",
+			Prediction: &v1alpha1.Prediction{
+				Mode:           v1alpha1.ObservePredictionMode,
+				Model:          v1alpha1.SeasonalNaivePredictionModel,
+				Season:         metav1.Duration{Duration: time.Hour},
+				HistorySeasons: 2,
+				Step:           metav1.Duration{Duration: step},
+				LeadTime:       metav1.Duration{Duration: 10 * time.Minute},
+			},
+		},
+	}
+
+	fetchContext := &metrics.FetchContext{Namespace: "default"}
+	var fetcher *fakeRangeFetcher
+
+	BeforeEach(func() {
+		// the value is the minute of the hour, one sample per step over the last 2 hours
+		fetcher = &fakeRangeFetcher{}
+		for t := now.Truncate(step).Add(-2 * time.Hour); !t.After(now); t = t.Add(step) {
+			fetcher.samples = append(fetcher.samples, metrics.Sample{Timestamp: t, Value: float64(t.Minute())})
+		}
+	})
+
+	It("Forecast lead time ahead", func() {
+		predictor := NewPredictor(fetcher)
+
+		forecast, err := predictor.Predict(scaleMetric, fetchContext, now)
+
+		Expect(err).To(BeNil())
+		Expect(forecast.Time).To(Equal(now.Add(10 * time.Minute)))
+		Expect(forecast.Value).To(Equal(float64(15)), "the forecast is for the step after 12:10:30")
+		Expect(forecast.Confidence).To(Equal(float64(1)))
+	})
+
+	It("History cached for a step", func() {
+		predictor := NewPredictor(fetcher)
+
+		_, _ = predictor.Predict(scaleMetric, fetchContext, now)
+		_, _ = predictor.Predict(scaleMetric, fetchContext, now.Add(time.Minute))
+		Expect(fetcher.calls).To(Equal(1))
+
+		_, _ = predictor.Predict(scaleMetric, fetchContext, now.Add(step))
+		Expect(fetcher.calls).To(Equal(2), "history should be fetched again once a new step is available")
+	})
+
+	It("Align fills gaps", func() {
+		start := now.Truncate(step)
+		samples := []metrics.Sample{
+			{Timestamp: start.Add(step), Value: 1},
+			{Timestamp: start.Add(2 * step), Value: 2},
+			{Timestamp: start.Add(4 * step), Value: 4},
+		}
+
+		values, err := Align(samples, start, step, 5)
+
+		Expect(err).To(BeNil())
+		Expect(values).To(Equal([]float64{1, 1, 2, 2, 4}))
+	})
+
+	It("Align with too many gaps", func() {
+		start := now.Truncate(step)
+		samples := []metrics.Sample{{Timestamp: start, Value: 1}}
+
+		_, err := Align(samples, start, step, 5)
+
+		Expect(err).NotTo(BeNil())
+	})
+})
@@ -4,6 +4,7 @@ go 1.16
 
 require (
 	github.com/Masterminds/semver v1.5.0 // indirect
+	github.com/Shopify/sarama v1.29.0
 	github.com/docker/docker v20.10.7+incompatible
 	github.com/fatih/color v1.12.0 // indirect
 	github.com/go-logr/logr v0.4.0
@@ -17,6 +18,7 @@ require (
 	github.com/onsi/gomega v1.13.0
 	github.com/prometheus/client_golang v1.11.0
 	github.com/prometheus/common v0.26.0
+	github.com/xdg/scram v1.0.3
 	go.uber.org/zap v1.18.1
 	golang.org/x/sys v0.0.0-20210630005230-0f9fa26af87c // indirect
 	golang.org/x/tools v0.1.3 // indirect
@@ -27,6 +29,7 @@ require (
 	k8s.io/client-go v0.21.3
 	k8s.io/klog v1.0.0 // indirect
 	k8s.io/metrics v0.21.0
+	k8s.io/utils v0.0.0-20210527160623-6fdb442a123b
 	rsc.io/letsencrypt v0.0.3 // indirect
 	sigs.k8s.io/controller-runtime v0.9.2
 	sigs.k8s.io/structured-merge-diff v1.0.1-0.20191108220359-b1b620dd3f06 // indirect
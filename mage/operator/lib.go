@@ -38,6 +38,14 @@ func Build() {
 	fmt.Println("- Build")
 	err := sh.RunV("go", "build", "-o", "bin/manager", "main.go")
 	utils.PanicOnError(err)
+	mg.SerialDeps(BuildPlugin)
+}
+
+// Builds the kubectl plugin, kubectl runs it as 'kubectl kratos' once bin/kubectl-kratos is on the PATH
+func BuildPlugin() {
+	fmt.Println("- Build kubectl plugin")
+	err := sh.RunV("go", "build", "-o", "bin/kubectl-kratos", "./cmd/kubectl-kratos")
+	utils.PanicOnError(err)
 }
 
 // Runs operator agains configured kube config
@@ -83,6 +91,14 @@ func GenerateCrds() {
 	utils.PanicOnError(err)
 }
 
+// Generates webhook configurations
+func GenerateWebhooks() {
+	mg.Deps(installControllerGen)
+	fmt.Println("- Generating webhook configurations")
+	err := sh.RunV("controller-gen", "webhook", "paths=\"./...\"", "output:webhook:artifacts:config=config/webhook")
+	utils.PanicOnError(err)
+}
+
 // Run operator tests
 func Test() {
 	mg.Deps(Build)
@@ -16,14 +16,18 @@ package main
 import (
 	"errors"
 	"flag"
+	"fmt"
 	"os"
 	"strconv"
 	"strings"
 
 	"github.com/adobe/kratos/api/common"
 	"github.com/adobe/kratos/controllers"
+	"github.com/adobe/kratos/simulate"
+	"github.com/adobe/kratos/webhooks"
 	"go.uber.org/zap/zapcore"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/azure"
@@ -63,6 +67,20 @@ func (i *int32Value) Get() interface{} { return int32(*i) }
 
 func (i *int32Value) String() string { return strconv.FormatInt(int64(*i), 10) }
 
+// parseNamespacedName parses a name in the form namespace/name, an empty value is the empty name
+func parseNamespacedName(value string) (types.NamespacedName, error) {
+	if value == "" {
+		return types.NamespacedName{}, nil
+	}
+
+	parts := strings.Split(value, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return types.NamespacedName{}, fmt.Errorf("'%s' is not in the form namespace/name", value)
+	}
+
+	return types.NamespacedName{Namespace: parts[0], Name: parts[1]}, nil
+}
+
 func numError(err error) error {
 	ne, ok := err.(*strconv.NumError)
 	if !ok {
@@ -87,11 +105,23 @@ func init() {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "simulate" {
+		if err := simulate.Run(os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	var metricsAddr string
 	var enableLeaderElection bool
 	var namespacesList string
 	var defaultPrometheusUrl string
+	var defaultPrometheusAuthSecret string
 	var defaultStabilizationWindowSeconds int32
+	var enableWebhooks bool
+	var dryRun bool
+	var decisionHistory int32
 
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
@@ -99,10 +129,28 @@ func main() {
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.StringVar(&namespacesList, "namespaces", "", "Comma separated list of namespaces")
 	flag.StringVar(&defaultPrometheusUrl, "default-prometheus-url", "https://prometheus-monitoring-va7.int.pipeline.adobedc.net", "Default Prometheus url")
+	flag.StringVar(&defaultPrometheusAuthSecret, "default-prometheus-auth-secret", "",
+		"Secret with the credentials and TLS settings of the default Prometheus url, in the form namespace/name")
 	flag.Var(newInt32Value(300, &defaultStabilizationWindowSeconds), "stabilization-window-seconds", "Stabilization window in seconds")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"Enable the defaulting and validating webhooks. "+
+			"Requires a serving certificate in the webhook server cert dir.")
+	flag.BoolVar(&dryRun, "dry-run", false,
+		"Calculate and report replicas for all autoscalers without scaling their targets, "+
+			"regardless of the mode in their spec.")
+	flag.Var(newInt32Value(5, &decisionHistory), "decision-history", "Number of scaling decisions kept in the status of every autoscaler, 0 keeps none")
+
+	logOptions := zap.Options{Development: true, Level: zapcore.DebugLevel}
+	logOptions.BindFlags(flag.CommandLine)
 	flag.Parse()
 
-	ctrl.SetLogger(zap.New(zap.UseDevMode(true), zap.Level(zapcore.DebugLevel)))
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&logOptions)))
+
+	defaultPrometheusAuthSecretName, err := parseNamespacedName(defaultPrometheusAuthSecret)
+	if err != nil {
+		setupLog.Error(err, "invalid default prometheus auth secret")
+		os.Exit(1)
+	}
 
 	namespaces := strings.Split(namespacesList, ",")
 	setupLog.Info("Listening for namespaces", "namespaces", namespaces)
@@ -121,13 +169,18 @@ func main() {
 		os.Exit(1)
 	}
 
+	// the auth Secrets of metric sources are read with the API reader, they aren't cached and may be outside the watched namespaces
 	params := &common.KratosParameters{
-		ClientConfig:               mgr.GetConfig(),
-		Client:                     mgr.GetClient(),
-		RestMapper:                 mgr.GetRESTMapper(),
-		EventRecorder:              mgr.GetEventRecorderFor("kratos"),
-		DefaultPrometheusUrl:       defaultPrometheusUrl,
-		StabilizationWindowSeconds: defaultStabilizationWindowSeconds,
+		ClientConfig:                mgr.GetConfig(),
+		Client:                      mgr.GetClient(),
+		APIReader:                   mgr.GetAPIReader(),
+		RestMapper:                  mgr.GetRESTMapper(),
+		EventRecorder:               mgr.GetEventRecorderFor("kratos"),
+		DefaultPrometheusUrl:        defaultPrometheusUrl,
+		DefaultPrometheusAuthSecret: defaultPrometheusAuthSecretName,
+		StabilizationWindowSeconds:  defaultStabilizationWindowSeconds,
+		DryRun:                      dryRun,
+		DecisionHistory:             decisionHistory,
 	}
 
 	reconciler, err := controllers.NewKratosReconciler(params)
@@ -142,6 +195,10 @@ func main() {
 		os.Exit(1)
 	}
 
+	if enableWebhooks {
+		webhooks.SetupWithManager(mgr, params)
+	}
+
 	// +kubebuilder:scaffold:builder
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
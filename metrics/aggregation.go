@@ -0,0 +1,93 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+)
+
+// Reduce aggregates the samples of a series in a window to a single value, samples don't need to be ordered.
+// The half-life is only used by EWMA. An error is returned when there are no samples.
+func Reduce(samples []Sample, aggregation v1alpha1.Aggregation, halfLife time.Duration) (MetricValue, error) {
+	if len(samples) == 0 {
+		return MetricValue{}, errors.New("no samples to aggregate")
+	}
+
+	ordered := make([]Sample, len(samples))
+	copy(ordered, samples)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Timestamp.Before(ordered[j].Timestamp)
+	})
+
+	switch aggregation {
+	case v1alpha1.AvgAggregation, "":
+		sum := float64(0)
+		for _, sample := range ordered {
+			sum += sample.Value
+		}
+		return NewMetricValue(sum / float64(len(ordered)))
+	case v1alpha1.MaxAggregation:
+		max := ordered[0].Value
+		for _, sample := range ordered[1:] {
+			max = math.Max(max, sample.Value)
+		}
+		return NewMetricValue(max)
+	case v1alpha1.P95Aggregation:
+		return NewMetricValue(percentile(ordered, 0.95))
+	case v1alpha1.LastAggregation:
+		return NewMetricValue(ordered[len(ordered)-1].Value)
+	case v1alpha1.EWMAAggregation:
+		if halfLife <= 0 {
+			return MetricValue{}, fmt.Errorf("half-life must be greater than 0, got %s", halfLife)
+		}
+		return NewMetricValue(ewma(ordered, halfLife))
+	default:
+		return MetricValue{}, fmt.Errorf("unknown aggregation: %s", aggregation)
+	}
+}
+
+// percentile interpolates linearly between the closest ranks of the values
+func percentile(samples []Sample, quantile float64) float64 {
+	values := make([]float64, len(samples))
+	for i, sample := range samples {
+		values[i] = sample.Value
+	}
+	sort.Float64s(values)
+
+	rank := quantile * float64(len(values)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+
+	return values[lower] + (rank-float64(lower))*(values[upper]-values[lower])
+}
+
+// ewma weighs the ordered samples by their age, the weight of the running average decays with the time between samples
+// so that gaps in the series don't skew the result
+func ewma(samples []Sample, halfLife time.Duration) float64 {
+	average := samples[0].Value
+
+	for i := 1; i < len(samples); i++ {
+		elapsed := samples[i].Timestamp.Sub(samples[i-1].Timestamp)
+		alpha := 1 - math.Exp2(-float64(elapsed)/float64(halfLife))
+		average += alpha * (samples[i].Value - average)
+	}
+
+	return average
+}
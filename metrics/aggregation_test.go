@@ -0,0 +1,69 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package metrics
+
+import (
+	"time"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Aggregation", func() {
+	start := time.Date(2021, 7, 5, 10, 0, 0, 0, time.UTC)
+
+	// values of 20 steps, out of order to check that the samples are sorted
+	samples := make([]Sample, 0, 20)
+	for i := 19; i >= 0; i-- {
+		samples = append(samples, Sample{Timestamp: start.Add(time.Duration(i) * time.Minute), Value: float64(i + 1)})
+	}
+
+	DescribeTable("Reduce",
+		func(aggregation v1alpha1.Aggregation, halfLife time.Duration, expected float64) {
+			value, err := Reduce(samples, aggregation, halfLife)
+
+			Expect(err).To(BeNil())
+			Expect(value.Value).To(BeNumerically("~", expected, 0.001))
+		},
+		Entry("avg", v1alpha1.AvgAggregation, time.Duration(0), 10.5),
+		Entry("max", v1alpha1.MaxAggregation, time.Duration(0), float64(20)),
+		Entry("p95 interpolated", v1alpha1.P95Aggregation, time.Duration(0), 19.05),
+		Entry("last", v1alpha1.LastAggregation, time.Duration(0), float64(20)),
+		// the weight of the running average halves every step, the result approaches the last value minus one
+		Entry("ewma", v1alpha1.EWMAAggregation, time.Minute, float64(19)),
+	)
+
+	It("EWMA weighs gaps by time", func() {
+		gap := []Sample{{Timestamp: start, Value: 0}, {Timestamp: start.Add(2 * time.Minute), Value: 100}}
+
+		value, err := Reduce(gap, v1alpha1.EWMAAggregation, time.Minute)
+
+		Expect(err).To(BeNil())
+		Expect(value.Value).To(BeNumerically("~", 75, 0.001), "two half-lives should keep a quarter of the previous average")
+	})
+
+	It("No samples", func() {
+		_, err := Reduce(nil, v1alpha1.AvgAggregation, 0)
+
+		Expect(err).To(MatchError("no samples to aggregate"))
+	})
+
+	It("EWMA without half-life", func() {
+		_, err := Reduce(samples, v1alpha1.EWMAAggregation, 0)
+
+		Expect(err).NotTo(BeNil())
+	})
+})
@@ -0,0 +1,69 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package metrics
+
+import (
+	"errors"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/go-logr/logr"
+	externalclient "k8s.io/metrics/pkg/client/external_metrics"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+type externalMetricsFetcher struct {
+	externalMetricsClient externalclient.ExternalMetricsClient
+	log                   logr.Logger
+}
+
+func newExternalMetricsFetcher(externalMetricsClient externalclient.ExternalMetricsClient) *externalMetricsFetcher {
+	fetcher := &externalMetricsFetcher{
+		externalMetricsClient: externalMetricsClient,
+		log:                   log.Log.WithName("externalMetrics-fetcher"),
+	}
+
+	return fetcher
+}
+
+// Fetch returns every series of the external metric matching the metric selector.
+// Values are summed by the replica calculator, Value targets compare the total and AverageValue targets the total per replica.
+func (e *externalMetricsFetcher) Fetch(scaleMetric *v1alpha1.ScaleMetric, fetchContext *FetchContext) ([]MetricValue, error) {
+	if scaleMetric.External == nil {
+		return nil, errors.New("external metric source is not defined")
+	}
+
+	metricSelector, err := toMetricSelector(scaleMetric.External.Metric.Selector)
+	if err != nil {
+		return nil, err
+	}
+
+	metricValueList, err := e.externalMetricsClient.NamespacedMetrics(fetchContext.Namespace).List(scaleMetric.External.Metric.Name, metricSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	e.log.V(1).Info("Fetched external metrics", "selector", metricSelector.String(), "metric", scaleMetric.External.Metric.Name, "metrics", metricValueList.Items)
+
+	if len(metricValueList.Items) == 0 {
+		return nil, errors.New("no values returned by external metrics API")
+	}
+
+	ret := make([]MetricValue, 0, len(metricValueList.Items))
+
+	for _, metricValue := range metricValueList.Items {
+		ret = append(ret, MetricValue{QuantityValue(&metricValue.Value)})
+	}
+
+	return ret, nil
+}
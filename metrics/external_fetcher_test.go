@@ -0,0 +1,106 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package metrics
+
+import (
+	"time"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/testing"
+	emapi "k8s.io/metrics/pkg/apis/external_metrics/v1beta1"
+	emfake "k8s.io/metrics/pkg/client/external_metrics/fake"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExternalFetcher", func() {
+	var fakeExternalMetricsClient *emfake.FakeExternalMetricsClient
+	var lastAction testing.ListAction
+
+	BeforeEach(func() {
+		fakeExternalMetricsClient = &emfake.FakeExternalMetricsClient{}
+		fakeExternalMetricsClient.AddReactor("list", "*", func(action testing.Action) (handled bool, ret runtime.Object, err error) {
+			lastAction = action.(testing.ListAction)
+
+			metrics := &emapi.ExternalMetricValueList{}
+
+			if lastAction.GetResource().Resource != "queue_messages_ready" {
+				return true, metrics, nil
+			}
+
+			for _, value := range []int64{30, 50} {
+				metrics.Items = append(metrics.Items, emapi.ExternalMetricValue{
+					MetricName: "queue_messages_ready",
+					Timestamp:  metav1.Time{Time: time.Now()},
+					Value:      *resource.NewQuantity(value, resource.DecimalSI),
+				})
+			}
+
+			return true, metrics, nil
+		})
+	})
+
+	It("Undefined external metric source", func() {
+		fetcher := newExternalMetricsFetcher(fakeExternalMetricsClient)
+
+		scaleMetric := &v1alpha1.ScaleMetric{
+			Type: v1alpha1.ExternalScaleMetricType,
+		}
+		_, err := fetcher.Fetch(scaleMetric, &FetchContext{Namespace: namespace, Selector: labels.Everything()})
+
+		Expect(err).NotTo(BeNil(), "missing external source should result in error")
+	})
+
+	It("No external metric values", func() {
+		fetcher := newExternalMetricsFetcher(fakeExternalMetricsClient)
+
+		scaleMetric := &v1alpha1.ScaleMetric{
+			Type: v1alpha1.ExternalScaleMetricType,
+			External: &v1alpha1.ExternalMetricSource{
+				Metric: v1alpha1.MetricIdentifier{Name: "nonexistent"},
+			},
+		}
+		_, err := fetcher.Fetch(scaleMetric, &FetchContext{Namespace: namespace, Selector: labels.Everything()})
+
+		Expect(err).NotTo(BeNil(), "empty external metric should result in error")
+	})
+
+	It("External metric values with selector", func() {
+		fetcher := newExternalMetricsFetcher(fakeExternalMetricsClient)
+
+		scaleMetric := &v1alpha1.ScaleMetric{
+			Type: v1alpha1.ExternalScaleMetricType,
+			External: &v1alpha1.ExternalMetricSource{
+				Metric: v1alpha1.MetricIdentifier{
+					Name: "queue_messages_ready",
+					Selector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"queue": "orders"},
+					},
+				},
+			},
+		}
+		res, err := fetcher.Fetch(scaleMetric, &FetchContext{Namespace: namespace, Selector: labels.Everything()})
+
+		Expect(err).To(BeNil(), "no errors expected for valid arguments")
+		Expect(res).To(Equal([]MetricValue{{30}, {50}}))
+		Expect(lastAction.GetNamespace()).To(Equal(namespace))
+		Expect(lastAction.GetListRestrictions().Labels.String()).To(Equal("queue=orders"), "metric selector should be passed to the external metrics API")
+	})
+})
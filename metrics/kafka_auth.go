@@ -0,0 +1,168 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package metrics
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Shopify/sarama"
+	"github.com/xdg/scram"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// keys of the Secret with the SASL settings of Kafka brokers, besides the shared TLS keys
+const (
+	kafkaMechanismKey = "sasl.mechanism"
+	kafkaUsernameKey  = "username"
+	kafkaPasswordKey  = "password"
+	kafkaTLSKey       = "tls"
+)
+
+// kafkaAuth is the content of a Kafka auth Secret
+type kafkaAuth struct {
+	mechanism          sarama.SASLMechanism
+	username           string
+	password           string
+	tls                bool
+	ca                 []byte
+	cert               []byte
+	key                []byte
+	insecureSkipVerify bool
+}
+
+// newKafkaAuth reads and checks the keys of a Kafka auth Secret, unknown keys are rejected to catch typos.
+// TLS is enabled by the tls key or any of the certificate keys, SASL defaults to PLAIN when a username is set.
+func newKafkaAuth(secret *corev1.Secret) (*kafkaAuth, error) {
+	auth := &kafkaAuth{}
+
+	for key, value := range secret.Data {
+		switch key {
+		case kafkaMechanismKey:
+			auth.mechanism = sarama.SASLMechanism(strings.TrimSpace(string(value)))
+		case kafkaUsernameKey:
+			auth.username = string(value)
+		case kafkaPasswordKey:
+			auth.password = string(value)
+		case kafkaTLSKey:
+			enabled, err := strconv.ParseBool(strings.TrimSpace(string(value)))
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %v", kafkaTLSKey, err)
+			}
+			auth.tls = enabled
+		case caKey:
+			auth.ca = value
+		case certKey:
+			auth.cert = value
+		case keyKey:
+			auth.key = value
+		case insecureSkipVerifyKey:
+			insecureSkipVerify, err := strconv.ParseBool(strings.TrimSpace(string(value)))
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %v", insecureSkipVerifyKey, err)
+			}
+			auth.insecureSkipVerify = insecureSkipVerify
+		default:
+			return nil, fmt.Errorf("unknown key '%s'", key)
+		}
+	}
+
+	if auth.mechanism == "" && auth.username != "" {
+		auth.mechanism = sarama.SASLTypePlaintext
+	}
+
+	switch auth.mechanism {
+	case "":
+		if auth.password != "" {
+			return nil, fmt.Errorf("%s requires %s", kafkaPasswordKey, kafkaUsernameKey)
+		}
+	case sarama.SASLTypePlaintext, sarama.SASLTypeSCRAMSHA256, sarama.SASLTypeSCRAMSHA512:
+		if auth.username == "" {
+			return nil, fmt.Errorf("%s %s requires %s", kafkaMechanismKey, auth.mechanism, kafkaUsernameKey)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported %s '%s', supported: %s, %s, %s", kafkaMechanismKey, auth.mechanism,
+			sarama.SASLTypePlaintext, sarama.SASLTypeSCRAMSHA256, sarama.SASLTypeSCRAMSHA512)
+	}
+
+	if (len(auth.cert) == 0) != (len(auth.key) == 0) {
+		return nil, fmt.Errorf("%s and %s must be set together", certKey, keyKey)
+	}
+
+	if len(auth.ca) > 0 || len(auth.cert) > 0 || auth.insecureSkipVerify {
+		auth.tls = true
+	}
+
+	return auth, nil
+}
+
+// configure sets the SASL and TLS settings of the Secret on the config of a Kafka client
+func (a *kafkaAuth) configure(config *sarama.Config) error {
+	if a.tls {
+		tlsConfig, err := newTLSConfig(a.ca, a.cert, a.key, a.insecureSkipVerify)
+		if err != nil {
+			return err
+		}
+		config.Net.TLS.Enable = true
+		config.Net.TLS.Config = tlsConfig
+	}
+
+	if a.mechanism == "" {
+		return nil
+	}
+
+	config.Net.SASL.Enable = true
+	config.Net.SASL.Mechanism = a.mechanism
+	config.Net.SASL.User = a.username
+	config.Net.SASL.Password = a.password
+
+	switch a.mechanism {
+	case sarama.SASLTypeSCRAMSHA256:
+		config.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+			return &scramClient{hashGenerator: scram.HashGeneratorFcn(sha256.New)}
+		}
+	case sarama.SASLTypeSCRAMSHA512:
+		config.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+			return &scramClient{hashGenerator: scram.HashGeneratorFcn(sha512.New)}
+		}
+	}
+
+	return nil
+}
+
+// scramClient runs the SCRAM exchange of the SASL authentication with the brokers
+type scramClient struct {
+	hashGenerator scram.HashGeneratorFcn
+	conversation  *scram.ClientConversation
+}
+
+func (c *scramClient) Begin(userName string, password string, authzID string) error {
+	client, err := c.hashGenerator.NewClient(userName, password, authzID)
+	if err != nil {
+		return err
+	}
+	c.conversation = client.NewConversation()
+	return nil
+}
+
+func (c *scramClient) Step(challenge string) (string, error) {
+	return c.conversation.Step(challenge)
+}
+
+func (c *scramClient) Done() bool {
+	return c.conversation.Done()
+}
@@ -0,0 +1,259 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Shopify/sarama"
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/adobe/kratos/cache"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const kafkaClientID = "kratos"
+
+type kafkaMetricsFetcher struct {
+	secretReader      client.Reader
+	kafkaClientsCache *cache.TTLCache
+	log               logr.Logger
+}
+
+// kafkaClient is a client of a Kafka cluster along with the admin client sharing its connections
+type kafkaClient struct {
+	sarama.Client
+	admin sarama.ClusterAdmin
+}
+
+// Close closes the admin client, which closes the connections of the client
+func (c *kafkaClient) Close() error {
+	return c.admin.Close()
+}
+
+// newKafkaMetricsFetcher creates the fetcher of the lag of Kafka consumer groups, auth secrets are read with the secret reader
+func newKafkaMetricsFetcher(secretReader client.Reader, clock clock.Clock) *kafkaMetricsFetcher {
+	return &kafkaMetricsFetcher{
+		secretReader:      secretReader,
+		kafkaClientsCache: cache.NewTTLCache("kafka-clients", defaultCacheTtl, clock),
+		log:               log.Log.WithName("kafka-fetcher"),
+	}
+}
+
+// Fetch returns the lag of the consumer group on the topics of the source as a single value, either the sum of the lag
+// of all partitions or the highest lag of a partition. Partitions without a committed offset lag from the oldest offset.
+func (k *kafkaMetricsFetcher) Fetch(scaleMetric *v1alpha1.ScaleMetric, fetchContext *FetchContext) ([]MetricValue, error) {
+	source := scaleMetric.Kafka
+
+	kafkaClient, err := k.getOrCreateClient(source, fetchContext.Namespace)
+
+	if err != nil {
+		return nil, err
+	}
+
+	partitions, err := k.partitions(kafkaClient, source.Topics)
+
+	if err != nil {
+		return nil, err
+	}
+
+	k.log.V(1).Info("fetching consumer group offsets", "brokers", source.Brokers, "group", source.ConsumerGroup, "topics", source.Topics)
+
+	offsets, err := kafkaClient.admin.ListConsumerGroupOffsets(source.ConsumerGroup, partitions)
+
+	if err != nil {
+		return nil, fmt.Errorf("can't fetch offsets of consumer group %s: %v", source.ConsumerGroup, err)
+	}
+
+	if offsets.Err != sarama.ErrNoError {
+		return nil, fmt.Errorf("can't fetch offsets of consumer group %s: %v", source.ConsumerGroup, offsets.Err)
+	}
+
+	total := int64(0)
+	max := int64(0)
+
+	for topic, topicPartitions := range partitions {
+		for _, partition := range topicPartitions {
+			lag, err := k.partitionLag(kafkaClient, offsets, topic, partition)
+
+			if err != nil {
+				return nil, err
+			}
+
+			total += lag
+			if lag > max {
+				max = lag
+			}
+		}
+	}
+
+	if source.Lag == v1alpha1.PartitionKafkaLag {
+		return []MetricValue{{Value: float64(max)}}, nil
+	}
+
+	return []MetricValue{{Value: float64(total)}}, nil
+}
+
+// MaxReplicas returns the number of partitions of the topics, consumers beyond that don't get a partition assigned
+func (k *kafkaMetricsFetcher) MaxReplicas(scaleMetric *v1alpha1.ScaleMetric, fetchContext *FetchContext) (int32, error) {
+	kafkaClient, err := k.getOrCreateClient(scaleMetric.Kafka, fetchContext.Namespace)
+
+	if err != nil {
+		return 0, err
+	}
+
+	partitions, err := k.partitions(kafkaClient, scaleMetric.Kafka.Topics)
+
+	if err != nil {
+		return 0, err
+	}
+
+	count := int32(0)
+	for _, topicPartitions := range partitions {
+		count += int32(len(topicPartitions))
+	}
+
+	return count, nil
+}
+
+// partitions returns the partitions of the topics, an unknown topic is an error
+func (k *kafkaMetricsFetcher) partitions(kafkaClient *kafkaClient, topics []string) (map[string][]int32, error) {
+	result := make(map[string][]int32, len(topics))
+
+	for _, topic := range topics {
+		partitions, err := kafkaClient.Partitions(topic)
+
+		if err != nil {
+			return nil, fmt.Errorf("can't fetch partitions of topic %s: %v", topic, err)
+		}
+
+		result[topic] = partitions
+	}
+
+	return result, nil
+}
+
+// partitionLag returns the number of messages of a partition after the committed offset of the group, never negative
+// as the latest offset may be fetched before an offset committed right after it
+func (k *kafkaMetricsFetcher) partitionLag(kafkaClient *kafkaClient, offsets *sarama.OffsetFetchResponse, topic string, partition int32) (int64, error) {
+	block := offsets.GetBlock(topic, partition)
+
+	if block == nil {
+		return 0, fmt.Errorf("no committed offset of topic %s partition %d in response", topic, partition)
+	}
+
+	if block.Err != sarama.ErrNoError {
+		return 0, fmt.Errorf("can't fetch committed offset of topic %s partition %d: %v", topic, partition, block.Err)
+	}
+
+	latest, err := kafkaClient.GetOffset(topic, partition, sarama.OffsetNewest)
+
+	if err != nil {
+		return 0, fmt.Errorf("can't fetch latest offset of topic %s partition %d: %v", topic, partition, err)
+	}
+
+	committed := block.Offset
+
+	if committed < 0 {
+		committed, err = kafkaClient.GetOffset(topic, partition, sarama.OffsetOldest)
+
+		if err != nil {
+			return 0, fmt.Errorf("can't fetch oldest offset of topic %s partition %d: %v", topic, partition, err)
+		}
+	}
+
+	if latest < committed {
+		return 0, nil
+	}
+
+	return latest - committed, nil
+}
+
+// getOrCreateClient returns the client of the brokers and auth secret of the source. Clients are cached by the digest of
+// the secret so that a changed secret is picked up on the next fetch.
+func (k *kafkaMetricsFetcher) getOrCreateClient(source *v1alpha1.KafkaMetricSource, namespace string) (*kafkaClient, error) {
+	key := strings.Join(source.Brokers, ",")
+	var secret *corev1.Secret
+
+	if source.AuthSecretName != "" {
+		secretName := types.NamespacedName{Namespace: namespace, Name: source.AuthSecretName}
+
+		var err error
+		secret, err = getSecret(k.secretReader, secretName, "kafka auth")
+
+		if err != nil {
+			return nil, err
+		}
+
+		key = fmt.Sprintf("%s|%s|%s", key, secretName, secretDigest(secret))
+	}
+
+	cachedClient, found := k.kafkaClientsCache.Get(key)
+
+	if !found {
+		kafkaClient, err := k.createClient(source.Brokers, secret)
+
+		if err != nil {
+			return nil, err
+		}
+
+		k.kafkaClientsCache.Put(key, kafkaClient)
+		cachedClient = kafkaClient
+	}
+
+	return cachedClient.(*kafkaClient), nil
+}
+
+func (k *kafkaMetricsFetcher) createClient(brokers []string, secret *corev1.Secret) (*kafkaClient, error) {
+	config := sarama.NewConfig()
+	config.ClientID = kafkaClientID
+	// offsets are listed with the protocol versions of Kafka 2.0 and later
+	config.Version = sarama.V2_0_0_0
+	config.Net.DialTimeout = defaultCallTimeout
+	config.Net.ReadTimeout = defaultCallTimeout
+	config.Net.WriteTimeout = defaultCallTimeout
+
+	if secret != nil {
+		auth, err := newKafkaAuth(secret)
+		if err != nil {
+			return nil, fmt.Errorf("invalid kafka auth secret %s/%s: %v", secret.Namespace, secret.Name, err)
+		}
+
+		if err = auth.configure(config); err != nil {
+			return nil, fmt.Errorf("invalid kafka auth secret %s/%s: %v", secret.Namespace, secret.Name, err)
+		}
+	}
+
+	saramaClient, err := sarama.NewClient(brokers, config)
+
+	if err != nil {
+		k.log.Error(err, "can't create client for kafka", "brokers", brokers)
+		return nil, err
+	}
+
+	admin, err := sarama.NewClusterAdminFromClient(saramaClient)
+
+	if err != nil {
+		k.log.Error(err, "can't create admin client for kafka", "brokers", brokers)
+		_ = saramaClient.Close()
+		return nil, err
+	}
+
+	return &kafkaClient{Client: saramaClient, admin: admin}, nil
+}
@@ -0,0 +1,252 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/adobe/kratos/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	testingclock "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("KafkaFetcher", func() {
+	const group = "processors"
+
+	var broker *sarama.MockBroker
+	var metadata *sarama.MockMetadataResponse
+	var offsets *sarama.MockOffsetResponse
+	var committed *sarama.MockOffsetFetchResponse
+	var fetcher *kafkaMetricsFetcher
+	fakeClock := testingclock.NewFakeClock(time.Date(2021, 7, 5, 10, 0, 0, 0, time.UTC))
+
+	// setPartition sets the oldest, latest and committed offset of a partition, a committed offset of -1 means none
+	setPartition := func(topic string, partition int32, oldest int64, latest int64, committedOffset int64) {
+		metadata.SetLeader(topic, partition, broker.BrokerID())
+		offsets.SetOffset(topic, partition, sarama.OffsetOldest, oldest)
+		offsets.SetOffset(topic, partition, sarama.OffsetNewest, latest)
+		committed.SetOffset(group, topic, partition, committedOffset, "", sarama.ErrNoError)
+	}
+
+	newScaleMetric := func(lag v1alpha1.KafkaLagMode, topics ...string) *v1alpha1.ScaleMetric {
+		return &v1alpha1.ScaleMetric{
+			Type: v1alpha1.KafkaScaleMetricType,
+			Kafka: &v1alpha1.KafkaMetricSource{
+				Brokers:       []string{broker.Addr()},
+				ConsumerGroup: group,
+				Topics:        topics,
+				Lag:           lag,
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		broker = sarama.NewMockBroker(GinkgoT(), 1)
+		metadata = sarama.NewMockMetadataResponse(GinkgoT()).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetController(broker.BrokerID())
+		offsets = sarama.NewMockOffsetResponse(GinkgoT()).SetVersion(1)
+		committed = sarama.NewMockOffsetFetchResponse(GinkgoT())
+
+		broker.SetHandlerByMap(map[string]sarama.MockResponse{
+			"MetadataRequest":        metadata,
+			"OffsetRequest":          offsets,
+			"FindCoordinatorRequest": sarama.NewMockFindCoordinatorResponse(GinkgoT()).SetCoordinator(sarama.CoordinatorGroup, group, broker),
+			"OffsetFetchRequest":     committed,
+		})
+
+		fetcher = newKafkaMetricsFetcher(nil, fakeClock)
+	})
+
+	AfterEach(func() {
+		broker.Close()
+	})
+
+	It("Total lag of topics", func() {
+		setPartition("orders", 0, 0, 100, 40)
+		setPartition("orders", 1, 0, 80, 80)
+		setPartition("payments", 0, 0, 30, 20)
+
+		values, err := fetcher.Fetch(newScaleMetric(v1alpha1.TotalKafkaLag, "orders", "payments"), &FetchContext{Namespace: "team"})
+
+		Expect(err).To(BeNil())
+		Expect(values).To(Equal([]MetricValue{{Value: 70}}), "lag of all partitions should be summed")
+	})
+
+	It("Highest lag of a partition", func() {
+		setPartition("orders", 0, 0, 100, 40)
+		setPartition("orders", 1, 0, 80, 70)
+
+		values, err := fetcher.Fetch(newScaleMetric(v1alpha1.PartitionKafkaLag, "orders"), &FetchContext{Namespace: "team"})
+
+		Expect(err).To(BeNil())
+		Expect(values).To(Equal([]MetricValue{{Value: 60}}), "highest lag of a single partition should be returned")
+	})
+
+	It("Lag from the oldest offset without committed offset", func() {
+		setPartition("orders", 0, 25, 100, -1)
+
+		values, err := fetcher.Fetch(newScaleMetric("", "orders"), &FetchContext{Namespace: "team"})
+
+		Expect(err).To(BeNil())
+		Expect(values).To(Equal([]MetricValue{{Value: 75}}), "partition without commit should lag from the oldest offset")
+	})
+
+	It("Committed offset ahead of latest offset", func() {
+		setPartition("orders", 0, 0, 100, 110)
+
+		values, err := fetcher.Fetch(newScaleMetric("", "orders"), &FetchContext{Namespace: "team"})
+
+		Expect(err).To(BeNil())
+		Expect(values).To(Equal([]MetricValue{{Value: 0}}), "lag should never be negative")
+	})
+
+	It("Partition count limits replicas", func() {
+		setPartition("orders", 0, 0, 100, 40)
+		setPartition("orders", 1, 0, 80, 80)
+		setPartition("payments", 0, 0, 30, 20)
+
+		var limiter ReplicaLimiter = fetcher
+		limit, err := limiter.MaxReplicas(newScaleMetric("", "orders", "payments"), &FetchContext{Namespace: "team"})
+
+		Expect(err).To(BeNil())
+		Expect(limit).To(Equal(int32(3)), "replicas should be limited to the partitions of all topics")
+	})
+
+	It("Unknown topic", func() {
+		setPartition("orders", 0, 0, 100, 40)
+
+		_, err := fetcher.Fetch(newScaleMetric("", "missing"), &FetchContext{Namespace: "team"})
+
+		Expect(err).To(MatchError(ContainSubstring("can't fetch partitions of topic missing")))
+	})
+
+	It("Error on committed offset", func() {
+		setPartition("orders", 0, 0, 100, 40)
+		committed.SetOffset(group, "orders", 0, 0, "", sarama.ErrUnknownTopicOrPartition)
+
+		_, err := fetcher.Fetch(newScaleMetric("", "orders"), &FetchContext{Namespace: "team"})
+
+		Expect(err).To(MatchError(ContainSubstring("can't fetch committed offset of topic orders partition 0")))
+	})
+
+	It("Missing secret", func() {
+		secretReader := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+		scaleMetric := newScaleMetric("", "orders")
+		scaleMetric.Kafka.AuthSecretName = "missing"
+
+		_, err := newKafkaMetricsFetcher(secretReader, fakeClock).Fetch(scaleMetric, &FetchContext{Namespace: "team"})
+
+		Expect(err).To(MatchError(ContainSubstring("can't read kafka auth secret team/missing")), "missing secret should fail the fetch")
+	})
+
+	It("Client replaced when the secret changes", func() {
+		setPartition("orders", 0, 0, 100, 40)
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team", Name: "kafka"},
+			Data:       map[string][]byte{"tls": []byte("false")},
+		}
+		secretReader := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secret).Build()
+		fetcher := newKafkaMetricsFetcher(secretReader, fakeClock)
+		scaleMetric := newScaleMetric("", "orders")
+		scaleMetric.Kafka.AuthSecretName = "kafka"
+
+		first, err := fetcher.getOrCreateClient(scaleMetric.Kafka, "team")
+		Expect(err).To(BeNil())
+
+		secret.Data["sasl.mechanism"] = []byte("SCRAM-SHA-1")
+		secret.Data["username"] = []byte("kratos")
+		Expect(secretReader.Update(context.Background(), secret)).To(Succeed())
+
+		_, err = fetcher.getOrCreateClient(scaleMetric.Kafka, "team")
+		Expect(err).To(MatchError(ContainSubstring("invalid kafka auth secret team/kafka: unsupported sasl.mechanism 'SCRAM-SHA-1'")),
+			"changed secret should be picked up")
+
+		delete(secret.Data, "sasl.mechanism")
+		delete(secret.Data, "username")
+		Expect(secretReader.Update(context.Background(), secret)).To(Succeed())
+
+		second, err := fetcher.getOrCreateClient(scaleMetric.Kafka, "team")
+		Expect(err).To(BeNil())
+		Expect(second).To(BeIdenticalTo(first), "client of the same secret content should be reused")
+	})
+})
+
+var _ = Describe("KafkaAuth", func() {
+	newSecret := func(data map[string]string) *corev1.Secret {
+		secret := &corev1.Secret{Data: map[string][]byte{}}
+		for key, value := range data {
+			secret.Data[key] = []byte(value)
+		}
+		return secret
+	}
+
+	DescribeTable("Invalid secrets",
+		func(data map[string]string, message string) {
+			auth, err := newKafkaAuth(newSecret(data))
+			if err == nil {
+				err = auth.configure(sarama.NewConfig())
+			}
+
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("unknown mechanism", map[string]string{"sasl.mechanism": "GSSAPI", "username": "u"}, "unsupported sasl.mechanism 'GSSAPI'"),
+		Entry("mechanism without username", map[string]string{"sasl.mechanism": "SCRAM-SHA-512"}, "SCRAM-SHA-512 requires username"),
+		Entry("password without username", map[string]string{"password": "p"}, "password requires username"),
+		Entry("certificate without key", map[string]string{"tls.key": "k"}, "must be set together"),
+		Entry("unknown key", map[string]string{"user": "u"}, "unknown key 'user'"),
+		Entry("invalid tls", map[string]string{"tls": "yes please"}, "invalid tls"),
+		Entry("invalid CA", map[string]string{"ca.crt": "not a certificate"}, "no PEM certificate found in ca.crt"),
+	)
+
+	It("PLAIN by default with a username", func() {
+		auth, err := newKafkaAuth(newSecret(map[string]string{"username": "kratos", "password": "secret"}))
+		Expect(err).To(BeNil())
+
+		config := sarama.NewConfig()
+		Expect(auth.configure(config)).To(Succeed())
+
+		Expect(config.Net.SASL.Enable).To(BeTrue())
+		Expect(config.Net.SASL.Mechanism).To(Equal(sarama.SASLMechanism(sarama.SASLTypePlaintext)))
+		Expect(config.Net.SASL.User).To(Equal("kratos"))
+		Expect(config.Net.TLS.Enable).To(BeFalse(), "TLS should only be enabled when configured")
+	})
+
+	It("SCRAM over TLS", func() {
+		auth, err := newKafkaAuth(newSecret(map[string]string{"sasl.mechanism": "SCRAM-SHA-256", "username": "kratos", "password": "secret", "insecureSkipVerify": "true"}))
+		Expect(err).To(BeNil())
+
+		config := sarama.NewConfig()
+		Expect(auth.configure(config)).To(Succeed())
+
+		Expect(config.Net.TLS.Enable).To(BeTrue(), "TLS keys should enable TLS")
+		Expect(config.Net.TLS.Config.InsecureSkipVerify).To(BeTrue())
+		Expect(config.Net.SASL.SCRAMClientGeneratorFunc).NotTo(BeNil())
+
+		scramClient := config.Net.SASL.SCRAMClientGeneratorFunc()
+		Expect(scramClient.Begin("kratos", "secret", "")).To(Succeed())
+		first, err := scramClient.Step("")
+		Expect(err).To(BeNil())
+		Expect(first).To(HavePrefix("n,,n=kratos,r="), "client first message should start the exchange")
+		Expect(scramClient.Done()).To(BeFalse())
+	})
+})
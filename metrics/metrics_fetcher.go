@@ -16,13 +16,18 @@ package metrics
 import (
 	"errors"
 	"fmt"
+	"math"
 	"time"
 
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/labels"
 
 	"github.com/adobe/kratos/api/common"
 	"github.com/adobe/kratos/api/v1alpha1"
+	"k8s.io/client-go/discovery"
 	metrics "k8s.io/metrics/pkg/client/clientset/versioned"
+	customclient "k8s.io/metrics/pkg/client/custom_metrics"
+	externalclient "k8s.io/metrics/pkg/client/external_metrics"
 )
 
 const (
@@ -30,17 +35,66 @@ const (
 	defaultCallTimeout = time.Second * 10
 )
 
+// MetricValue is a single fetched metric sample, quantities are converted with milli precision
 type MetricValue struct {
-	Value int64
+	Value float64
+}
+
+// NewMetricValue creates a metric value, NaN and infinite values are rejected as they can't be compared to a target
+func NewMetricValue(value float64) (MetricValue, error) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return MetricValue{}, fmt.Errorf("invalid metric value: %v", value)
+	}
+
+	return MetricValue{Value: value}, nil
+}
+
+// QuantityValue converts a quantity to float64 keeping milli precision, e.g. 250m cpu is 0.25
+func QuantityValue(quantity *resource.Quantity) float64 {
+	return float64(quantity.MilliValue()) / 1000
+}
+
+// FetchContext describes the autoscaler a metric is fetched for and its scale target
+type FetchContext struct {
+	// namespace of the autoscaler and its scale target
+	Namespace string
+	// selector of the pods of the scale target
+	Selector labels.Selector
+	// scale target of the autoscaler
+	Target v1alpha1.ScaleTargetReference
+	// current replicas of the scale target
+	Replicas int32
 }
 
 type MetricsFetcher interface {
-	Fetch(scaleMetric *v1alpha1.ScaleMetric, namespace string, selector labels.Selector) ([]MetricValue, error)
+	Fetch(scaleMetric *v1alpha1.ScaleMetric, fetchContext *FetchContext) ([]MetricValue, error)
+}
+
+// ReplicaLimiter is implemented by fetchers whose metric source limits the replicas doing useful work,
+// e.g. consumers of Kafka topics beyond the number of partitions are idle
+type ReplicaLimiter interface {
+	MaxReplicas(scaleMetric *v1alpha1.ScaleMetric, fetchContext *FetchContext) (int32, error)
+}
+
+// Sample is a metric value at a point in time
+type Sample struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+// RangeFetcher fetches the history of a metric, the values of all series are summed per timestamp
+type RangeFetcher interface {
+	FetchRange(scaleMetric *v1alpha1.ScaleMetric, fetchContext *FetchContext, start time.Time, end time.Time, step time.Duration) ([]Sample, error)
 }
 
 type MetricsFactory struct {
-	prometheusFetcher MetricsFetcher
+	prometheusFetcher *prometheusMetricsFetcher
 	resourceFetcher   MetricsFetcher
+	podsFetcher       MetricsFetcher
+	objectFetcher     MetricsFetcher
+	externalFetcher   MetricsFetcher
+	kafkaFetcher      MetricsFetcher
+	rabbitMQFetcher   MetricsFetcher
 }
 
 func NewMetricsFactory(params *common.KratosParameters) *MetricsFactory {
@@ -48,9 +102,23 @@ func NewMetricsFactory(params *common.KratosParameters) *MetricsFactory {
 	if err != nil {
 		panic(err.Error())
 	}
+	dc, err := discovery.NewDiscoveryClientForConfig(params.ClientConfig)
+	if err != nil {
+		panic(err.Error())
+	}
+	cmc := customclient.NewForConfig(params.ClientConfig, params.RestMapper, customclient.NewAvailableAPIsGetter(dc))
+	emc, err := externalclient.NewForConfig(params.ClientConfig)
+	if err != nil {
+		panic(err.Error())
+	}
 	return &MetricsFactory{
-		prometheusFetcher: newPrometheusMetricsFetcher(params.DefaultPrometheusUrl),
+		prometheusFetcher: newPrometheusMetricsFetcher(params.DefaultPrometheusUrl, params.DefaultPrometheusAuthSecret, params.APIReader, params.GetClock()),
 		resourceFetcher:   newResourceMetricsFetcher(mc),
+		podsFetcher:       newPodsMetricsFetcher(cmc),
+		objectFetcher:     newObjectMetricsFetcher(cmc, params.RestMapper),
+		externalFetcher:   newExternalMetricsFetcher(emc),
+		kafkaFetcher:      newKafkaMetricsFetcher(params.APIReader, params.GetClock()),
+		rabbitMQFetcher:   newRabbitMQMetricsFetcher(params.APIReader, params.GetClock()),
 	}
 }
 
@@ -60,7 +128,27 @@ func (facade *MetricsFactory) GetMetricsFetcher(scaleMetric *v1alpha1.ScaleMetri
 		return facade.prometheusFetcher, nil
 	case v1alpha1.ResourceScaleMetricType:
 		return facade.resourceFetcher, nil
+	case v1alpha1.PodScaleMetricType:
+		return facade.podsFetcher, nil
+	case v1alpha1.ObjectScaleMetricType:
+		return facade.objectFetcher, nil
+	case v1alpha1.ExternalScaleMetricType:
+		return facade.externalFetcher, nil
+	case v1alpha1.KafkaScaleMetricType:
+		return facade.kafkaFetcher, nil
+	case v1alpha1.RabbitMQScaleMetricType:
+		return facade.rabbitMQFetcher, nil
 	default:
 		return nil, errors.New(fmt.Sprintf("Unknown metric type %s \n", scaleMetric.Type))
 	}
 }
+
+// GetRangeFetcher returns the fetcher of the metric history, only Prometheus metrics have one
+func (facade *MetricsFactory) GetRangeFetcher(scaleMetric *v1alpha1.ScaleMetric) (RangeFetcher, error) {
+	switch scaleMetric.Type {
+	case v1alpha1.PrometheusScaleMetricType:
+		return facade.prometheusFetcher, nil
+	default:
+		return nil, fmt.Errorf("history is not supported for metric type %s", scaleMetric.Type)
+	}
+}
@@ -53,4 +53,57 @@ var _ = Describe("MetricsFactory", func() {
 		Expect(err).To(BeNil(), "no error for supported metrics fetcher type")
 		Expect(fetcher).NotTo(BeNil())
 	})
+
+	It("Pods fetcher type", func() {
+		metricsFactory := NewMetricsFactory(fakeKratosSpec)
+		scaleMetric := &v1alpha1.ScaleMetric{
+			Type: v1alpha1.PodScaleMetricType,
+		}
+		fetcher, err := metricsFactory.GetMetricsFetcher(scaleMetric)
+
+		Expect(err).To(BeNil(), "no error for supported metrics fetcher type")
+		Expect(fetcher).NotTo(BeNil())
+	})
+	It("Object fetcher type", func() {
+		metricsFactory := NewMetricsFactory(fakeKratosSpec)
+		scaleMetric := &v1alpha1.ScaleMetric{
+			Type: v1alpha1.ObjectScaleMetricType,
+		}
+		fetcher, err := metricsFactory.GetMetricsFetcher(scaleMetric)
+
+		Expect(err).To(BeNil(), "no error for supported metrics fetcher type")
+		Expect(fetcher).NotTo(BeNil())
+	})
+	It("External fetcher type", func() {
+		metricsFactory := NewMetricsFactory(fakeKratosSpec)
+		scaleMetric := &v1alpha1.ScaleMetric{
+			Type: v1alpha1.ExternalScaleMetricType,
+		}
+		fetcher, err := metricsFactory.GetMetricsFetcher(scaleMetric)
+
+		Expect(err).To(BeNil(), "no error for supported metrics fetcher type")
+		Expect(fetcher).NotTo(BeNil())
+	})
+	It("Kafka fetcher type", func() {
+		metricsFactory := NewMetricsFactory(fakeKratosSpec)
+		scaleMetric := &v1alpha1.ScaleMetric{
+			Type: v1alpha1.KafkaScaleMetricType,
+		}
+		fetcher, err := metricsFactory.GetMetricsFetcher(scaleMetric)
+
+		Expect(err).To(BeNil(), "no error for supported metrics fetcher type")
+		Expect(fetcher).To(BeAssignableToTypeOf(&kafkaMetricsFetcher{}))
+		_, limitsReplicas := fetcher.(ReplicaLimiter)
+		Expect(limitsReplicas).To(BeTrue(), "kafka fetcher limits replicas to the partition count")
+	})
+	It("RabbitMQ fetcher type", func() {
+		metricsFactory := NewMetricsFactory(fakeKratosSpec)
+		scaleMetric := &v1alpha1.ScaleMetric{
+			Type: v1alpha1.RabbitMQScaleMetricType,
+		}
+		fetcher, err := metricsFactory.GetMetricsFetcher(scaleMetric)
+
+		Expect(err).To(BeNil(), "no error for supported metrics fetcher type")
+		Expect(fetcher).To(BeAssignableToTypeOf(&rabbitMQMetricsFetcher{}))
+	})
 })
@@ -70,6 +70,7 @@ var _ = BeforeSuite(func() {
 	fakeKratosSpec = &common.KratosParameters{
 		ClientConfig: cfg,
 		Client:       k8sClient,
+		APIReader:    k8sClient,
 	}
 }, 60)
 
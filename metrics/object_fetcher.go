@@ -0,0 +1,101 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package metrics
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	customclient "k8s.io/metrics/pkg/client/custom_metrics"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+type objectMetricsFetcher struct {
+	customMetricsClient customclient.CustomMetricsClient
+	mapper              meta.RESTMapper
+	log                 logr.Logger
+}
+
+func newObjectMetricsFetcher(customMetricsClient customclient.CustomMetricsClient, mapper meta.RESTMapper) *objectMetricsFetcher {
+	fetcher := &objectMetricsFetcher{
+		customMetricsClient: customMetricsClient,
+		mapper:              mapper,
+		log:                 log.Log.WithName("objectMetrics-fetcher"),
+	}
+
+	return fetcher
+}
+
+// Fetch returns the single value of the custom metric describing the object referenced by describedObject
+func (o *objectMetricsFetcher) Fetch(scaleMetric *v1alpha1.ScaleMetric, fetchContext *FetchContext) ([]MetricValue, error) {
+	if scaleMetric.Object == nil {
+		return nil, errors.New("object metric source is not defined")
+	}
+
+	describedObject := &scaleMetric.Object.DescribedObject
+
+	mapping, err := o.getObjectMapping(describedObject)
+	if err != nil {
+		return nil, err
+	}
+
+	metricSelector, err := toMetricSelector(scaleMetric.Object.Metric.Selector)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := o.customMetricsClient.NamespacedMetrics(fetchContext.Namespace)
+	if mapping.Scope.Name() == meta.RESTScopeNameRoot {
+		metrics = o.customMetricsClient.RootScopedMetrics()
+	}
+
+	metricValue, err := metrics.GetForObject(mapping.GroupVersionKind.GroupKind(), describedObject.Name, scaleMetric.Object.Metric.Name, metricSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	o.log.V(1).Info("Fetched object metric", "object", describedObject, "metric", scaleMetric.Object.Metric.Name, "value", metricValue.Value.String())
+
+	return []MetricValue{{QuantityValue(&metricValue.Value)}}, nil
+}
+
+func (o *objectMetricsFetcher) getObjectMapping(describedObject *v1alpha1.ScaleTargetReference) (*meta.RESTMapping, error) {
+	groupVersion, err := schema.ParseGroupVersion(describedObject.APIVersion)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid API version in described object reference: %v", err)
+	}
+
+	groupKind := schema.GroupKind{
+		Group: groupVersion.Group,
+		Kind:  describedObject.Kind,
+	}
+
+	var versions []string
+	if groupVersion.Version != "" {
+		versions = append(versions, groupVersion.Version)
+	}
+
+	mapping, err := o.mapper.RESTMapping(groupKind, versions...)
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to determine resource for described object reference: %v", err)
+	}
+
+	return mapping, nil
+}
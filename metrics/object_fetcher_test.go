@@ -0,0 +1,130 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package metrics
+
+import (
+	"time"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/testing"
+	cmapi "k8s.io/metrics/pkg/apis/custom_metrics/v1beta2"
+	cmfake "k8s.io/metrics/pkg/client/custom_metrics/fake"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ObjectFetcher", func() {
+	var fakeCustomMetricsClient *cmfake.FakeCustomMetricsClient
+	var mapper *meta.DefaultRESTMapper
+	var lastAction cmfake.GetForAction
+
+	BeforeEach(func() {
+		mapper = meta.NewDefaultRESTMapper([]schema.GroupVersion{})
+		mapper.Add(schema.GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"}, meta.RESTScopeNamespace)
+		mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, meta.RESTScopeRoot)
+
+		fakeCustomMetricsClient = &cmfake.FakeCustomMetricsClient{}
+		fakeCustomMetricsClient.AddReactor("get", "*", func(action testing.Action) (handled bool, ret runtime.Object, err error) {
+			lastAction = action.(cmfake.GetForAction)
+
+			metrics := &cmapi.MetricValueList{
+				Items: []cmapi.MetricValue{
+					{
+						DescribedObject: v1.ObjectReference{
+							Name: lastAction.GetName(),
+						},
+						Metric: cmapi.MetricIdentifier{
+							Name: lastAction.GetMetricName(),
+						},
+						Timestamp: metav1.Time{Time: time.Now()},
+						Value:     *resource.NewQuantity(120, resource.DecimalSI),
+					},
+				},
+			}
+
+			return true, metrics, nil
+		})
+	})
+
+	It("Undefined object metric source", func() {
+		fetcher := newObjectMetricsFetcher(fakeCustomMetricsClient, mapper)
+
+		scaleMetric := &v1alpha1.ScaleMetric{
+			Type: v1alpha1.ObjectScaleMetricType,
+		}
+		_, err := fetcher.Fetch(scaleMetric, &FetchContext{Namespace: namespace, Selector: labels.Everything()})
+
+		Expect(err).NotTo(BeNil(), "missing object source should result in error")
+	})
+
+	It("Unknown described object kind", func() {
+		fetcher := newObjectMetricsFetcher(fakeCustomMetricsClient, mapper)
+
+		scaleMetric := &v1alpha1.ScaleMetric{
+			Type: v1alpha1.ObjectScaleMetricType,
+			Object: &v1alpha1.ObjectMetricSource{
+				DescribedObject: v1alpha1.ScaleTargetReference{APIVersion: "example.com/v1", Kind: "Unknown", Name: "main"},
+				Metric:          v1alpha1.MetricIdentifier{Name: "requests_per_second"},
+			},
+		}
+		_, err := fetcher.Fetch(scaleMetric, &FetchContext{Namespace: namespace, Selector: labels.Everything()})
+
+		Expect(err).NotTo(BeNil(), "unknown described object kind should result in error")
+	})
+
+	It("Namespaced described object", func() {
+		fetcher := newObjectMetricsFetcher(fakeCustomMetricsClient, mapper)
+
+		scaleMetric := &v1alpha1.ScaleMetric{
+			Type: v1alpha1.ObjectScaleMetricType,
+			Object: &v1alpha1.ObjectMetricSource{
+				DescribedObject: v1alpha1.ScaleTargetReference{APIVersion: "networking.k8s.io/v1", Kind: "Ingress", Name: "main"},
+				Metric:          v1alpha1.MetricIdentifier{Name: "requests_per_second"},
+			},
+		}
+		res, err := fetcher.Fetch(scaleMetric, &FetchContext{Namespace: namespace, Selector: labels.Everything()})
+
+		Expect(err).To(BeNil(), "no errors expected for valid arguments")
+		Expect(res).To(Equal([]MetricValue{{120}}))
+		Expect(lastAction.GetNamespace()).To(Equal(namespace))
+		Expect(lastAction.GetName()).To(Equal("main"))
+		Expect(lastAction.GetResource().Resource).To(Equal("ingresses.networking.k8s.io"))
+	})
+
+	It("Root scoped described object", func() {
+		fetcher := newObjectMetricsFetcher(fakeCustomMetricsClient, mapper)
+
+		scaleMetric := &v1alpha1.ScaleMetric{
+			Type: v1alpha1.ObjectScaleMetricType,
+			Object: &v1alpha1.ObjectMetricSource{
+				DescribedObject: v1alpha1.ScaleTargetReference{APIVersion: "v1", Kind: "Namespace", Name: namespace},
+				Metric:          v1alpha1.MetricIdentifier{Name: "queue_length"},
+			},
+		}
+		res, err := fetcher.Fetch(scaleMetric, &FetchContext{Namespace: namespace, Selector: labels.Everything()})
+
+		Expect(err).To(BeNil(), "no errors expected for valid arguments")
+		Expect(res).To(Equal([]MetricValue{{120}}))
+		Expect(lastAction.GetNamespace()).To(BeEmpty(), "root scoped object metrics are not namespaced")
+	})
+})
@@ -0,0 +1,83 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package metrics
+
+import (
+	"errors"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	customclient "k8s.io/metrics/pkg/client/custom_metrics"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var podGroupKind = schema.GroupKind{Kind: "Pod"}
+
+type podsMetricsFetcher struct {
+	customMetricsClient customclient.CustomMetricsClient
+	log                 logr.Logger
+}
+
+func newPodsMetricsFetcher(customMetricsClient customclient.CustomMetricsClient) *podsMetricsFetcher {
+	fetcher := &podsMetricsFetcher{
+		customMetricsClient: customMetricsClient,
+		log:                 log.Log.WithName("podsMetrics-fetcher"),
+	}
+
+	return fetcher
+}
+
+// Fetch returns the value of the custom metric for every pod matching the scale target selector which reports it.
+// No reported value is an error, an empty result would otherwise be read as no load.
+func (p *podsMetricsFetcher) Fetch(scaleMetric *v1alpha1.ScaleMetric, fetchContext *FetchContext) ([]MetricValue, error) {
+	if scaleMetric.Pods == nil {
+		return nil, errors.New("pods metric source is not defined")
+	}
+
+	metricSelector, err := toMetricSelector(scaleMetric.Pods.Metric.Selector)
+	if err != nil {
+		return nil, err
+	}
+
+	metricValueList, err := p.customMetricsClient.NamespacedMetrics(fetchContext.Namespace).GetForObjects(podGroupKind, fetchContext.Selector, scaleMetric.Pods.Metric.Name, metricSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	p.log.V(1).Info("Fetched pods metrics", "selector", fetchContext.Selector.String(), "metric", scaleMetric.Pods.Metric.Name, "metrics", metricValueList.Items)
+
+	if len(metricValueList.Items) == 0 {
+		return nil, errors.New("no values returned by custom metrics API")
+	}
+
+	ret := make([]MetricValue, 0, len(metricValueList.Items))
+
+	for _, metricValue := range metricValueList.Items {
+		ret = append(ret, MetricValue{QuantityValue(&metricValue.Value)})
+	}
+
+	return ret, nil
+}
+
+// toMetricSelector converts the optional selector of a metric identifier, nil selects every metric series
+func toMetricSelector(selector *metav1.LabelSelector) (labels.Selector, error) {
+	if selector == nil {
+		return labels.Everything(), nil
+	}
+
+	return metav1.LabelSelectorAsSelector(selector)
+}
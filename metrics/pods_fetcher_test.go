@@ -0,0 +1,144 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/testing"
+	cmapi "k8s.io/metrics/pkg/apis/custom_metrics/v1beta2"
+	cmfake "k8s.io/metrics/pkg/client/custom_metrics/fake"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func makePodCustomMetric(id int, metricName string) cmapi.MetricValue {
+	return cmapi.MetricValue{
+		DescribedObject: v1.ObjectReference{
+			Kind:      "Pod",
+			Name:      fmt.Sprintf("%s-%d", podNamePrefix, id),
+			Namespace: namespace,
+		},
+		Metric: cmapi.MetricIdentifier{
+			Name: metricName,
+		},
+		Timestamp: metav1.Time{Time: time.Now()},
+		Value:     *resource.NewQuantity(int64(id*10), resource.DecimalSI),
+	}
+}
+
+var _ = Describe("PodsFetcher", func() {
+	var fakeCustomMetricsClient *cmfake.FakeCustomMetricsClient
+	var lastAction cmfake.GetForAction
+
+	BeforeEach(func() {
+		fakeCustomMetricsClient = &cmfake.FakeCustomMetricsClient{}
+
+		fakeCustomMetricsClient.AddReactor("get", "pods", func(action testing.Action) (handled bool, ret runtime.Object, err error) {
+			lastAction = action.(cmfake.GetForAction)
+
+			metrics := &cmapi.MetricValueList{}
+
+			for i := 1; i <= numFakePods; i++ {
+				metrics.Items = append(metrics.Items, makePodCustomMetric(i, lastAction.GetMetricName()))
+			}
+
+			return true, metrics, nil
+		})
+	})
+
+	It("Undefined pods metric source", func() {
+		fetcher := newPodsMetricsFetcher(fakeCustomMetricsClient)
+
+		scaleMetric := &v1alpha1.ScaleMetric{
+			Type: v1alpha1.PodScaleMetricType,
+		}
+		_, err := fetcher.Fetch(scaleMetric, &FetchContext{Namespace: namespace, Selector: labels.Everything()})
+
+		Expect(err).NotTo(BeNil(), "missing pods source should result in error")
+	})
+
+	It("Per pod values", func() {
+		fetcher := newPodsMetricsFetcher(fakeCustomMetricsClient)
+
+		scaleMetric := &v1alpha1.ScaleMetric{
+			Type: v1alpha1.PodScaleMetricType,
+			Pods: &v1alpha1.PodsMetricSource{
+				Metric: v1alpha1.MetricIdentifier{
+					Name: "http_requests_per_second",
+				},
+			},
+		}
+		selector := labels.SelectorFromSet(labels.Set{
+			"app": "nginx",
+		})
+		res, err := fetcher.Fetch(scaleMetric, &FetchContext{Namespace: namespace, Selector: selector})
+
+		Expect(err).To(BeNil(), "no errors expected for valid arguments")
+		Expect(len(res)).To(Equal(numFakePods), "get a metric for every pod")
+		Expect(res[0].Value).To(Equal(float64(10)))
+		Expect(lastAction.GetMetricName()).To(Equal("http_requests_per_second"))
+		Expect(lastAction.GetLabelSelector().String()).To(Equal(selector.String()), "pods should be selected with the scale target selector")
+	})
+
+	It("No pod reports the metric", func() {
+		emptyMetricsClient := &cmfake.FakeCustomMetricsClient{}
+		emptyMetricsClient.AddReactor("get", "pods", func(action testing.Action) (handled bool, ret runtime.Object, err error) {
+			return true, &cmapi.MetricValueList{}, nil
+		})
+		fetcher := newPodsMetricsFetcher(emptyMetricsClient)
+
+		scaleMetric := &v1alpha1.ScaleMetric{
+			Type: v1alpha1.PodScaleMetricType,
+			Pods: &v1alpha1.PodsMetricSource{
+				Metric: v1alpha1.MetricIdentifier{
+					Name: "http_requests_per_second",
+				},
+			},
+		}
+		_, err := fetcher.Fetch(scaleMetric, &FetchContext{Namespace: namespace, Selector: labels.Everything()})
+
+		Expect(err).To(MatchError("no values returned by custom metrics API"), "empty result should not be read as no load")
+	})
+
+	It("Invalid metric selector", func() {
+		fetcher := newPodsMetricsFetcher(fakeCustomMetricsClient)
+
+		scaleMetric := &v1alpha1.ScaleMetric{
+			Type: v1alpha1.PodScaleMetricType,
+			Pods: &v1alpha1.PodsMetricSource{
+				Metric: v1alpha1.MetricIdentifier{
+					Name: "http_requests_per_second",
+					Selector: &metav1.LabelSelector{
+						MatchExpressions: []metav1.LabelSelectorRequirement{
+							{Key: "verb", Operator: "Unknown"},
+						},
+					},
+				},
+			},
+		}
+		_, err := fetcher.Fetch(scaleMetric, &FetchContext{Namespace: namespace, Selector: labels.Everything()})
+
+		Expect(err).NotTo(BeNil(), "invalid metric selector should result in error")
+	})
+})
@@ -0,0 +1,146 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/api"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// keys of the Secret with the credentials and headers of a Prometheus endpoint, besides the shared TLS keys
+const (
+	prometheusTokenKey        = "token"
+	prometheusUsernameKey     = "username"
+	prometheusPasswordKey     = "password"
+	prometheusHeaderKeyPrefix = "header."
+)
+
+// prometheusAuth is the content of a Prometheus auth Secret
+type prometheusAuth struct {
+	token              string
+	username           string
+	password           string
+	ca                 []byte
+	cert               []byte
+	key                []byte
+	insecureSkipVerify bool
+	headers            map[string]string
+}
+
+// newPrometheusAuth reads and checks the keys of a Prometheus auth Secret, unknown keys are rejected to catch typos
+func newPrometheusAuth(secret *corev1.Secret) (*prometheusAuth, error) {
+	auth := &prometheusAuth{headers: make(map[string]string)}
+
+	for key, value := range secret.Data {
+		switch {
+		case key == prometheusTokenKey:
+			auth.token = strings.TrimSpace(string(value))
+		case key == prometheusUsernameKey:
+			auth.username = string(value)
+		case key == prometheusPasswordKey:
+			auth.password = string(value)
+		case key == caKey:
+			auth.ca = value
+		case key == certKey:
+			auth.cert = value
+		case key == keyKey:
+			auth.key = value
+		case key == insecureSkipVerifyKey:
+			insecureSkipVerify, err := strconv.ParseBool(strings.TrimSpace(string(value)))
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %v", insecureSkipVerifyKey, err)
+			}
+			auth.insecureSkipVerify = insecureSkipVerify
+		case strings.HasPrefix(key, prometheusHeaderKeyPrefix) && len(key) > len(prometheusHeaderKeyPrefix):
+			auth.headers[http.CanonicalHeaderKey(strings.TrimPrefix(key, prometheusHeaderKeyPrefix))] = strings.TrimSpace(string(value))
+		case key == corev1.ServiceAccountTokenKey || key == corev1.ServiceAccountRootCAKey || key == corev1.ServiceAccountNamespaceKey:
+			// keys of service account token secrets besides token and ca.crt
+		default:
+			return nil, fmt.Errorf("unknown key '%s'", key)
+		}
+	}
+
+	if auth.token != "" && auth.username != "" {
+		return nil, errors.New("token and username are mutually exclusive")
+	}
+
+	if auth.username == "" && auth.password != "" {
+		return nil, fmt.Errorf("%s requires %s", prometheusPasswordKey, prometheusUsernameKey)
+	}
+
+	if (len(auth.cert) == 0) != (len(auth.key) == 0) {
+		return nil, fmt.Errorf("%s and %s must be set together", certKey, keyKey)
+	}
+
+	if _, found := auth.headers["Authorization"]; found && (auth.token != "" || auth.username != "") {
+		return nil, errors.New("the Authorization header can't be combined with a token or username")
+	}
+
+	return auth, nil
+}
+
+// roundTripper creates the transport of the Prometheus client with the TLS settings and headers of the Secret
+func (a *prometheusAuth) roundTripper() (http.RoundTripper, error) {
+	transport := api.DefaultRoundTripper.(*http.Transport).Clone()
+
+	if len(a.ca) > 0 || len(a.cert) > 0 || a.insecureSkipVerify {
+		tlsConfig, err := newTLSConfig(a.ca, a.cert, a.key, a.insecureSkipVerify)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	headers := make(http.Header)
+	for name, value := range a.headers {
+		headers.Set(name, value)
+	}
+
+	switch {
+	case a.token != "":
+		headers.Set("Authorization", "Bearer "+a.token)
+	case a.username != "":
+		request := &http.Request{Header: make(http.Header)}
+		request.SetBasicAuth(a.username, a.password)
+		headers.Set("Authorization", request.Header.Get("Authorization"))
+	}
+
+	if len(headers) == 0 {
+		return transport, nil
+	}
+
+	return &headerRoundTripper{headers: headers, next: transport}, nil
+}
+
+// headerRoundTripper sets headers on every request
+type headerRoundTripper struct {
+	headers http.Header
+	next    http.RoundTripper
+}
+
+func (rt *headerRoundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	// round trippers must not modify the request
+	request = request.Clone(request.Context())
+	for name, values := range rt.headers {
+		request.Header[name] = values
+	}
+
+	return rt.next.RoundTrip(request)
+}
@@ -0,0 +1,71 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package metrics
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("PrometheusAuth", func() {
+	newSecret := func(data map[string]string) *corev1.Secret {
+		secret := &corev1.Secret{Data: map[string][]byte{}}
+		for key, value := range data {
+			secret.Data[key] = []byte(value)
+		}
+		return secret
+	}
+
+	DescribeTable("Invalid secrets",
+		func(data map[string]string, message string) {
+			auth, err := newPrometheusAuth(newSecret(data))
+			if err == nil {
+				_, err = auth.roundTripper()
+			}
+
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("token and username", map[string]string{"token": "t", "username": "u"}, "mutually exclusive"),
+		Entry("password without username", map[string]string{"password": "p"}, "password requires username"),
+		Entry("certificate without key", map[string]string{"tls.crt": "c"}, "must be set together"),
+		Entry("unknown key", map[string]string{"tocken": "t"}, "unknown key 'tocken'"),
+		Entry("invalid insecureSkipVerify", map[string]string{"insecureSkipVerify": "maybe"}, "invalid insecureSkipVerify"),
+		Entry("authorization header with token", map[string]string{"token": "t", "header.authorization": "Basic x"}, "Authorization header"),
+		Entry("invalid CA", map[string]string{"ca.crt": "not a certificate"}, "no PEM certificate found in ca.crt"),
+		Entry("invalid client certificate", map[string]string{"tls.crt": "c", "tls.key": "k"}, "invalid client certificate"),
+	)
+
+	It("TLS settings", func() {
+		auth, err := newPrometheusAuth(newSecret(map[string]string{"insecureSkipVerify": "true"}))
+		Expect(err).To(BeNil())
+
+		roundTripper, err := auth.roundTripper()
+
+		Expect(err).To(BeNil())
+		transport, ok := roundTripper.(*http.Transport)
+		Expect(ok).To(BeTrue(), "transport should not be wrapped without headers")
+		Expect(transport.TLSClientConfig.InsecureSkipVerify).To(BeTrue())
+	})
+
+	It("Digest changes with the data", func() {
+		first := secretDigest(newSecret(map[string]string{"token": "first"}))
+
+		Expect(secretDigest(newSecret(map[string]string{"token": "first"}))).To(Equal(first))
+		Expect(secretDigest(newSecret(map[string]string{"token": "second"}))).NotTo(Equal(first))
+	})
+})
@@ -17,7 +17,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strconv"
+	"net/http"
+	"sort"
 	"time"
 
 	"github.com/adobe/kratos/api/v1alpha1"
@@ -26,47 +27,72 @@ import (
 	"github.com/prometheus/client_golang/api"
 	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/prometheus/common/model"
-	"k8s.io/apimachinery/pkg/labels"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 type prometheusMetricsFetcher struct {
 	defaultUrl             string
+	defaultAuthSecret      types.NamespacedName
+	secretReader           client.Reader
 	prometheusClientsCache *cache.TTLCache
 	log                    logr.Logger
+	clock                  clock.Clock
 }
 
 type prometheusClient struct {
 	v1.API
+	roundTripper http.RoundTripper
 }
 
 func (c *prometheusClient) Close() error {
+	if transport, ok := c.roundTripper.(interface{ CloseIdleConnections() }); ok {
+		transport.CloseIdleConnections()
+	}
 	return nil
 }
 
-func newPrometheusMetricsFetcher(defaultUrl string) *prometheusMetricsFetcher {
+// newPrometheusMetricsFetcher creates the fetcher of Prometheus metrics, the default auth secret is used for queries
+// to the default url without an auth secret of their own. Auth secrets are read with the secret reader.
+func newPrometheusMetricsFetcher(defaultUrl string, defaultAuthSecret types.NamespacedName, secretReader client.Reader, clock clock.Clock) *prometheusMetricsFetcher {
 	fetcher := &prometheusMetricsFetcher{
 		defaultUrl:             defaultUrl,
-		prometheusClientsCache: cache.NewTTLCache("prometheus-clients", defaultCacheTtl),
+		defaultAuthSecret:      defaultAuthSecret,
+		secretReader:           secretReader,
+		prometheusClientsCache: cache.NewTTLCache("prometheus-clients", defaultCacheTtl, clock),
 		log:                    log.Log.WithName("prom-fetcher"),
+		clock:                  clock,
 	}
 
 	return fetcher
 }
 
-func (p *prometheusMetricsFetcher) Fetch(scaleMetric *v1alpha1.ScaleMetric, namespace string, selector labels.Selector) ([]MetricValue, error) {
-	client, err := p.getOrCreateClient(scaleMetric.Prometheus.PrometheusEndpoint)
+func (p *prometheusMetricsFetcher) Fetch(scaleMetric *v1alpha1.ScaleMetric, fetchContext *FetchContext) ([]MetricValue, error) {
+	query, err := RenderQuery(scaleMetric.Prometheus.MetricQuery, fetchContext)
+
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := p.getOrCreateClient(scaleMetric.Prometheus, fetchContext.Namespace)
 
 	if err != nil {
 		return nil, err
 	}
 
+	if scaleMetric.Prometheus.Range != nil {
+		return p.fetchWindow(client, scaleMetric.Prometheus, query)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), defaultCallTimeout)
 	defer cancel()
 
-	p.log.V(1).Info("fetching metrics", "url", scaleMetric.Prometheus.PrometheusEndpoint, "query", scaleMetric.Prometheus.MetricQuery)
+	p.log.V(1).Info("fetching metrics", "url", scaleMetric.Prometheus.PrometheusEndpoint, "query", query)
 
-	result, warnings, err := client.Query(ctx, scaleMetric.Prometheus.MetricQuery, time.Now())
+	result, warnings, err := client.Query(ctx, query, p.clock.Now())
 
 	if err != nil {
 		return nil, err
@@ -78,23 +104,103 @@ func (p *prometheusMetricsFetcher) Fetch(scaleMetric *v1alpha1.ScaleMetric, name
 	return p.convertToMetricValue(result)
 }
 
-func (p *prometheusMetricsFetcher) getOrCreateClient(queryUrl string) (*prometheusClient, error) {
+// fetchWindow runs the query as a range query over the window of the source ending now,
+// every returned series is reduced to a single value with the aggregation of the range
+func (p *prometheusMetricsFetcher) fetchWindow(client *prometheusClient, source *v1alpha1.PrometheusMetricSource, query string) ([]MetricValue, error) {
+	window := source.Range
+	end := p.clock.Now()
+	start := end.Add(-window.Window.Duration)
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultCallTimeout)
+	defer cancel()
+
+	p.log.V(1).Info("fetching metrics window", "url", source.PrometheusEndpoint, "query", query, "window", window.Window.Duration, "step", window.Step.Duration, "aggregation", window.Aggregation)
+
+	result, warnings, err := client.QueryRange(ctx, query, v1.Range{Start: start, End: end, Step: window.Step.Duration})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if warnings != nil {
+		p.log.V(1).Info("warnings on fetching metrics window", "url", source.PrometheusEndpoint, "warnings", warnings)
+	}
+
+	return p.reduceMatrix(result, window)
+}
+
+// FetchRange runs the query as a range query, the values of all returned series are summed per timestamp
+func (p *prometheusMetricsFetcher) FetchRange(scaleMetric *v1alpha1.ScaleMetric, fetchContext *FetchContext, start time.Time, end time.Time, step time.Duration) ([]Sample, error) {
+	query, err := RenderQuery(scaleMetric.Prometheus.MetricQuery, fetchContext)
+
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := p.getOrCreateClient(scaleMetric.Prometheus, fetchContext.Namespace)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultCallTimeout)
+	defer cancel()
+
+	p.log.V(1).Info("fetching metrics range", "url", scaleMetric.Prometheus.PrometheusEndpoint, "query", query, "start", start, "end", end, "step", step)
+
+	result, warnings, err := client.QueryRange(ctx, query, v1.Range{Start: start, End: end, Step: step})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if warnings != nil {
+		p.log.V(1).Info("warnings on fetching metrics range", "url", scaleMetric.Prometheus.PrometheusEndpoint, "warnings", warnings)
+	}
+
+	return p.convertToSamples(result)
+}
+
+// getOrCreateClient returns the client of the url and auth secret of the source. Clients are cached by the digest of
+// the secret so that a changed secret is picked up on the next fetch.
+func (p *prometheusMetricsFetcher) getOrCreateClient(source *v1alpha1.PrometheusMetricSource, namespace string) (*prometheusClient, error) {
 	prometheusUrl := p.defaultUrl
+	var secretName types.NamespacedName
 
-	if queryUrl != "" {
-		prometheusUrl = queryUrl
+	if source.PrometheusEndpoint != "" {
+		prometheusUrl = source.PrometheusEndpoint
+	} else {
+		secretName = p.defaultAuthSecret
 	}
 
-	cachedClient, found := p.prometheusClientsCache.Get(prometheusUrl)
+	if source.AuthSecretName != "" {
+		secretName = types.NamespacedName{Namespace: namespace, Name: source.AuthSecretName}
+	}
+
+	key := prometheusUrl
+	var secret *corev1.Secret
+
+	if secretName.Name != "" {
+		var err error
+		secret, err = getSecret(p.secretReader, secretName, "prometheus auth")
+
+		if err != nil {
+			return nil, err
+		}
+
+		key = fmt.Sprintf("%s|%s|%s", prometheusUrl, secretName, secretDigest(secret))
+	}
+
+	cachedClient, found := p.prometheusClientsCache.Get(key)
 
 	if !found {
-		client, err := p.createPrometheusApi(prometheusUrl)
+		client, err := p.createPrometheusApi(prometheusUrl, secret)
 
 		if err != nil {
 			return nil, err
 		}
 
-		p.prometheusClientsCache.Put(queryUrl, client)
+		p.prometheusClientsCache.Put(key, client)
 		cachedClient = client
 	}
 
@@ -102,9 +208,24 @@ func (p *prometheusMetricsFetcher) getOrCreateClient(queryUrl string) (*promethe
 	return castedClient, nil
 }
 
-func (p *prometheusMetricsFetcher) createPrometheusApi(prometheusUrl string) (*prometheusClient, error) {
+func (p *prometheusMetricsFetcher) createPrometheusApi(prometheusUrl string, secret *corev1.Secret) (*prometheusClient, error) {
+	roundTripper := api.DefaultRoundTripper
+
+	if secret != nil {
+		auth, err := newPrometheusAuth(secret)
+		if err != nil {
+			return nil, fmt.Errorf("invalid prometheus auth secret %s/%s: %v", secret.Namespace, secret.Name, err)
+		}
+
+		roundTripper, err = auth.roundTripper()
+		if err != nil {
+			return nil, fmt.Errorf("invalid prometheus auth secret %s/%s: %v", secret.Namespace, secret.Name, err)
+		}
+	}
+
 	client, err := api.NewClient(api.Config{
-		Address: prometheusUrl,
+		Address:      prometheusUrl,
+		RoundTripper: roundTripper,
 	})
 
 	if err != nil {
@@ -112,7 +233,7 @@ func (p *prometheusMetricsFetcher) createPrometheusApi(prometheusUrl string) (*p
 		return nil, err
 	}
 
-	prometheusClient := &prometheusClient{v1.NewAPI(client)}
+	prometheusClient := &prometheusClient{API: v1.NewAPI(client), roundTripper: roundTripper}
 	return prometheusClient, nil
 }
 
@@ -163,16 +284,70 @@ func (p *prometheusMetricsFetcher) convertVector(value model.Value) ([]MetricVal
 	return result, nil
 }
 
-func (p *prometheusMetricsFetcher) convertSampleValue(sample model.SampleValue) (MetricValue, error) {
-	integerValue, err := strconv.ParseInt(sample.String(), 10, 64)
+func (p *prometheusMetricsFetcher) convertToSamples(result model.Value) ([]Sample, error) {
+	matrix, ok := result.(model.Matrix)
 
-	if err != nil {
-		return MetricValue{}, err
+	if !ok {
+		return nil, fmt.Errorf("unsupported prometheus range result type: %v", result.Type())
+	}
+
+	sums := make(map[model.Time]float64)
+
+	for _, stream := range matrix {
+		for _, pair := range stream.Values {
+			// gaps are filled by the consumer of the history, invalid values are dropped like missing ones
+			if _, err := p.convertSampleValue(pair.Value); err != nil {
+				continue
+			}
+			sums[pair.Timestamp] += float64(pair.Value)
+		}
+	}
+
+	samples := make([]Sample, 0, len(sums))
+	for timestamp, value := range sums {
+		samples = append(samples, Sample{Timestamp: timestamp.Time(), Value: value})
 	}
 
-	metricValue := MetricValue{
-		Value: integerValue,
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i].Timestamp.Before(samples[j].Timestamp)
+	})
+
+	return samples, nil
+}
+
+// reduceMatrix reduces every series of the matrix to a value, series without valid values are dropped like missing ones
+func (p *prometheusMetricsFetcher) reduceMatrix(result model.Value, window *v1alpha1.PrometheusRange) ([]MetricValue, error) {
+	matrix, ok := result.(model.Matrix)
+
+	if !ok {
+		return nil, fmt.Errorf("unsupported prometheus range result type: %v", result.Type())
 	}
 
-	return metricValue, nil
+	values := make([]MetricValue, 0, matrix.Len())
+
+	for _, stream := range matrix {
+		samples := make([]Sample, 0, len(stream.Values))
+		for _, pair := range stream.Values {
+			if _, err := p.convertSampleValue(pair.Value); err != nil {
+				continue
+			}
+			samples = append(samples, Sample{Timestamp: pair.Timestamp.Time(), Value: float64(pair.Value)})
+		}
+
+		if len(samples) == 0 {
+			continue
+		}
+
+		value, err := Reduce(samples, window.Aggregation, window.HalfLife.Duration)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+func (p *prometheusMetricsFetcher) convertSampleValue(sample model.SampleValue) (MetricValue, error) {
+	return NewMetricValue(float64(sample))
 }
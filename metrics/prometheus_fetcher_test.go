@@ -14,14 +14,24 @@ written permission of Adobe.
 package metrics
 
 import (
+	"context"
 	"encoding/json"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"time"
 
 	"github.com/adobe/kratos/api/v1alpha1"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/prometheus/common/model"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	testingclock "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 type queryResult struct {
@@ -41,12 +51,17 @@ var _ = Describe("PrometheusFetcher", func() {
 	var testServer *httptest.Server
 	var fetcher MetricsFetcher
 	var queryResults map[string]queryResult
+	var queryTime string
+	var requestHeaders http.Header
+	fakeClock := testingclock.NewFakeClock(time.Date(2021, 7, 5, 10, 0, 0, 0, time.UTC))
 
 	BeforeEach(func() {
 		testServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 			req.ParseForm()
 
 			queryKey := req.Form.Get("query")
+			queryTime = req.Form.Get("time")
+			requestHeaders = req.Header
 			results, _ := queryResults[queryKey]
 
 			testResp, _ := json.Marshal(results)
@@ -59,7 +74,7 @@ var _ = Describe("PrometheusFetcher", func() {
 
 			w.Write(body)
 		}))
-		fetcher = newPrometheusMetricsFetcher(testServer.URL)
+		fetcher = newPrometheusMetricsFetcher(testServer.URL, types.NamespacedName{}, nil, fakeClock)
 		queryResults = make(map[string]queryResult, 0)
 	})
 
@@ -72,7 +87,7 @@ var _ = Describe("PrometheusFetcher", func() {
 			Prometheus: &v1alpha1.PrometheusMetricSource{
 				MetricQuery: "count(up)",
 			}}
-		_, err := fetcher.Fetch(scaleMetric, "", nil)
+		_, err := fetcher.Fetch(scaleMetric, &FetchContext{})
 
 		Expect(err).NotTo(BeNil(), "non prometheus response should result in error")
 	})
@@ -89,11 +104,12 @@ var _ = Describe("PrometheusFetcher", func() {
 			Prometheus: &v1alpha1.PrometheusMetricSource{
 				MetricQuery: query,
 			}}
-		fetchResults, err := fetcher.Fetch(scaleMetric, "", nil)
+		fetchResults, err := fetcher.Fetch(scaleMetric, &FetchContext{})
 
 		Expect(err).To(BeNil(), "no errors on scalar value")
 		Expect(len(fetchResults)).To(Equal(1), "scalar value should result in single item")
-		Expect(fetchResults[0].Value).To(Equal(int64(55)), "metric value should match scalar value")
+		Expect(fetchResults[0].Value).To(Equal(float64(55)), "metric value should match scalar value")
+		Expect(queryTime).To(Equal("1625479200"), "query should be evaluated at the time of the clock")
 	})
 
 	It("Vector value - empty", func() {
@@ -106,7 +122,7 @@ var _ = Describe("PrometheusFetcher", func() {
 			Prometheus: &v1alpha1.PrometheusMetricSource{
 				MetricQuery: query,
 			}}
-		fetchResults, err := fetcher.Fetch(scaleMetric, "", nil)
+		fetchResults, err := fetcher.Fetch(scaleMetric, &FetchContext{})
 
 		Expect(err).To(BeNil(), "no errors on vector value")
 		Expect(len(fetchResults)).To(Equal(0), "empty vector value should result in empty metrics")
@@ -135,9 +151,210 @@ var _ = Describe("PrometheusFetcher", func() {
 			Prometheus: &v1alpha1.PrometheusMetricSource{
 				MetricQuery: query,
 			}}
-		fetchResults, err := fetcher.Fetch(scaleMetric, "", nil)
+		fetchResults, err := fetcher.Fetch(scaleMetric, &FetchContext{})
 
 		Expect(err).To(BeNil(), "no errors on vector value")
 		Expect(len(fetchResults)).To(Equal(len(samples)), "vector size should be equal to returned metrics size")
 	})
+	It("Scalar value - fractional", func() {
+		query := "sum(rate(http_requests_total[1m]))"
+		queryResults[query] = queryResult{
+			Type: model.ValScalar,
+			Result: model.Scalar{
+				Value:     0.75,
+				Timestamp: model.Now(),
+			}}
+		scaleMetric := &v1alpha1.ScaleMetric{
+			Prometheus: &v1alpha1.PrometheusMetricSource{
+				MetricQuery: query,
+			}}
+		fetchResults, err := fetcher.Fetch(scaleMetric, &FetchContext{})
+
+		Expect(err).To(BeNil(), "no errors on fractional scalar value")
+		Expect(fetchResults[0].Value).To(Equal(0.75), "metric value should keep fractional part")
+	})
+
+	It("Scalar value - NaN", func() {
+		query := "sum(up) / 0"
+		queryResults[query] = queryResult{
+			Type: model.ValScalar,
+			Result: model.Scalar{
+				Value:     model.SampleValue(math.NaN()),
+				Timestamp: model.Now(),
+			}}
+		scaleMetric := &v1alpha1.ScaleMetric{
+			Prometheus: &v1alpha1.PrometheusMetricSource{
+				MetricQuery: query,
+			}}
+		_, err := fetcher.Fetch(scaleMetric, &FetchContext{})
+
+		Expect(err).NotTo(BeNil(), "NaN value should result in error")
+	})
+
+	It("Query template rendered before query", func() {
+		query := `sum(up{namespace="team",app="nginx"}) / 2`
+		queryResults[query] = queryResult{
+			Type:   model.ValScalar,
+			Result: model.Scalar{Value: 4, Timestamp: model.Now()},
+		}
+		scaleMetric := &v1alpha1.ScaleMetric{
+			Prometheus: &v1alpha1.PrometheusMetricSource{
+				MetricQuery: `sum(up{namespace="{{ .Namespace }}",{{ .Selector }}}) / {{ .Replicas }}`,
+			}}
+		fetchResults, err := fetcher.Fetch(scaleMetric, &FetchContext{Namespace: "team", Selector: labels.SelectorFromSet(labels.Set{"app": "nginx"}), Replicas: 2})
+
+		Expect(err).To(BeNil(), "no errors on rendered query")
+		Expect(fetchResults[0].Value).To(Equal(float64(4)), "rendered query should be sent to prometheus")
+	})
+
+	It("Window reduced per series", func() {
+		query := "sum by (pod) (rate(requests[1m]))"
+		end := model.TimeFromUnix(fakeClock.Now().Unix())
+		queryResults[query] = queryResult{
+			Type: model.ValMatrix,
+			Result: model.Matrix{
+				{
+					Metric: model.Metric{"pod": "nginx-1"},
+					Values: []model.SamplePair{{Timestamp: end.Add(-time.Minute), Value: 4}, {Timestamp: end, Value: 2}},
+				},
+				{
+					Metric: model.Metric{"pod": "nginx-2"},
+					Values: []model.SamplePair{{Timestamp: end.Add(-time.Minute), Value: 1}, {Timestamp: end, Value: model.SampleValue(math.NaN())}},
+				},
+				{
+					Metric: model.Metric{"pod": "nginx-3"},
+					Values: []model.SamplePair{{Timestamp: end, Value: model.SampleValue(math.NaN())}},
+				},
+			}}
+		scaleMetric := &v1alpha1.ScaleMetric{
+			Prometheus: &v1alpha1.PrometheusMetricSource{
+				MetricQuery: query,
+				Range: &v1alpha1.PrometheusRange{
+					Window:      metav1.Duration{Duration: 5 * time.Minute},
+					Step:        metav1.Duration{Duration: time.Minute},
+					Aggregation: v1alpha1.MaxAggregation,
+				},
+			}}
+		fetchResults, err := fetcher.Fetch(scaleMetric, &FetchContext{})
+
+		Expect(err).To(BeNil(), "no errors on matrix value")
+		Expect(fetchResults).To(Equal([]MetricValue{{Value: 4}, {Value: 1}}), "every series should be reduced, NaN values and empty series dropped")
+	})
+
+	It("Range value - series summed per timestamp", func() {
+		query := "sum by (pod) (rate(requests[1m]))"
+		start := model.TimeFromUnix(1625486400)
+		queryResults[query] = queryResult{
+			Type: model.ValMatrix,
+			Result: model.Matrix{
+				{
+					Metric: model.Metric{"pod": "nginx-1"},
+					Values: []model.SamplePair{{Timestamp: start, Value: 1.5}, {Timestamp: start.Add(time.Minute), Value: 2}},
+				},
+				{
+					Metric: model.Metric{"pod": "nginx-2"},
+					Values: []model.SamplePair{{Timestamp: start.Add(time.Minute), Value: 3}, {Timestamp: start.Add(2 * time.Minute), Value: model.SampleValue(math.NaN())}},
+				},
+			}}
+		scaleMetric := &v1alpha1.ScaleMetric{
+			Prometheus: &v1alpha1.PrometheusMetricSource{
+				MetricQuery: query,
+			}}
+		samples, err := newPrometheusMetricsFetcher(testServer.URL, types.NamespacedName{}, nil, fakeClock).FetchRange(scaleMetric, &FetchContext{}, start.Time(), start.Add(2*time.Minute).Time(), time.Minute)
+
+		Expect(err).To(BeNil(), "no errors on matrix value")
+		Expect(samples).To(Equal([]Sample{
+			{Timestamp: start.Time(), Value: 1.5},
+			{Timestamp: start.Add(time.Minute).Time(), Value: 5},
+		}), "values should be summed per timestamp and NaN values dropped")
+	})
+	Context("Auth secret", func() {
+		query := "count(up)"
+
+		BeforeEach(func() {
+			queryResults[query] = queryResult{
+				Type:   model.ValScalar,
+				Result: model.Scalar{Value: 1, Timestamp: model.Now()},
+			}
+		})
+
+		newSecret := func(namespace string, name string, data map[string]string) *corev1.Secret {
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}, Data: map[string][]byte{}}
+			for key, value := range data {
+				secret.Data[key] = []byte(value)
+			}
+			return secret
+		}
+
+		It("Token and headers of the secret in the namespace of the autoscaler", func() {
+			secretReader := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+				newSecret("team", "prometheus-auth", map[string]string{"token": "secret-token\n", "header.X-Scope-OrgID": "team"}),
+			).Build()
+			scaleMetric := &v1alpha1.ScaleMetric{
+				Prometheus: &v1alpha1.PrometheusMetricSource{
+					MetricQuery:        query,
+					PrometheusEndpoint: testServer.URL,
+					AuthSecretName:     "prometheus-auth",
+				}}
+
+			_, err := newPrometheusMetricsFetcher("", types.NamespacedName{}, secretReader, fakeClock).Fetch(scaleMetric, &FetchContext{Namespace: "team"})
+
+			Expect(err).To(BeNil(), "no errors with a valid secret")
+			Expect(requestHeaders.Get("Authorization")).To(Equal("Bearer secret-token"), "token should be sent as bearer token")
+			Expect(requestHeaders.Get("X-Scope-OrgID")).To(Equal("team"), "headers of the secret should be sent")
+		})
+
+		It("Default secret for the default url, refreshed on change", func() {
+			secret := newSecret("kratos", "prometheus-auth", map[string]string{"username": "kratos", "password": "first"})
+			secretReader := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secret).Build()
+			fetcher := newPrometheusMetricsFetcher(testServer.URL, types.NamespacedName{Namespace: "kratos", Name: "prometheus-auth"}, secretReader, fakeClock)
+			scaleMetric := &v1alpha1.ScaleMetric{
+				Prometheus: &v1alpha1.PrometheusMetricSource{
+					MetricQuery: query,
+				}}
+
+			_, err := fetcher.Fetch(scaleMetric, &FetchContext{Namespace: "team"})
+
+			Expect(err).To(BeNil(), "no errors with the default secret")
+			username, password, _ := (&http.Request{Header: requestHeaders}).BasicAuth()
+			Expect([]string{username, password}).To(Equal([]string{"kratos", "first"}), "default secret should be used for basic auth")
+
+			secret.Data["password"] = []byte("second")
+			Expect(secretReader.Update(context.Background(), secret)).To(Succeed())
+
+			_, err = fetcher.Fetch(scaleMetric, &FetchContext{Namespace: "team"})
+
+			Expect(err).To(BeNil(), "no errors with the changed secret")
+			_, password, _ = (&http.Request{Header: requestHeaders}).BasicAuth()
+			Expect(password).To(Equal("second"), "changed secret should be picked up")
+		})
+
+		It("Default secret not used for other urls", func() {
+			secretReader := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+			fetcher := newPrometheusMetricsFetcher("http://default", types.NamespacedName{Namespace: "kratos", Name: "missing"}, secretReader, fakeClock)
+			scaleMetric := &v1alpha1.ScaleMetric{
+				Prometheus: &v1alpha1.PrometheusMetricSource{
+					MetricQuery:        query,
+					PrometheusEndpoint: testServer.URL,
+				}}
+
+			_, err := fetcher.Fetch(scaleMetric, &FetchContext{Namespace: "team"})
+
+			Expect(err).To(BeNil(), "default secret should not be read for another url")
+			Expect(requestHeaders.Get("Authorization")).To(BeEmpty(), "no credentials should be sent")
+		})
+
+		It("Missing secret", func() {
+			secretReader := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+			scaleMetric := &v1alpha1.ScaleMetric{
+				Prometheus: &v1alpha1.PrometheusMetricSource{
+					MetricQuery:    query,
+					AuthSecretName: "missing",
+				}}
+
+			_, err := newPrometheusMetricsFetcher(testServer.URL, types.NamespacedName{}, secretReader, fakeClock).Fetch(scaleMetric, &FetchContext{Namespace: "team"})
+
+			Expect(err).To(MatchError(ContainSubstring("can't read prometheus auth secret team/missing")), "missing secret should fail the fetch")
+		})
+	})
 })
@@ -0,0 +1,121 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// invalidLabelNameChars matches the characters of Kubernetes label keys which aren't allowed in Prometheus label names
+var invalidLabelNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// queryTemplateData is the data available to query templates
+type queryTemplateData struct {
+	// namespace of the autoscaler
+	Namespace string
+	// kind of the scale target, e.g. Deployment
+	TargetKind string
+	// name of the scale target
+	TargetName string
+	// pod selector of the scale target as PromQL label matchers, e.g. app="nginx",tier=~"web|api"
+	Selector string
+	// current replicas of the scale target
+	Replicas int32
+}
+
+// RenderQuery expands the Go template placeholders of a query with the autoscaler and target of the fetch context.
+// Queries without placeholders are returned unchanged.
+func RenderQuery(query string, fetchContext *FetchContext) (string, error) {
+	if !strings.Contains(query, "{{") {
+		return query, nil
+	}
+
+	queryTemplate, err := template.New("query").Option("missingkey=error").Parse(query)
+	if err != nil {
+		return "", fmt.Errorf("invalid query template: %v", err)
+	}
+
+	selector, err := SelectorToMatchers(fetchContext.Selector)
+	if err != nil {
+		return "", err
+	}
+
+	data := &queryTemplateData{
+		Namespace:  fetchContext.Namespace,
+		TargetKind: fetchContext.Target.Kind,
+		TargetName: fetchContext.Target.Name,
+		Selector:   selector,
+		Replicas:   fetchContext.Replicas,
+	}
+
+	var rendered strings.Builder
+	if err := queryTemplate.Execute(&rendered, data); err != nil {
+		return "", fmt.Errorf("can't render query template: %v", err)
+	}
+
+	return rendered.String(), nil
+}
+
+// SelectorToMatchers converts a label selector to comma separated PromQL label matchers. Label keys are sanitized
+// like Prometheus relabeling does, e.g. app.kubernetes.io/name becomes app_kubernetes_io_name.
+// Numeric comparisons have no PromQL matcher and are rejected.
+func SelectorToMatchers(selector labels.Selector) (string, error) {
+	if selector == nil {
+		return "", nil
+	}
+
+	requirements, _ := selector.Requirements()
+	matchers := make([]string, 0, len(requirements))
+
+	for _, requirement := range requirements {
+		name := invalidLabelNameChars.ReplaceAllString(requirement.Key(), "_")
+		values := requirement.Values().List()
+
+		switch requirement.Operator() {
+		case selection.Equals, selection.DoubleEquals:
+			matchers = append(matchers, fmt.Sprintf("%s=%s", name, strconv.Quote(values[0])))
+		case selection.NotEquals:
+			matchers = append(matchers, fmt.Sprintf("%s!=%s", name, strconv.Quote(values[0])))
+		case selection.In:
+			matchers = append(matchers, fmt.Sprintf("%s=~%s", name, strconv.Quote(valuesRegex(values))))
+		case selection.NotIn:
+			matchers = append(matchers, fmt.Sprintf("%s!~%s", name, strconv.Quote(valuesRegex(values))))
+		case selection.Exists:
+			matchers = append(matchers, fmt.Sprintf("%s!=\"\"", name))
+		case selection.DoesNotExist:
+			matchers = append(matchers, fmt.Sprintf("%s=\"\"", name))
+		default:
+			return "", fmt.Errorf("selector operator %s of label %s has no PromQL matcher", requirement.Operator(), requirement.Key())
+		}
+	}
+
+	return strings.Join(matchers, ","), nil
+}
+
+// valuesRegex matches any of the values, PromQL anchors regular expressions on both ends
+func valuesRegex(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = regexp.QuoteMeta(value)
+	}
+
+	return strings.Join(quoted, "|")
+}
@@ -0,0 +1,79 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package metrics
+
+import (
+	"github.com/adobe/kratos/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+var _ = Describe("QueryTemplate", func() {
+	It("Placeholders expanded", func() {
+		selector, err := labels.Parse("app.kubernetes.io/name=nginx,tier in (api,web)")
+		Expect(err).To(BeNil())
+		fetchContext := &FetchContext{
+			Namespace: "team",
+			Selector:  selector,
+			Target:    v1alpha1.ScaleTargetReference{Kind: "Deployment", Name: "nginx"},
+			Replicas:  3,
+		}
+
+		query, err := RenderQuery(`sum(rate(requests{namespace="{{ .Namespace }}",{{ .Selector }}}[1m])) / {{ .Replicas }} # {{ .TargetKind }}/{{ .TargetName }}`, fetchContext)
+
+		Expect(err).To(BeNil())
+		Expect(query).To(Equal(`sum(rate(requests{namespace="team",app_kubernetes_io_name="nginx",tier=~"api|web"}[1m])) / 3 # Deployment/nginx`))
+	})
+
+	It("Query without placeholders unchanged", func() {
+		query, err := RenderQuery(`sum(up{job="nginx"})`, &FetchContext{})
+
+		Expect(err).To(BeNil())
+		Expect(query).To(Equal(`sum(up{job="nginx"})`))
+	})
+
+	It("Unknown placeholder", func() {
+		_, err := RenderQuery(`sum(up{deployment="{{ .Deployment }}"})`, &FetchContext{Selector: labels.Everything()})
+
+		Expect(err).To(MatchError(ContainSubstring("can't render query template")))
+	})
+
+	DescribeTable("Selector matchers",
+		func(selector string, matchers string) {
+			parsed, err := labels.Parse(selector)
+			Expect(err).To(BeNil())
+
+			result, err := SelectorToMatchers(parsed)
+
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(matchers))
+		},
+		Entry("everything", "", ""),
+		Entry("not equals", "app!=nginx", `app!="nginx"`),
+		Entry("not in with regex characters", "version notin (1.0,2.0)", `version!~"1\\.0|2\\.0"`),
+		Entry("exists", "canary", `canary!=""`),
+		Entry("does not exist", "!canary", `canary=""`),
+	)
+
+	It("Numeric selector rejected", func() {
+		selector, err := labels.Parse("generation>2")
+		Expect(err).To(BeNil())
+
+		_, err = SelectorToMatchers(selector)
+
+		Expect(err).To(MatchError(ContainSubstring("has no PromQL matcher")))
+	})
+})
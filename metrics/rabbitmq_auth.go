@@ -0,0 +1,102 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// keys of the Secret with the credentials and vhost of a RabbitMQ queue, besides the shared TLS keys
+const (
+	rabbitMQUsernameKey = "username"
+	rabbitMQPasswordKey = "password"
+	rabbitMQVhostKey    = "vhost"
+
+	defaultRabbitMQVhost = "/"
+)
+
+// rabbitMQAuth is the content of a RabbitMQ auth Secret
+type rabbitMQAuth struct {
+	username           string
+	password           string
+	vhost              string
+	ca                 []byte
+	cert               []byte
+	key                []byte
+	insecureSkipVerify bool
+}
+
+// newRabbitMQAuth reads and checks the keys of a RabbitMQ auth Secret, unknown keys are rejected to catch typos
+func newRabbitMQAuth(secret *corev1.Secret) (*rabbitMQAuth, error) {
+	auth := &rabbitMQAuth{vhost: defaultRabbitMQVhost}
+
+	for key, value := range secret.Data {
+		switch key {
+		case rabbitMQUsernameKey:
+			auth.username = string(value)
+		case rabbitMQPasswordKey:
+			auth.password = string(value)
+		case rabbitMQVhostKey:
+			auth.vhost = strings.TrimSpace(string(value))
+		case caKey:
+			auth.ca = value
+		case certKey:
+			auth.cert = value
+		case keyKey:
+			auth.key = value
+		case insecureSkipVerifyKey:
+			insecureSkipVerify, err := strconv.ParseBool(strings.TrimSpace(string(value)))
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %v", insecureSkipVerifyKey, err)
+			}
+			auth.insecureSkipVerify = insecureSkipVerify
+		default:
+			return nil, fmt.Errorf("unknown key '%s'", key)
+		}
+	}
+
+	if auth.username == "" {
+		return nil, fmt.Errorf("%s is required", rabbitMQUsernameKey)
+	}
+
+	if auth.vhost == "" {
+		return nil, fmt.Errorf("%s must not be empty", rabbitMQVhostKey)
+	}
+
+	if (len(auth.cert) == 0) != (len(auth.key) == 0) {
+		return nil, fmt.Errorf("%s and %s must be set together", certKey, keyKey)
+	}
+
+	return auth, nil
+}
+
+// transport creates the transport of the management API client with the TLS settings of the Secret
+func (a *rabbitMQAuth) transport() (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if len(a.ca) > 0 || len(a.cert) > 0 || a.insecureSkipVerify {
+		tlsConfig, err := newTLSConfig(a.ca, a.cert, a.key, a.insecureSkipVerify)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return transport, nil
+}
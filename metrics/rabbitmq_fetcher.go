@@ -0,0 +1,206 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/adobe/kratos/cache"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// limits the error body quoted in errors of the management API
+const maxRabbitMQErrorLength = 256
+
+type rabbitMQMetricsFetcher struct {
+	secretReader         client.Reader
+	rabbitMQClientsCache *cache.TTLCache
+	log                  logr.Logger
+}
+
+// rabbitMQClient calls the management API of a RabbitMQ cluster with the credentials of an auth Secret
+type rabbitMQClient struct {
+	httpClient *http.Client
+	transport  *http.Transport
+	username   string
+	password   string
+	vhost      string
+}
+
+func (c *rabbitMQClient) Close() error {
+	c.transport.CloseIdleConnections()
+	return nil
+}
+
+// rabbitMQQueue is the part of a queue of the management API used for scaling
+type rabbitMQQueue struct {
+	Messages      *float64 `json:"messages"`
+	MessagesReady *float64 `json:"messages_ready"`
+	MessageStats  struct {
+		PublishDetails    rabbitMQRate `json:"publish_details"`
+		DeliverGetDetails rabbitMQRate `json:"deliver_get_details"`
+	} `json:"message_stats"`
+}
+
+type rabbitMQRate struct {
+	Rate float64 `json:"rate"`
+}
+
+// newRabbitMQMetricsFetcher creates the fetcher of RabbitMQ queues, auth secrets are read with the secret reader
+func newRabbitMQMetricsFetcher(secretReader client.Reader, clock clock.Clock) *rabbitMQMetricsFetcher {
+	return &rabbitMQMetricsFetcher{
+		secretReader:         secretReader,
+		rabbitMQClientsCache: cache.NewTTLCache("rabbitmq-clients", defaultCacheTtl, clock),
+		log:                  log.Log.WithName("rabbitmq-fetcher"),
+	}
+}
+
+// Fetch returns the depth or a message rate of the queue as a single value. Rates are only reported by the management API
+// once messages went through the queue, a queue without message stats has rates of 0.
+func (r *rabbitMQMetricsFetcher) Fetch(scaleMetric *v1alpha1.ScaleMetric, fetchContext *FetchContext) ([]MetricValue, error) {
+	source := scaleMetric.RabbitMQ
+
+	rabbitMQClient, err := r.getOrCreateClient(source, fetchContext.Namespace)
+
+	if err != nil {
+		return nil, err
+	}
+
+	queue, err := r.getQueue(rabbitMQClient, source)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch source.QueueMetric {
+	case v1alpha1.MessagesRabbitMQQueueMetric, "":
+		if queue.Messages == nil {
+			return nil, fmt.Errorf("no message count of queue %s reported yet", source.Queue)
+		}
+		return []MetricValue{{Value: *queue.Messages}}, nil
+	case v1alpha1.MessagesReadyRabbitMQQueueMetric:
+		if queue.MessagesReady == nil {
+			return nil, fmt.Errorf("no ready message count of queue %s reported yet", source.Queue)
+		}
+		return []MetricValue{{Value: *queue.MessagesReady}}, nil
+	case v1alpha1.PublishRateRabbitMQQueueMetric:
+		value, err := NewMetricValue(queue.MessageStats.PublishDetails.Rate)
+		return []MetricValue{value}, err
+	case v1alpha1.DeliverRateRabbitMQQueueMetric:
+		value, err := NewMetricValue(queue.MessageStats.DeliverGetDetails.Rate)
+		return []MetricValue{value}, err
+	default:
+		return nil, fmt.Errorf("unknown queue metric: %s", source.QueueMetric)
+	}
+}
+
+// getQueue reads the queue from the management API, the vhost of the auth secret is used
+func (r *rabbitMQMetricsFetcher) getQueue(rabbitMQClient *rabbitMQClient, source *v1alpha1.RabbitMQMetricSource) (*rabbitMQQueue, error) {
+	queueUrl := fmt.Sprintf("%s/api/queues/%s/%s", strings.TrimSuffix(source.ManagementUrl, "/"),
+		url.PathEscape(rabbitMQClient.vhost), url.PathEscape(source.Queue))
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultCallTimeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, queueUrl, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	request.SetBasicAuth(rabbitMQClient.username, rabbitMQClient.password)
+	request.Header.Set("Accept", "application/json")
+
+	r.log.V(1).Info("fetching queue", "url", source.ManagementUrl, "vhost", rabbitMQClient.vhost, "queue", source.Queue)
+
+	response, err := rabbitMQClient.httpClient.Do(request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+
+	if err != nil {
+		return nil, fmt.Errorf("can't read queue %s: %v", source.Queue, err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		message := strings.TrimSpace(string(body))
+		if len(message) > maxRabbitMQErrorLength {
+			message = message[:maxRabbitMQErrorLength]
+		}
+		return nil, fmt.Errorf("can't read queue %s in vhost %s: %s: %s", source.Queue, rabbitMQClient.vhost, response.Status, message)
+	}
+
+	queue := &rabbitMQQueue{}
+	if err := json.Unmarshal(body, queue); err != nil {
+		return nil, fmt.Errorf("can't decode queue %s: %v", source.Queue, err)
+	}
+
+	return queue, nil
+}
+
+// getOrCreateClient returns the client of the url and auth secret of the source. Clients are cached by the digest of
+// the secret so that a changed secret is picked up on the next fetch.
+func (r *rabbitMQMetricsFetcher) getOrCreateClient(source *v1alpha1.RabbitMQMetricSource, namespace string) (*rabbitMQClient, error) {
+	secretName := types.NamespacedName{Namespace: namespace, Name: source.AuthSecretName}
+	secret, err := getSecret(r.secretReader, secretName, "rabbitmq auth")
+
+	if err != nil {
+		return nil, err
+	}
+
+	key := fmt.Sprintf("%s|%s|%s", source.ManagementUrl, secretName, secretDigest(secret))
+
+	cachedClient, found := r.rabbitMQClientsCache.Get(key)
+
+	if !found {
+		auth, err := newRabbitMQAuth(secret)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rabbitmq auth secret %s: %v", secretName, err)
+		}
+
+		transport, err := auth.transport()
+		if err != nil {
+			return nil, fmt.Errorf("invalid rabbitmq auth secret %s: %v", secretName, err)
+		}
+
+		rabbitMQClient := &rabbitMQClient{
+			httpClient: &http.Client{Transport: transport, Timeout: defaultCallTimeout},
+			transport:  transport,
+			username:   auth.username,
+			password:   auth.password,
+			vhost:      auth.vhost,
+		}
+
+		r.rabbitMQClientsCache.Put(key, rabbitMQClient)
+		cachedClient = rabbitMQClient
+	}
+
+	return cachedClient.(*rabbitMQClient), nil
+}
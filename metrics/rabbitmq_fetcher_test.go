@@ -0,0 +1,206 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package metrics
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	testingclock "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("RabbitMQFetcher", func() {
+	var testServer *httptest.Server
+	// queues of the fake management API by escaped path, e.g. /api/queues/%2F/orders
+	var queues map[string]string
+	var requestedUser string
+	var secret *corev1.Secret
+	var secretReader client.Client
+	var fetcher *rabbitMQMetricsFetcher
+	fakeClock := testingclock.NewFakeClock(time.Date(2021, 7, 5, 10, 0, 0, 0, time.UTC))
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		user, password, ok := req.BasicAuth()
+		if !ok || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"not_authorised","reason":"Login failed"}`))
+			return
+		}
+		requestedUser = user
+
+		queue, found := queues[req.URL.EscapedPath()]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"Object Not Found","reason":"Not Found"}`))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(queue))
+	})
+
+	newScaleMetric := func(queueMetric v1alpha1.RabbitMQQueueMetric) *v1alpha1.ScaleMetric {
+		return &v1alpha1.ScaleMetric{
+			Type: v1alpha1.RabbitMQScaleMetricType,
+			RabbitMQ: &v1alpha1.RabbitMQMetricSource{
+				ManagementUrl:  testServer.URL,
+				Queue:          "orders",
+				QueueMetric:    queueMetric,
+				AuthSecretName: "rabbitmq",
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		testServer = httptest.NewServer(handler)
+		queues = map[string]string{
+			"/api/queues/%2F/orders": `{"name":"orders","vhost":"/","messages":42,"messages_ready":30,"messages_unacknowledged":12,
+				"message_stats":{"publish_details":{"rate":12.5},"deliver_get_details":{"rate":8.2}}}`,
+		}
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team", Name: "rabbitmq"},
+			Data:       map[string][]byte{"username": []byte("kratos"), "password": []byte("secret")},
+		}
+		secretReader = fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secret).Build()
+		fetcher = newRabbitMQMetricsFetcher(secretReader, fakeClock)
+	})
+
+	AfterEach(func() {
+		testServer.Close()
+	})
+
+	DescribeTable("Queue metrics",
+		func(queueMetric v1alpha1.RabbitMQQueueMetric, expected float64) {
+			values, err := fetcher.Fetch(newScaleMetric(queueMetric), &FetchContext{Namespace: "team"})
+
+			Expect(err).To(BeNil())
+			Expect(values).To(Equal([]MetricValue{{Value: expected}}))
+			Expect(requestedUser).To(Equal("kratos"), "credentials of the secret should be used")
+		},
+		Entry("messages by default", v1alpha1.RabbitMQQueueMetric(""), 42.0),
+		Entry("messages", v1alpha1.MessagesRabbitMQQueueMetric, 42.0),
+		Entry("ready messages", v1alpha1.MessagesReadyRabbitMQQueueMetric, 30.0),
+		Entry("publish rate", v1alpha1.PublishRateRabbitMQQueueMetric, 12.5),
+		Entry("deliver rate", v1alpha1.DeliverRateRabbitMQQueueMetric, 8.2),
+	)
+
+	It("Vhost of the secret", func() {
+		queues["/api/queues/team-a/orders"] = `{"name":"orders","vhost":"team-a","messages":7,"messages_ready":7}`
+		secret.Data["vhost"] = []byte("team-a")
+		Expect(secretReader.Update(context.Background(), secret)).To(Succeed())
+
+		values, err := fetcher.Fetch(newScaleMetric(v1alpha1.MessagesRabbitMQQueueMetric), &FetchContext{Namespace: "team"})
+
+		Expect(err).To(BeNil())
+		Expect(values).To(Equal([]MetricValue{{Value: 7}}), "queue should be read from the vhost of the secret")
+	})
+
+	It("Rates of an idle queue", func() {
+		queues["/api/queues/%2F/orders"] = `{"name":"orders","vhost":"/","messages":0,"messages_ready":0,"message_stats":{}}`
+
+		values, err := fetcher.Fetch(newScaleMetric(v1alpha1.PublishRateRabbitMQQueueMetric), &FetchContext{Namespace: "team"})
+
+		Expect(err).To(BeNil())
+		Expect(values).To(Equal([]MetricValue{{Value: 0}}), "queue without message stats should have no rate")
+	})
+
+	It("Depth not reported yet", func() {
+		queues["/api/queues/%2F/orders"] = `{"name":"orders","vhost":"/"}`
+
+		_, err := fetcher.Fetch(newScaleMetric(v1alpha1.MessagesRabbitMQQueueMetric), &FetchContext{Namespace: "team"})
+
+		Expect(err).To(MatchError(ContainSubstring("no message count of queue orders reported yet")), "missing depth should not be read as an empty queue")
+	})
+
+	It("Unknown queue", func() {
+		delete(queues, "/api/queues/%2F/orders")
+
+		_, err := fetcher.Fetch(newScaleMetric(v1alpha1.MessagesRabbitMQQueueMetric), &FetchContext{Namespace: "team"})
+
+		Expect(err).To(MatchError(ContainSubstring("can't read queue orders in vhost /: 404 Not Found")))
+	})
+
+	It("Changed secret picked up", func() {
+		secret.Data["password"] = []byte("wrong")
+		Expect(secretReader.Update(context.Background(), secret)).To(Succeed())
+
+		_, err := fetcher.Fetch(newScaleMetric(v1alpha1.MessagesRabbitMQQueueMetric), &FetchContext{Namespace: "team"})
+		Expect(err).To(MatchError(ContainSubstring("401 Unauthorized")))
+
+		secret.Data["password"] = []byte("secret")
+		Expect(secretReader.Update(context.Background(), secret)).To(Succeed())
+
+		_, err = fetcher.Fetch(newScaleMetric(v1alpha1.MessagesRabbitMQQueueMetric), &FetchContext{Namespace: "team"})
+		Expect(err).To(BeNil(), "changed secret should be picked up")
+	})
+
+	It("Missing secret", func() {
+		scaleMetric := newScaleMetric(v1alpha1.MessagesRabbitMQQueueMetric)
+		scaleMetric.RabbitMQ.AuthSecretName = "missing"
+
+		_, err := fetcher.Fetch(scaleMetric, &FetchContext{Namespace: "team"})
+
+		Expect(err).To(MatchError(ContainSubstring("can't read rabbitmq auth secret team/missing")), "missing secret should fail the fetch")
+	})
+
+	It("Management API with TLS", func() {
+		tlsServer := httptest.NewTLSServer(handler)
+		defer tlsServer.Close()
+
+		secret.Data["ca.crt"] = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tlsServer.Certificate().Raw})
+		Expect(secretReader.Update(context.Background(), secret)).To(Succeed())
+		scaleMetric := newScaleMetric(v1alpha1.MessagesRabbitMQQueueMetric)
+		scaleMetric.RabbitMQ.ManagementUrl = tlsServer.URL + "/"
+
+		values, err := fetcher.Fetch(scaleMetric, &FetchContext{Namespace: "team"})
+
+		Expect(err).To(BeNil(), "server certificate should be trusted with the CA of the secret")
+		Expect(values).To(Equal([]MetricValue{{Value: 42}}))
+	})
+})
+
+var _ = Describe("RabbitMQAuth", func() {
+	DescribeTable("Invalid secrets",
+		func(data map[string]string, message string) {
+			secret := &corev1.Secret{Data: map[string][]byte{}}
+			for key, value := range data {
+				secret.Data[key] = []byte(value)
+			}
+
+			auth, err := newRabbitMQAuth(secret)
+			if err == nil {
+				_, err = auth.transport()
+			}
+
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("missing username", map[string]string{"password": "p"}, "username is required"),
+		Entry("empty vhost", map[string]string{"username": "u", "vhost": " "}, "vhost must not be empty"),
+		Entry("unknown key", map[string]string{"username": "u", "virtualHost": "team-a"}, "unknown key 'virtualHost'"),
+		Entry("certificate without key", map[string]string{"username": "u", "tls.crt": "c"}, "must be set together"),
+		Entry("invalid CA", map[string]string{"username": "u", "ca.crt": "not a certificate"}, "no PEM certificate found in ca.crt"),
+	)
+})
@@ -24,7 +24,6 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	// "k8s.io/client-go/util/retry"
-	"k8s.io/apimachinery/pkg/labels"
 	clientset "k8s.io/metrics/pkg/client/clientset/versioned"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -43,41 +42,41 @@ func newResourceMetricsFetcher(metricsClient clientset.Interface) *resourceMetri
 	return fetcher
 }
 
-func (r *resourceMetricsFetcher) Fetch(scaleMetric *v1alpha1.ScaleMetric, namespace string, selector labels.Selector) ([]MetricValue, error) {
+func (r *resourceMetricsFetcher) Fetch(scaleMetric *v1alpha1.ScaleMetric, fetchContext *FetchContext) ([]MetricValue, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), defaultCallTimeout)
 	defer cancel()
 
 	opts := metav1.ListOptions{
-		LabelSelector: selector.String(),
+		LabelSelector: fetchContext.Selector.String(),
 	}
 
-	podMetricsList, err := r.metricsClient.MetricsV1beta1().PodMetricses(namespace).List(ctx, opts)
+	podMetricsList, err := r.metricsClient.MetricsV1beta1().PodMetricses(fetchContext.Namespace).List(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	r.log.Info("Fetched resource metrics", "selector", selector.String(), "container", scaleMetric.Resource.Container, "metrics", podMetricsList.Items)
+	r.log.Info("Fetched resource metrics", "selector", fetchContext.Selector.String(), "container", scaleMetric.Resource.Container, "metrics", podMetricsList.Items)
 
 	ret := []MetricValue{}
 
 	for _, podMetrics := range podMetricsList.Items {
-		var accum int64 = 0
+		accum := float64(0)
 		found := false
 		for _, container := range podMetrics.Containers {
 			if scaleMetric.Resource.Container == "" || scaleMetric.Resource.Container == container.Name {
 				found = true
 				switch scaleMetric.Resource.Name {
 				case corev1.ResourceCPU:
-					accum += container.Usage.Cpu().Value()
+					accum += QuantityValue(container.Usage.Cpu())
 				case corev1.ResourceMemory:
-					accum += container.Usage.Memory().Value()
+					accum += QuantityValue(container.Usage.Memory())
 				default:
 					return nil, fmt.Errorf("Unsuported resource type %s", scaleMetric.Resource.Name)
 				}
 			}
 		}
 		if !found {
-			return nil, fmt.Errorf("container %s not present in metrics for pod %s/%s", scaleMetric.Resource.Container, namespace, podMetrics.Name)
+			return nil, fmt.Errorf("container %s not present in metrics for pod %s/%s", scaleMetric.Resource.Container, fetchContext.Namespace, podMetrics.Name)
 		}
 		ret = append(ret, MetricValue{accum})
 	}
@@ -50,8 +50,8 @@ func makePodMetric(id int, labelSet map[string]string) metricsapi.PodMetrics {
 			{
 				Name: podNamePrefix,
 				Usage: v1.ResourceList{
-					v1.ResourceCPU: *resource.NewQuantity(
-						int64(id),
+					v1.ResourceCPU: *resource.NewMilliQuantity(
+						int64(id*100),
 						resource.DecimalSI),
 					v1.ResourceMemory: *resource.NewQuantity(
 						int64(id*1024*1024),
@@ -93,7 +93,7 @@ var _ = Describe("ResourceFetcher", func() {
 		selector := labels.SelectorFromSet(labels.Set{
 			"app": "nonexistent",
 		})
-		res, err := fetcher.Fetch(scaleMetric, namespace, selector)
+		res, err := fetcher.Fetch(scaleMetric, &FetchContext{Namespace: namespace, Selector: selector})
 
 		Expect(res, err).To(BeEmpty(), "get no metrics with Nothing label selector")
 	})
@@ -105,7 +105,7 @@ var _ = Describe("ResourceFetcher", func() {
 				Name: corev1.ResourceCPU,
 			},
 		}
-		res, err := fetcher.Fetch(scaleMetric, namespace, labels.Everything())
+		res, err := fetcher.Fetch(scaleMetric, &FetchContext{Namespace: namespace, Selector: labels.Everything()})
 
 		Expect(res, err).NotTo(BeEmpty(), "get all namespaced metrics should return result")
 	})
@@ -117,7 +117,7 @@ var _ = Describe("ResourceFetcher", func() {
 				Name: corev1.ResourceCPU,
 			},
 		}
-		res, err := fetcher.Fetch(scaleMetric, namespace, labels.Everything())
+		res, err := fetcher.Fetch(scaleMetric, &FetchContext{Namespace: namespace, Selector: labels.Everything()})
 
 		Expect(res, err).NotTo(BeEmpty(), "get all namespaced metrics should return result")
 	})
@@ -128,7 +128,7 @@ var _ = Describe("ResourceFetcher", func() {
 			Resource: &v1alpha1.ResourceMetricSource{
 				Name: corev1.ResourceMemory,
 			}}
-		res, err := fetcher.Fetch(scaleMetric, namespace, labels.Everything())
+		res, err := fetcher.Fetch(scaleMetric, &FetchContext{Namespace: namespace, Selector: labels.Everything()})
 
 		Expect(res, err).NotTo(BeEmpty(), "get all namespaced metrics should return result")
 	})
@@ -142,9 +142,23 @@ var _ = Describe("ResourceFetcher", func() {
 		selector := labels.SelectorFromSet(labels.Set{
 			"state": "backup",
 		})
-		res, err := fetcher.Fetch(scaleMetric, namespace, selector)
+		res, err := fetcher.Fetch(scaleMetric, &FetchContext{Namespace: namespace, Selector: selector})
 
 		Expect(res, err).NotTo(BeEmpty(), "get metrics with selector should return result")
 		Expect(len(res)).To(Equal(int(numFakePods)), "get the expected number of metrics")
 	})
+	It("Sub-core CPU resource", func() {
+		scaleMetric := &v1alpha1.ScaleMetric{
+			Type: v1alpha1.ResourceScaleMetricType,
+			Resource: &v1alpha1.ResourceMetricSource{
+				Name: corev1.ResourceCPU,
+			}}
+		selector := labels.SelectorFromSet(labels.Set{
+			"state": "backup",
+		})
+		res, err := fetcher.Fetch(scaleMetric, &FetchContext{Namespace: namespace, Selector: selector})
+
+		Expect(err).To(BeNil())
+		Expect(res[0].Value).To(BeNumerically("~", 1.1), "cpu usage should keep milli precision")
+	})
 })
@@ -0,0 +1,94 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package metrics
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// keys of the TLS settings shared by the auth Secrets of all metric sources
+const (
+	caKey                 = "ca.crt"
+	certKey               = "tls.crt"
+	keyKey                = "tls.key"
+	insecureSkipVerifyKey = "insecureSkipVerify"
+)
+
+// getSecret reads the auth secret of a metric source, the kind of the secret is part of the errors, e.g. 'prometheus auth'
+func getSecret(secretReader client.Reader, name types.NamespacedName, kind string) (*corev1.Secret, error) {
+	if secretReader == nil {
+		return nil, fmt.Errorf("can't read %s secret %s: no secret reader", kind, name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultCallTimeout)
+	defer cancel()
+
+	secret := &corev1.Secret{}
+	if err := secretReader.Get(ctx, name, secret); err != nil {
+		return nil, fmt.Errorf("can't read %s secret %s: %v", kind, name, err)
+	}
+
+	return secret, nil
+}
+
+// newTLSConfig creates the TLS settings of a client from PEM encoded certificates, the CA is added to the root CAs
+// and the certificate and key are used as client certificate when set
+func newTLSConfig(ca []byte, cert []byte, key []byte, insecureSkipVerify bool) (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: insecureSkipVerify}
+
+	if len(ca) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no PEM certificate found in %s", caKey)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if len(cert) > 0 {
+		certificate, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return tlsConfig, nil
+}
+
+// secretDigest hashes the data of a Secret, clients are cached by it so that they're replaced when the Secret changes
+func secretDigest(secret *corev1.Secret) string {
+	keys := make([]string, 0, len(secret.Data))
+	for key := range secret.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	for _, key := range keys {
+		fmt.Fprintf(hash, "%d:%s%d:", len(key), key, len(secret.Data[key]))
+		hash.Write(secret.Data[key])
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))[:16]
+}
@@ -0,0 +1,304 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package monitoring
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	metricsNamespace = "kratos"
+
+	kindLabel       = "kind"
+	namespaceLabel  = "namespace"
+	nameLabel       = "name"
+	targetLabel     = "target"
+	metricTypeLabel = "metric_type"
+	metricLabel     = "metric"
+	backendLabel    = "backend"
+	directionLabel  = "direction"
+	reasonLabel     = "reason"
+
+	ScaleUpDirection   = "up"
+	ScaleDownDirection = "down"
+)
+
+var autoscalerLabels = []string{kindLabel, namespaceLabel, nameLabel, targetLabel}
+
+var (
+	currentReplicas = newAutoscalerGauge("current_replicas", "Current replicas of the scale target.")
+	desiredReplicas = newAutoscalerGauge("desired_replicas", "Desired replicas set on the scale target by the last evaluation.")
+	minReplicas     = newAutoscalerGauge("min_replicas", "Lower limit of replicas for the scale target.")
+	maxReplicas     = newAutoscalerGauge("max_replicas", "Upper limit of replicas for the scale target.")
+
+	metricValue = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "metric_value",
+		Help:      "Value fetched for an autoscaler metric, summed over all returned values.",
+	}, append(autoscalerLabels, metricTypeLabel, metricLabel))
+
+	metricProposal = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "metric_replicas_proposal",
+		Help:      "Replicas proposed by the scaling algorithm for an autoscaler metric.",
+	}, append(autoscalerLabels, metricTypeLabel, metricLabel))
+
+	scaleOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "scale_operations_total",
+		Help:      "Number of successful scale operations by direction.",
+	}, append(autoscalerLabels, directionLabel))
+
+	dryRunScaleOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "dry_run_scale_operations_total",
+		Help:      "Number of scale operations skipped in dry run mode by direction.",
+	}, append(autoscalerLabels, directionLabel))
+
+	scalingLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "scaling_limited_total",
+		Help:      "Number of evaluations where the desired replicas were limited, by reason.",
+	}, append(autoscalerLabels, reasonLabel))
+
+	fetchDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "metric_fetch_duration_seconds",
+		Help:      "Latency of fetching metrics by metric backend.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{backendLabel})
+
+	fetchErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "metric_fetch_errors_total",
+		Help:      "Number of failed metric fetches by metric backend.",
+	}, []string{backendLabel})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		currentReplicas,
+		desiredReplicas,
+		minReplicas,
+		maxReplicas,
+		metricValue,
+		metricProposal,
+		scaleOperations,
+		dryRunScaleOperations,
+		scalingLimited,
+		fetchDuration,
+		fetchErrors,
+	)
+}
+
+func newAutoscalerGauge(name string, help string) *prometheus.GaugeVec {
+	return prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      name,
+		Help:      help,
+	}, autoscalerLabels)
+}
+
+// MetricKey identifies the series of a metric of an autoscaler by metric type and name
+type MetricKey struct {
+	Type string
+	Name string
+}
+
+// autoscalerKey identifies an autoscaler definition, Kratos resources and ConfigMaps may share names
+type autoscalerKey struct {
+	kind string
+	name types.NamespacedName
+}
+
+// series keeps the label values exported for an autoscaler so they can be deleted when it goes away
+type series struct {
+	target     string
+	metrics    map[MetricKey]struct{}
+	directions map[string]struct{}
+	reasons    map[string]struct{}
+}
+
+var (
+	lock       sync.Mutex
+	autoscaler = make(map[autoscalerKey]*series)
+)
+
+// Recorder exports the metrics of a single autoscaler definition
+type Recorder struct {
+	key    autoscalerKey
+	target string
+}
+
+// ForAutoscaler returns the recorder of an autoscaler of a kind, i.e. Kratos or ConfigMap, series of a previous scale target are deleted
+func ForAutoscaler(kind string, namespace string, name string, target string) *Recorder {
+	recorder := &Recorder{key: autoscalerKey{kind: kind, name: types.NamespacedName{Namespace: namespace, Name: name}}, target: target}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	if existing, found := autoscaler[recorder.key]; found && existing.target != target {
+		deleteSeries(recorder.key, existing)
+		delete(autoscaler, recorder.key)
+	}
+
+	return recorder
+}
+
+// RecordReplicas exports the replicas of the scale target and its limits
+func (r *Recorder) RecordReplicas(current int32, desired int32, min int32, max int32) {
+	r.track(func(*series) {})
+
+	currentReplicas.WithLabelValues(r.labels()...).Set(float64(current))
+	desiredReplicas.WithLabelValues(r.labels()...).Set(float64(desired))
+	minReplicas.WithLabelValues(r.labels()...).Set(float64(min))
+	maxReplicas.WithLabelValues(r.labels()...).Set(float64(max))
+}
+
+// RecordMetricValue exports the fetched value of a metric
+func (r *Recorder) RecordMetricValue(metricType string, metric string, value float64) {
+	r.track(func(s *series) { s.metrics[MetricKey{Type: metricType, Name: metric}] = struct{}{} })
+
+	metricValue.WithLabelValues(append(r.labels(), metricType, metric)...).Set(value)
+}
+
+// RecordMetricProposal exports the replicas proposed for a metric
+func (r *Recorder) RecordMetricProposal(metricType string, metric string, replicas int32) {
+	r.track(func(s *series) { s.metrics[MetricKey{Type: metricType, Name: metric}] = struct{}{} })
+
+	metricProposal.WithLabelValues(append(r.labels(), metricType, metric)...).Set(float64(replicas))
+}
+
+// RetainMetrics deletes the series of metrics which are no longer part of the spec of the autoscaler
+func (r *Recorder) RetainMetrics(current []MetricKey) {
+	retained := make(map[MetricKey]struct{}, len(current))
+	for _, metric := range current {
+		retained[metric] = struct{}{}
+	}
+
+	r.track(func(s *series) {
+		for metric := range s.metrics {
+			if _, found := retained[metric]; !found {
+				metricValue.DeleteLabelValues(append(r.labels(), metric.Type, metric.Name)...)
+				metricProposal.DeleteLabelValues(append(r.labels(), metric.Type, metric.Name)...)
+				delete(s.metrics, metric)
+			}
+		}
+	})
+}
+
+// RecordScale counts a scale operation of the target from one replica count to another
+func (r *Recorder) RecordScale(from int32, to int32) {
+	direction := scaleDirection(from, to)
+
+	r.track(func(s *series) { s.directions[direction] = struct{}{} })
+
+	scaleOperations.WithLabelValues(append(r.labels(), direction)...).Inc()
+}
+
+// RecordDryRunScale counts a scale operation which was calculated but not applied
+func (r *Recorder) RecordDryRunScale(from int32, to int32) {
+	direction := scaleDirection(from, to)
+
+	r.track(func(s *series) { s.directions[direction] = struct{}{} })
+
+	dryRunScaleOperations.WithLabelValues(append(r.labels(), direction)...).Inc()
+}
+
+// RecordLimited counts an evaluation where the desired replicas were limited
+func (r *Recorder) RecordLimited(reason string) {
+	r.track(func(s *series) { s.reasons[reason] = struct{}{} })
+
+	scalingLimited.WithLabelValues(append(r.labels(), reason)...).Inc()
+}
+
+// ObserveFetch records the latency and the outcome of fetching a metric from a backend
+func ObserveFetch(backend string, duration time.Duration, err error) {
+	fetchDuration.WithLabelValues(backend).Observe(duration.Seconds())
+
+	if err != nil {
+		fetchErrors.WithLabelValues(backend).Inc()
+	}
+}
+
+// Forget deletes all series of an autoscaler of a kind, it's called once the autoscaler definition is deleted
+func Forget(kind string, namespace string, name string) {
+	key := autoscalerKey{kind: kind, name: types.NamespacedName{Namespace: namespace, Name: name}}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	if existing, found := autoscaler[key]; found {
+		deleteSeries(key, existing)
+		delete(autoscaler, key)
+	}
+}
+
+func scaleDirection(from int32, to int32) string {
+	if to < from {
+		return ScaleDownDirection
+	}
+
+	return ScaleUpDirection
+}
+
+func (r *Recorder) labels() []string {
+	return []string{r.key.kind, r.key.name.Namespace, r.key.name.Name, r.target}
+}
+
+func (r *Recorder) track(update func(*series)) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	existing, found := autoscaler[r.key]
+	if !found {
+		existing = &series{
+			target:     r.target,
+			metrics:    make(map[MetricKey]struct{}),
+			directions: make(map[string]struct{}),
+			reasons:    make(map[string]struct{}),
+		}
+		autoscaler[r.key] = existing
+	}
+
+	update(existing)
+}
+
+func deleteSeries(key autoscalerKey, s *series) {
+	labels := []string{key.kind, key.name.Namespace, key.name.Name, s.target}
+
+	currentReplicas.DeleteLabelValues(labels...)
+	desiredReplicas.DeleteLabelValues(labels...)
+	minReplicas.DeleteLabelValues(labels...)
+	maxReplicas.DeleteLabelValues(labels...)
+
+	for metric := range s.metrics {
+		metricValue.DeleteLabelValues(append(labels, metric.Type, metric.Name)...)
+		metricProposal.DeleteLabelValues(append(labels, metric.Type, metric.Name)...)
+	}
+
+	for direction := range s.directions {
+		scaleOperations.DeleteLabelValues(append(labels, direction)...)
+		dryRunScaleOperations.DeleteLabelValues(append(labels, direction)...)
+	}
+
+	for reason := range s.reasons {
+		scalingLimited.DeleteLabelValues(append(labels, reason)...)
+	}
+}
@@ -0,0 +1,127 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package monitoring
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var _ = Describe("Monitoring", func() {
+
+	It("Record autoscaler metrics", func() {
+		recorder := ForAutoscaler("Kratos", "default", "record", "Deployment/nginx")
+		defer Forget("Kratos", "default", "record")
+
+		recorder.RecordReplicas(2, 4, 1, 10)
+		recorder.RecordMetricValue("Prometheus", "sum(rate(requests[1m]))", 12.5)
+		recorder.RecordMetricProposal("Prometheus", "sum(rate(requests[1m]))", 4)
+		recorder.RecordScale(2, 4)
+		recorder.RecordLimited("ScaleUpLimit")
+
+		labels := []string{"Kratos", "default", "record", "Deployment/nginx"}
+		Expect(testutil.ToFloat64(currentReplicas.WithLabelValues(labels...))).To(Equal(float64(2)))
+		Expect(testutil.ToFloat64(desiredReplicas.WithLabelValues(labels...))).To(Equal(float64(4)))
+		Expect(testutil.ToFloat64(minReplicas.WithLabelValues(labels...))).To(Equal(float64(1)))
+		Expect(testutil.ToFloat64(maxReplicas.WithLabelValues(labels...))).To(Equal(float64(10)))
+		Expect(testutil.ToFloat64(metricValue.WithLabelValues(append(labels, "Prometheus", "sum(rate(requests[1m]))")...))).To(Equal(12.5))
+		Expect(testutil.ToFloat64(metricProposal.WithLabelValues(append(labels, "Prometheus", "sum(rate(requests[1m]))")...))).To(Equal(float64(4)))
+		Expect(testutil.ToFloat64(scaleOperations.WithLabelValues(append(labels, ScaleUpDirection)...))).To(Equal(float64(1)))
+		Expect(testutil.ToFloat64(scalingLimited.WithLabelValues(append(labels, "ScaleUpLimit")...))).To(Equal(float64(1)))
+	})
+
+	It("Scale direction", func() {
+		recorder := ForAutoscaler("Kratos", "default", "direction", "Deployment/nginx")
+		defer Forget("Kratos", "default", "direction")
+
+		recorder.RecordScale(5, 3)
+
+		Expect(testutil.ToFloat64(scaleOperations.WithLabelValues("Kratos", "default", "direction", "Deployment/nginx", ScaleDownDirection))).To(Equal(float64(1)))
+	})
+
+	It("Dry run scale", func() {
+		recorder := ForAutoscaler("Kratos", "default", "dry-run", "Deployment/nginx")
+		recorder.RecordDryRunScale(2, 6)
+
+		Expect(testutil.ToFloat64(dryRunScaleOperations.WithLabelValues("Kratos", "default", "dry-run", "Deployment/nginx", ScaleUpDirection))).To(Equal(float64(1)))
+		Expect(testutil.CollectAndCount(scaleOperations)).To(Equal(0), "skipped scale operations should not be counted as applied")
+
+		Forget("Kratos", "default", "dry-run")
+		Expect(testutil.CollectAndCount(dryRunScaleOperations)).To(Equal(0))
+	})
+
+	It("Forget autoscaler", func() {
+		recorder := ForAutoscaler("Kratos", "default", "forget", "Deployment/nginx")
+		recorder.RecordReplicas(2, 4, 1, 10)
+		recorder.RecordMetricValue("Resource", "cpu", 0.5)
+		recorder.RecordScale(2, 4)
+
+		before := testutil.CollectAndCount(desiredReplicas)
+
+		Forget("Kratos", "default", "forget")
+
+		Expect(testutil.CollectAndCount(desiredReplicas)).To(Equal(before-1), "forgotten autoscaler should not be exported")
+		Expect(testutil.CollectAndCount(metricValue)).To(Equal(0))
+		Expect(testutil.CollectAndCount(scaleOperations)).To(Equal(0))
+	})
+
+	It("Changed scale target", func() {
+		ForAutoscaler("Kratos", "default", "retarget", "Deployment/nginx").RecordReplicas(2, 2, 1, 10)
+		ForAutoscaler("Kratos", "default", "retarget", "StatefulSet/nginx").RecordReplicas(3, 3, 1, 10)
+		defer Forget("Kratos", "default", "retarget")
+
+		Expect(testutil.CollectAndCount(currentReplicas)).To(Equal(1), "series of the previous target should be deleted")
+		Expect(testutil.ToFloat64(currentReplicas.WithLabelValues("Kratos", "default", "retarget", "StatefulSet/nginx"))).To(Equal(float64(3)))
+	})
+
+	It("Autoscalers of different kinds with the same name", func() {
+		ForAutoscaler("Kratos", "default", "shared", "Deployment/nginx").RecordReplicas(2, 2, 1, 10)
+		ForAutoscaler("ConfigMap", "default", "shared", "Deployment/nginx").RecordReplicas(5, 5, 1, 10)
+		defer Forget("ConfigMap", "default", "shared")
+
+		Expect(testutil.ToFloat64(currentReplicas.WithLabelValues("Kratos", "default", "shared", "Deployment/nginx"))).To(Equal(float64(2)))
+		Expect(testutil.ToFloat64(currentReplicas.WithLabelValues("ConfigMap", "default", "shared", "Deployment/nginx"))).To(Equal(float64(5)))
+
+		Forget("Kratos", "default", "shared")
+
+		Expect(testutil.CollectAndCount(currentReplicas)).To(Equal(1), "only the series of the forgotten kind should be deleted")
+	})
+
+	It("Metric removed from spec", func() {
+		recorder := ForAutoscaler("Kratos", "default", "removed", "Deployment/nginx")
+		defer Forget("Kratos", "default", "removed")
+
+		recorder.RecordMetricValue("Resource", "cpu", 0.5)
+		recorder.RecordMetricProposal("Resource", "cpu", 2)
+		recorder.RecordMetricValue("Prometheus", "sum(rate(requests[1m]))", 12.5)
+		recorder.RecordMetricProposal("Prometheus", "sum(rate(requests[1m]))", 4)
+
+		recorder.RetainMetrics([]MetricKey{{Type: "Prometheus", Name: "sum(rate(requests[1m]))"}})
+
+		Expect(testutil.CollectAndCount(metricValue)).To(Equal(1), "series of the removed metric should be deleted")
+		Expect(testutil.CollectAndCount(metricProposal)).To(Equal(1))
+		Expect(testutil.ToFloat64(metricValue.WithLabelValues("Kratos", "default", "removed", "Deployment/nginx", "Prometheus", "sum(rate(requests[1m]))"))).To(Equal(12.5))
+	})
+
+	It("Fetch errors by backend", func() {
+		ObserveFetch("External", time.Millisecond, nil)
+		ObserveFetch("External", time.Millisecond, errors.New("unavailable"))
+
+		Expect(testutil.ToFloat64(fetchErrors.WithLabelValues("External"))).To(Equal(float64(1)))
+	})
+})
@@ -19,42 +19,69 @@ import (
 
 	"github.com/adobe/kratos/api/common"
 	"github.com/adobe/kratos/api/v1alpha1"
+	"k8s.io/utils/clock"
 )
 
 type behaviorNormalizer struct {
+	clock clock.Clock
 }
 
-func newBehaviorNormalizer() *behaviorNormalizer {
-	return &behaviorNormalizer{}
+func newBehaviorNormalizer(clock clock.Clock) *behaviorNormalizer {
+	return &behaviorNormalizer{clock: clock}
 }
 
-func (n *behaviorNormalizer) normalizeReplicas(spec *v1alpha1.KratosSpec, status *v1alpha1.KratosStatus, desiredReplicas int32) int32 {
+func (n *behaviorNormalizer) normalizeReplicas(spec *v1alpha1.KratosSpec, status *v1alpha1.KratosStatus, desiredReplicas int32) Normalization {
 	maxRecommendation := n.findMaxRecommendation(spec, status, desiredReplicas)
+	result := Normalization{Replicas: maxRecommendation, Reason: NotLimited, StabilizedReplicas: maxRecommendation}
+
+	if maxRecommendation != desiredReplicas {
+		result.Reason = StabilizationLimit
+	}
 
 	if maxRecommendation == status.CurrentReplicas {
-		return maxRecommendation
+		return result
 	}
 
 	if maxRecommendation > status.CurrentReplicas && spec.Behavior.ScaleUp.SelectPolicy == v1alpha1.DisabledPolicySelect {
-		return status.CurrentReplicas
+		result.Replicas, result.Reason = status.CurrentReplicas, ScaleUpDisabled
+		return result
 	}
 
 	if maxRecommendation < status.CurrentReplicas && spec.Behavior.ScaleDown.SelectPolicy == v1alpha1.DisabledPolicySelect {
-		return status.CurrentReplicas
+		result.Replicas, result.Reason = status.CurrentReplicas, ScaleDownDisabled
+		return result
 	}
 
 	if maxRecommendation > status.CurrentReplicas {
 
-		scaleUpLimit := n.calculateScaleUpLimit(spec.Behavior.ScaleUp, status.ScaleUpEvents, status.CurrentReplicas)
-		return n.min(common.Max(scaleUpLimit, status.CurrentReplicas), spec.MaxReplicas, maxRecommendation)
+		scaleUpLimit, policy := n.calculateScaleUpLimit(spec.Behavior.ScaleUp, status.ScaleUpEvents, status.CurrentReplicas)
+		result.Replicas = n.min(common.Max(scaleUpLimit, status.CurrentReplicas), spec.MaxReplicas, maxRecommendation)
+
+		switch {
+		case result.Replicas == maxRecommendation:
+		case result.Replicas == spec.MaxReplicas:
+			result.Reason = MaxReplicasLimit
+		default:
+			result.Reason = ScaleUpLimit
+			result.Limit = policy
+		}
 
 	} else if maxRecommendation < status.CurrentReplicas {
 
-		scaleDownLimit := n.calculateScaleDownLimit(spec.Behavior.ScaleDown, status.ScaleDownEvents, status.CurrentReplicas)
-		return n.max(common.Min(scaleDownLimit, status.CurrentReplicas), spec.MinReplicas, maxRecommendation)
+		scaleDownLimit, policy := n.calculateScaleDownLimit(spec.Behavior.ScaleDown, status.ScaleDownEvents, status.CurrentReplicas)
+		result.Replicas = n.max(common.Min(scaleDownLimit, status.CurrentReplicas), spec.GetMinReplicas(), maxRecommendation)
+
+		switch {
+		case result.Replicas == maxRecommendation:
+		case result.Replicas == spec.GetMinReplicas():
+			result.Reason = MinReplicasLimit
+		default:
+			result.Reason = ScaleDownLimit
+			result.Limit = policy
+		}
 	}
 
-	return maxRecommendation
+	return result
 }
 
 func (n *behaviorNormalizer) findMaxRecommendation(spec *v1alpha1.KratosSpec, status *v1alpha1.KratosStatus, desiredReplicas int32) int32 {
@@ -73,7 +100,7 @@ func (n *behaviorNormalizer) findMaxRecommendation(spec *v1alpha1.KratosSpec, st
 		recommendationsComparator = common.Max
 	}
 
-	cutOff := time.Now().Add(time.Duration(-stabilizationWondowsSeconds))
+	cutOff := n.clock.Now().Add(-time.Duration(stabilizationWondowsSeconds) * time.Second)
 	maxRecomendation := desiredReplicas
 
 	for _, recommendation := range status.Recommendations {
@@ -85,9 +112,11 @@ func (n *behaviorNormalizer) findMaxRecommendation(spec *v1alpha1.KratosSpec, st
 	return maxRecomendation
 }
 
-func (n *behaviorNormalizer) calculateScaleUpLimit(selectRules *v1alpha1.ScaleRules, scaleUpEvents []v1alpha1.ScaleChangeEvent, currentReplicas int32) int32 {
+// calculateScaleUpLimit returns the replicas allowed by the selected scale up policy and its description
+func (n *behaviorNormalizer) calculateScaleUpLimit(selectRules *v1alpha1.ScaleRules, scaleUpEvents []v1alpha1.ScaleChangeEvent, currentReplicas int32) (int32, string) {
 	var result int32 = math.MinInt32
 	var proposed int32
+	var selected string
 
 	scaleUpSelectFunc := common.Max
 	if selectRules.SelectPolicy == v1alpha1.MinPolicySelect {
@@ -104,15 +133,20 @@ func (n *behaviorNormalizer) calculateScaleUpLimit(selectRules *v1alpha1.ScaleRu
 			// the proposal has to be rounded up because the proposed change might not increase the replica count causing the target to never scale up
 			proposed = int32(math.Ceil(float64(windowStartReplicas) * (1 + float64(policy.Value)/100)))
 		}
+		if selected == "" || scaleUpSelectFunc(result, proposed) != result {
+			selected = describePolicy(policy)
+		}
 		result = scaleUpSelectFunc(result, proposed)
 	}
 
-	return result
+	return result, selected
 }
 
-func (n *behaviorNormalizer) calculateScaleDownLimit(scaleRules *v1alpha1.ScaleRules, scaleDownEvents []v1alpha1.ScaleChangeEvent, currentReplicas int32) int32 {
+// calculateScaleDownLimit returns the replicas allowed by the selected scale down policy and its description
+func (n *behaviorNormalizer) calculateScaleDownLimit(scaleRules *v1alpha1.ScaleRules, scaleDownEvents []v1alpha1.ScaleChangeEvent, currentReplicas int32) (int32, string) {
 	var result int32 = math.MaxInt32
 	var proposed int32
+	var selected string
 
 	scaleDownSelectFunc := common.Min
 
@@ -129,15 +163,18 @@ func (n *behaviorNormalizer) calculateScaleDownLimit(scaleRules *v1alpha1.ScaleR
 		} else if policy.Type == v1alpha1.PercentScalingPolicy {
 			proposed = int32(float64(windowStartReplicas) * (1 - float64(policy.Value)/100))
 		}
+		if selected == "" || scaleDownSelectFunc(result, proposed) != result {
+			selected = describePolicy(policy)
+		}
 		result = scaleDownSelectFunc(result, proposed)
 	}
-	return result
+	return result, selected
 
 }
 
 func (n *behaviorNormalizer) getReplicasChange(windowSeconds int32, scaleEvents []v1alpha1.ScaleChangeEvent) int32 {
 	windowAsDuration := time.Second * time.Duration(windowSeconds)
-	cutOff := time.Now().Add(-windowAsDuration)
+	cutOff := n.clock.Now().Add(-windowAsDuration)
 
 	totalReplicasChange := int32(0)
 
@@ -14,11 +14,14 @@ written permission of Adobe.
 package normalizer
 
 import (
+	"time"
+
 	"github.com/adobe/kratos/api/v1alpha1"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 )
 
 var _ = Describe("BehaviorNormalizer", func() {
@@ -117,11 +120,83 @@ var _ = Describe("BehaviorNormalizer", func() {
 		Entry("scale up by 10 pods from current value", 1, 60, 30, 50, []interface{}{}, 40),
 	)
 
+	DescribeTable("Behavior limit reasons",
+		func(minReplicas int, maxReplicas int, currentReplicas int, desiredReplicas int, expectedReplicas int, expectedReason LimitReason) {
+			spec := createSpec(minReplicas, maxReplicas)
+			spec.Behavior.ScaleUp.SelectPolicy = v1alpha1.MaxPolicySelect
+			spec.Behavior.ScaleUp.Policies = []v1alpha1.ScalingPolicy{
+				createPolicy(v1alpha1.PodsScalingPolicy, 2, 15),
+			}
+			spec.Behavior.ScaleDown.SelectPolicy = v1alpha1.DisabledPolicySelect
+
+			status := createStatus(currentReplicas, []interface{}{})
+
+			normalizedReplicas, reason := NewReplicaNormalizer(testClock).NormalizeReplicas(spec, status, int32(desiredReplicas))
+			Expect(normalizedReplicas).To(Equal(int32(expectedReplicas)))
+			Expect(reason).To(Equal(expectedReason))
+		},
+
+		Entry("not limited", 1, 10, 3, 5, 5, NotLimited),
+		Entry("limited by scale up policy", 1, 10, 3, 8, 5, ScaleUpLimit),
+		Entry("limited by max replicas", 1, 4, 3, 8, 4, MaxReplicasLimit),
+		Entry("scale down disabled", 1, 10, 5, 2, 5, ScaleDownDisabled),
+	)
+
+	DescribeTable("Behavior windows",
+		func(eventAgeSeconds int, recommendationAgeSeconds int, currentReplicas int, desiredReplicas int, expectedReplicas int, expectedReason LimitReason) {
+			spec := createSpec(1, 20)
+			spec.Behavior.ScaleUp.SelectPolicy = v1alpha1.MaxPolicySelect
+			spec.Behavior.ScaleUp.Policies = []v1alpha1.ScalingPolicy{
+				createPolicy(v1alpha1.PodsScalingPolicy, 2, 60),
+			}
+			spec.Behavior.ScaleDown.SelectPolicy = v1alpha1.MaxPolicySelect
+			spec.Behavior.ScaleDown.Policies = []v1alpha1.ScalingPolicy{
+				createPolicy(v1alpha1.PercentScalingPolicy, 100, 15),
+			}
+
+			status := createStatus(currentReplicas, []interface{}{})
+			status.ScaleUpEvents = []v1alpha1.ScaleChangeEvent{
+				{Timestamp: metav1.NewTime(testClock.Now().Add(-time.Duration(eventAgeSeconds) * time.Second)), ReplicaChange: 2},
+			}
+			status.Recommendations = []v1alpha1.Recommendation{
+				{Timestamp: metav1.NewTime(testClock.Now().Add(-time.Duration(recommendationAgeSeconds) * time.Second)), Replicas: 4},
+			}
+
+			normalizedReplicas, reason := NewReplicaNormalizer(testClock).NormalizeReplicas(spec, status, int32(desiredReplicas))
+			Expect(normalizedReplicas).To(Equal(int32(expectedReplicas)))
+			Expect(reason).To(Equal(expectedReason))
+		},
+
+		Entry("scale up event inside policy period", 59, 100, 4, 10, 4, ScaleUpLimit),
+		Entry("scale up event outside policy period", 61, 100, 4, 10, 6, ScaleUpLimit),
+		Entry("scale down recommendation inside stabilization window", 100, 14, 5, 2, 4, StabilizationLimit),
+		Entry("scale down recommendation outside stabilization window", 100, 16, 5, 2, 2, NotLimited),
+	)
+
+	DescribeTable("Behavior limiting policy",
+		func(selectPolicy v1alpha1.ScalingPolicySelect, expectedReplicas int, expectedLimit string) {
+			spec := createSpec(1, 20)
+			spec.Behavior.ScaleUp.SelectPolicy = selectPolicy
+			spec.Behavior.ScaleUp.Policies = []v1alpha1.ScalingPolicy{
+				createPolicy(v1alpha1.PodsScalingPolicy, 2, 60),
+				createPolicy(v1alpha1.PercentScalingPolicy, 100, 60),
+			}
+
+			normalization := NewReplicaNormalizer(testClock).Normalize(spec, createStatus(3, []interface{}{}), 10)
+			Expect(normalization.Replicas).To(Equal(int32(expectedReplicas)))
+			Expect(normalization.Reason).To(Equal(ScaleUpLimit))
+			Expect(normalization.StabilizedReplicas).To(Equal(int32(10)))
+			Expect(normalization.Limit).To(Equal(expectedLimit))
+		},
+
+		Entry("max policy", v1alpha1.MaxPolicySelect, 6, "Percent 100 per 60s"),
+		Entry("min policy", v1alpha1.MinPolicySelect, 5, "Pods 2 per 60s"),
+	)
 })
 
 func createSpec(minReplicas int, maxReplicas int) *v1alpha1.KratosSpec {
 	return &v1alpha1.KratosSpec{
-		MinReplicas:                int32(minReplicas),
+		MinReplicas:                pointer.Int32Ptr(int32(minReplicas)),
 		MaxReplicas:                int32(maxReplicas),
 		StabilizationWindowSeconds: 15,
 		Behavior: &v1alpha1.ScaleBehavior{
@@ -140,7 +215,7 @@ func createStatus(currentReplicas int, recommendations []interface{}) *v1alpha1.
 	recordedRecommendations := make([]v1alpha1.Recommendation, len(recommendations))
 
 	for i, item := range recommendations {
-		recordedRecommendations[i] = v1alpha1.Recommendation{Timestamp: metav1.Now(), Replicas: int32(item.(int))}
+		recordedRecommendations[i] = v1alpha1.Recommendation{Timestamp: metav1.NewTime(testClock.Now()), Replicas: int32(item.(int))}
 	}
 
 	return &v1alpha1.KratosStatus{
@@ -150,10 +225,10 @@ func createStatus(currentReplicas int, recommendations []interface{}) *v1alpha1.
 }
 
 func normalizeAndVerifyResult(spec *v1alpha1.KratosSpec, status *v1alpha1.KratosStatus, desiredReplicas int, expectedReplicas int) {
-	normalizedReplicas := newBehaviorNormalizer().normalizeReplicas(spec, status, int32(desiredReplicas))
+	normalizedReplicas := newBehaviorNormalizer(testClock).normalizeReplicas(spec, status, int32(desiredReplicas)).Replicas
 	Expect(normalizedReplicas).To(Equal(int32(expectedReplicas)))
 
-	normalizedReplicasFacade := NewReplicaNormalizer().NormalizeReplicas(spec, status, int32(desiredReplicas))
+	normalizedReplicasFacade, _ := NewReplicaNormalizer(testClock).NormalizeReplicas(spec, status, int32(desiredReplicas))
 	Expect(normalizedReplicasFacade).To(Equal(int32(expectedReplicas)))
 }
 
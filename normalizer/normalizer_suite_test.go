@@ -15,13 +15,18 @@ package normalizer
 
 import (
 	"testing"
+	"time"
 
 	"github.com/adobe/kratos/api/v1alpha1"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testingclock "k8s.io/utils/clock/testing"
 )
 
+// testClock is shared by the normalizer tests, recommendations and scale events are recorded relative to it
+var testClock = testingclock.NewFakeClock(time.Date(2021, 7, 5, 10, 0, 0, 0, time.UTC))
+
 func TestReplicas(t *testing.T) {
 	RegisterFailHandler(Fail)
 
@@ -40,7 +45,7 @@ func appendRecommendations(status *v1alpha1.KratosStatus, recommendations ...int
 	status.Recommendations = make([]v1alpha1.Recommendation, len(recommendations))
 	for i, value := range recommendations {
 		status.Recommendations[i] = v1alpha1.Recommendation{
-			Timestamp: metav1.Now(),
+			Timestamp: metav1.NewTime(testClock.Now()),
 			Replicas:  value,
 		}
 	}
@@ -13,10 +13,47 @@ written permission of Adobe.
 
 package normalizer
 
-import "github.com/adobe/kratos/api/v1alpha1"
+import (
+	"fmt"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+	"k8s.io/utils/clock"
+)
+
+// LimitReason explains why normalized replicas differ from the desired replicas
+type LimitReason string
+
+const (
+	// NotLimited is returned when the desired replicas are used as they are
+	NotLimited LimitReason = ""
+	// StabilizationLimit is returned when a recommendation in the stabilization window is used
+	StabilizationLimit LimitReason = "Stabilization"
+	// ScaleUpLimit is returned when replicas are limited by the scale up rate
+	ScaleUpLimit LimitReason = "ScaleUpLimit"
+	// ScaleDownLimit is returned when replicas are limited by the scale down rate
+	ScaleDownLimit LimitReason = "ScaleDownLimit"
+	// ScaleUpDisabled is returned when scaling up is disabled by the behavior
+	ScaleUpDisabled LimitReason = "ScaleUpDisabled"
+	// ScaleDownDisabled is returned when scaling down is disabled by the behavior
+	ScaleDownDisabled LimitReason = "ScaleDownDisabled"
+	// MinReplicasLimit is returned when replicas are raised to min replicas
+	MinReplicasLimit LimitReason = "MinReplicas"
+	// MaxReplicasLimit is returned when replicas are lowered to max replicas
+	MaxReplicasLimit LimitReason = "MaxReplicas"
+)
+
+// Normalization explains how the desired replicas were normalized
+type Normalization struct {
+	Replicas int32
+	Reason   LimitReason
+	// replicas chosen from the recommendations in the stabilization window
+	StabilizedReplicas int32
+	// scaling policy or rule which limited the replicas, empty unless limited by the scale up or scale down rate
+	Limit string
+}
 
 type normalizer interface {
-	normalizeReplicas(spec *v1alpha1.KratosSpec, status *v1alpha1.KratosStatus, desiredReplicas int32) int32
+	normalizeReplicas(spec *v1alpha1.KratosSpec, status *v1alpha1.KratosStatus, desiredReplicas int32) Normalization
 }
 
 type ReplicaNormalizer struct {
@@ -24,18 +61,31 @@ type ReplicaNormalizer struct {
 	behaviourNormalizer normalizer
 }
 
-func NewReplicaNormalizer() *ReplicaNormalizer {
+// NewReplicaNormalizer creates a normalizer which compares the timestamps of recommendations and scale events with the clock
+func NewReplicaNormalizer(clock clock.Clock) *ReplicaNormalizer {
 	return &ReplicaNormalizer{
-		standardNormalizer:  newStandardNormalizer(),
-		behaviourNormalizer: newBehaviorNormalizer(),
+		standardNormalizer:  newStandardNormalizer(clock),
+		behaviourNormalizer: newBehaviorNormalizer(clock),
 	}
 }
 
+// NormalizeReplicas applies stabilization and scaling limits to the desired replicas, the reason is NotLimited when they are kept
 func (n *ReplicaNormalizer) NormalizeReplicas(spec *v1alpha1.KratosSpec, status *v1alpha1.KratosStatus,
-	desiredReplicas int32) int32 {
+	desiredReplicas int32) (int32, LimitReason) {
+	normalization := n.Normalize(spec, status, desiredReplicas)
+	return normalization.Replicas, normalization.Reason
+}
+
+// Normalize applies stabilization and scaling limits to the desired replicas and explains which of them limited the result
+func (n *ReplicaNormalizer) Normalize(spec *v1alpha1.KratosSpec, status *v1alpha1.KratosStatus, desiredReplicas int32) Normalization {
 	if spec.Behavior == nil || (spec.Behavior.ScaleUp == nil && spec.Behavior.ScaleDown == nil) {
 		return n.standardNormalizer.normalizeReplicas(spec, status, desiredReplicas)
 	}
 
 	return n.behaviourNormalizer.normalizeReplicas(spec, status, desiredReplicas)
 }
+
+// describePolicy formats a scaling policy, e.g. 'Pods 4 per 60s'
+func describePolicy(policy v1alpha1.ScalingPolicy) string {
+	return fmt.Sprintf("%s %d per %ds", policy.Type, policy.Value, policy.PeriodSeconds)
+}
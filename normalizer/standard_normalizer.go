@@ -14,11 +14,13 @@ written permission of Adobe.
 package normalizer
 
 import (
+	"fmt"
 	"math"
 	"time"
 
 	"github.com/adobe/kratos/api/common"
 	"github.com/adobe/kratos/api/v1alpha1"
+	"k8s.io/utils/clock"
 )
 
 const (
@@ -27,27 +29,37 @@ const (
 )
 
 type standardNormalizer struct {
+	clock clock.Clock
 }
 
-func newStandardNormalizer() *standardNormalizer {
-	return &standardNormalizer{}
+func newStandardNormalizer(clock clock.Clock) *standardNormalizer {
+	return &standardNormalizer{clock: clock}
 }
 
-func (n *standardNormalizer) normalizeReplicas(spec *v1alpha1.KratosSpec, status *v1alpha1.KratosStatus, desiredReplicas int32) int32 {
+func (n *standardNormalizer) normalizeReplicas(spec *v1alpha1.KratosSpec, status *v1alpha1.KratosStatus, desiredReplicas int32) Normalization {
 	maxRecommendation := n.findMaxRecommendation(spec.StabilizationWindowSeconds, status, desiredReplicas)
+	result := Normalization{Replicas: maxRecommendation, Reason: NotLimited, StabilizedReplicas: maxRecommendation}
 
 	scaleUpLimit := n.calculateScaleUpLimit(status.CurrentReplicas)
 	maxAllowedScaleReplicas := common.Min(spec.MaxReplicas, scaleUpLimit)
 
-	if maxRecommendation > maxAllowedScaleReplicas {
-		return maxAllowedScaleReplicas
-	}
-
-	if maxRecommendation < spec.MinReplicas {
-		return spec.MinReplicas
+	switch {
+	case maxRecommendation > maxAllowedScaleReplicas:
+		result.Replicas = maxAllowedScaleReplicas
+		if spec.MaxReplicas <= scaleUpLimit {
+			result.Reason = MaxReplicasLimit
+		} else {
+			result.Reason = ScaleUpLimit
+			result.Limit = fmt.Sprintf("%g times current replicas, at least %g", scaleUpLimitFactor, scaleUpLimitMinimum)
+		}
+	case maxRecommendation < spec.GetMinReplicas():
+		result.Replicas = spec.GetMinReplicas()
+		result.Reason = MinReplicasLimit
+	case maxRecommendation != desiredReplicas:
+		result.Reason = StabilizationLimit
 	}
 
-	return maxRecommendation
+	return result
 }
 
 func (n *standardNormalizer) findMaxRecommendation(stabilizationWindowSec int32, status *v1alpha1.KratosStatus, desiredReplicas int32) int32 {
@@ -55,7 +67,7 @@ func (n *standardNormalizer) findMaxRecommendation(stabilizationWindowSec int32,
 		return desiredReplicas
 	}
 
-	cutOff := time.Now().Add(time.Duration(-stabilizationWindowSec))
+	cutOff := n.clock.Now().Add(-time.Duration(stabilizationWindowSec) * time.Second)
 	maxRecommendation := desiredReplicas
 	for _, recommendation := range status.Recommendations {
 		if maxRecommendation < recommendation.Replicas && recommendation.Timestamp.After(cutOff) {
@@ -14,29 +14,32 @@ written permission of Adobe.
 package normalizer
 
 import (
+	"time"
+
 	"github.com/adobe/kratos/api/v1alpha1"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 )
 
 var _ = Describe("StandardNormalizer", func() {
-	var normalizer = newStandardNormalizer()
-	var normalizerFacade = NewReplicaNormalizer()
+	var normalizer = newStandardNormalizer(testClock)
+	var normalizerFacade = NewReplicaNormalizer(testClock)
 
 	DescribeTable("Standard normalizer",
 		func(minReplicas int, maxReplicas int, currentReplicas int, desiredReplicas int, recommendations []interface{}, expectedReplicas int) {
 			spec := &v1alpha1.KratosSpec{
 				StabilizationWindowSeconds: 15,
-				MinReplicas:                int32(minReplicas),
+				MinReplicas:                pointer.Int32Ptr(int32(minReplicas)),
 				MaxReplicas:                int32(maxReplicas),
 			}
 
 			recordedRecommendations := make([]v1alpha1.Recommendation, len(recommendations))
 
 			for i, item := range recommendations {
-				recordedRecommendations[i] = v1alpha1.Recommendation{Timestamp: metav1.Now(), Replicas: int32(item.(int))}
+				recordedRecommendations[i] = v1alpha1.Recommendation{Timestamp: metav1.NewTime(testClock.Now()), Replicas: int32(item.(int))}
 			}
 
 			status := &v1alpha1.KratosStatus{
@@ -44,10 +47,10 @@ var _ = Describe("StandardNormalizer", func() {
 				Recommendations: recordedRecommendations,
 			}
 
-			normalizedReplicas := normalizer.normalizeReplicas(spec, status, int32(desiredReplicas))
+			normalizedReplicas := normalizer.normalizeReplicas(spec, status, int32(desiredReplicas)).Replicas
 			Expect(normalizedReplicas).To(Equal(int32(expectedReplicas)))
 
-			normalizedReplicasFacade := normalizerFacade.NormalizeReplicas(spec, status, int32(desiredReplicas))
+			normalizedReplicasFacade, _ := normalizerFacade.NormalizeReplicas(spec, status, int32(desiredReplicas))
 			Expect(normalizedReplicasFacade).To(Equal(int32(expectedReplicas)))
 		},
 
@@ -55,4 +58,57 @@ var _ = Describe("StandardNormalizer", func() {
 		Entry("scale limited by max replicas ", 1, 10, 9, 11, []interface{}{4, 15}, 10),
 		Entry("scale limited by min replicas ", 4, 10, 5, 2, []interface{}{1, 1, 2}, 4),
 	)
+	DescribeTable("Standard normalizer limit reasons",
+		func(minReplicas int, maxReplicas int, currentReplicas int, desiredReplicas int, recommendations []interface{}, expectedReason LimitReason) {
+			spec := &v1alpha1.KratosSpec{
+				StabilizationWindowSeconds: 15,
+				MinReplicas:                pointer.Int32Ptr(int32(minReplicas)),
+				MaxReplicas:                int32(maxReplicas),
+			}
+
+			recordedRecommendations := make([]v1alpha1.Recommendation, len(recommendations))
+
+			for i, item := range recommendations {
+				recordedRecommendations[i] = v1alpha1.Recommendation{Timestamp: metav1.NewTime(testClock.Now()), Replicas: int32(item.(int))}
+			}
+
+			status := &v1alpha1.KratosStatus{
+				CurrentReplicas: int32(currentReplicas),
+				Recommendations: recordedRecommendations,
+			}
+
+			_, reason := normalizerFacade.NormalizeReplicas(spec, status, int32(desiredReplicas))
+			Expect(reason).To(Equal(expectedReason))
+		},
+
+		Entry("not limited", 1, 10, 4, 6, []interface{}{}, NotLimited),
+		Entry("limited by scale up rate", 1, 20, 2, 10, []interface{}{}, ScaleUpLimit),
+		Entry("limited by max replicas", 1, 10, 9, 11, []interface{}{}, MaxReplicasLimit),
+		Entry("limited by min replicas", 4, 10, 5, 2, []interface{}{}, MinReplicasLimit),
+	)
+
+	DescribeTable("Standard normalizer stabilization window",
+		func(ageSeconds int, expectedReplicas int, expectedReason LimitReason) {
+			spec := &v1alpha1.KratosSpec{
+				StabilizationWindowSeconds: 15,
+				MinReplicas:                pointer.Int32Ptr(1),
+				MaxReplicas:                10,
+			}
+
+			status := &v1alpha1.KratosStatus{
+				CurrentReplicas: 5,
+				Recommendations: []v1alpha1.Recommendation{
+					{Timestamp: metav1.NewTime(testClock.Now().Add(-time.Duration(ageSeconds) * time.Second)), Replicas: 6},
+				},
+			}
+
+			normalizedReplicas, reason := normalizerFacade.NormalizeReplicas(spec, status, 3)
+			Expect(normalizedReplicas).To(Equal(int32(expectedReplicas)))
+			Expect(reason).To(Equal(expectedReason))
+		},
+
+		Entry("recommendation inside window", 14, 6, StabilizationLimit),
+		Entry("recommendation at window start", 15, 3, NotLimited),
+		Entry("recommendation outside window", 16, 3, NotLimited),
+	)
 })
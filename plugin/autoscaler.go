@@ -0,0 +1,166 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/adobe/kratos/scale"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	kratosKind    = "Kratos"
+	configMapKind = "ConfigMap"
+	specKey       = "kratosSpec"
+)
+
+// autoscaler is a Kratos resource or a ConfigMap with a 'kratosSpec' key
+type autoscaler struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Spec      *v1alpha1.KratosSpec
+	Status    *v1alpha1.KratosStatus
+	// error on reading the spec or status of a ConfigMap
+	Error error
+}
+
+func fromKratos(kratos *v1alpha1.Kratos) autoscaler {
+	return autoscaler{
+		Kind:      kratosKind,
+		Namespace: kratos.Namespace,
+		Name:      kratos.Name,
+		Spec:      kratos.Spec.DeepCopy(),
+		Status:    kratos.Status.DeepCopy(),
+	}
+}
+
+// fromConfigMap reads the spec and status of a ConfigMap the way the operator does
+func fromConfigMap(configMap *corev1.ConfigMap) autoscaler {
+	spec, status, err := scale.UnmarshallConfigMapData(configMap.Data)
+
+	return autoscaler{
+		Kind:      configMapKind,
+		Namespace: configMap.Namespace,
+		Name:      configMap.Name,
+		Spec:      spec,
+		Status:    status,
+		Error:     err,
+	}
+}
+
+// listAutoscalers lists the Kratos resources and ConfigMaps with a 'kratosSpec' key in the namespace, all namespaces when empty
+func listAutoscalers(ctx context.Context, c client.Client, namespace string) ([]autoscaler, error) {
+	result := make([]autoscaler, 0)
+
+	kratosList := &v1alpha1.KratosList{}
+	if err := c.List(ctx, kratosList, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("can't list Kratos resources: %v", err)
+	}
+
+	for i := range kratosList.Items {
+		result = append(result, fromKratos(&kratosList.Items[i]))
+	}
+
+	configMaps := &corev1.ConfigMapList{}
+	if err := c.List(ctx, configMaps, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("can't list ConfigMaps: %v", err)
+	}
+
+	for i := range configMaps.Items {
+		if _, found := configMaps.Items[i].Data[specKey]; found {
+			result = append(result, fromConfigMap(&configMaps.Items[i]))
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Namespace != result[j].Namespace {
+			return result[i].Namespace < result[j].Namespace
+		}
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].Kind > result[j].Kind
+	})
+
+	return result, nil
+}
+
+// getAutoscaler gets an autoscaler by name. The name may be prefixed with its kind, e.g. 'configmap/nginx-scaler',
+// otherwise a Kratos resource is looked up before a ConfigMap.
+func getAutoscaler(ctx context.Context, c client.Client, namespace string, name string) (*autoscaler, error) {
+	kinds := []string{kratosKind, configMapKind}
+
+	if separator := strings.Index(name, "/"); separator >= 0 {
+		kind, err := parseKind(name[:separator])
+		if err != nil {
+			return nil, err
+		}
+		kinds = []string{kind}
+		name = name[separator+1:]
+	}
+
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+
+	for _, kind := range kinds {
+		var result autoscaler
+
+		switch kind {
+		case kratosKind:
+			kratos := &v1alpha1.Kratos{}
+			if err := c.Get(ctx, key, kratos); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return nil, fmt.Errorf("can't get Kratos %s: %v", key, err)
+			}
+			result = fromKratos(kratos)
+		case configMapKind:
+			configMap := &corev1.ConfigMap{}
+			if err := c.Get(ctx, key, configMap); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return nil, fmt.Errorf("can't get ConfigMap %s: %v", key, err)
+			}
+			if _, found := configMap.Data[specKey]; !found {
+				return nil, fmt.Errorf("ConfigMap %s has no '%s' key", key, specKey)
+			}
+			result = fromConfigMap(configMap)
+		}
+
+		return &result, nil
+	}
+
+	return nil, fmt.Errorf("autoscaler %s not found", key)
+}
+
+func parseKind(kind string) (string, error) {
+	switch strings.ToLower(kind) {
+	case "kratos", "kratos.scaling.core.adobe.com":
+		return kratosKind, nil
+	case "configmap", "configmaps", "cm":
+		return configMapKind, nil
+	}
+
+	return "", fmt.Errorf("unknown kind '%s', expected kratos or configmap", kind)
+}
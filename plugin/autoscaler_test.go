@@ -0,0 +1,115 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Autoscalers", func() {
+	var c client.Client
+	now := time.Date(2021, 7, 5, 10, 0, 0, 0, time.UTC)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&v1alpha1.Kratos{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nginx-scaler"},
+				Spec: v1alpha1.KratosSpec{
+					Target:      v1alpha1.ScaleTargetReference{Kind: "Deployment", Name: "nginx"},
+					MinReplicas: pointer.Int32Ptr(1),
+					MaxReplicas: 10,
+				},
+				Status: v1alpha1.KratosStatus{
+					CurrentReplicas: 3,
+					DesiredReplicas: 4,
+					LastScaleTime:   &metav1.Time{Time: now.Add(-5 * time.Minute)},
+				},
+			},
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nginx-scaler"},
+				Data: map[string]string{
+					"kratosSpec":   "target:\n  kind: Deployment\n  name: nginx-cm\nminReplicas: 2\nmaxReplicas: 5\n",
+					"kratosStatus": "currentReplicas: 2\ndesiredReplicas: 2\n",
+				},
+			},
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "apps", Name: "broken-scaler"},
+				Data:       map[string]string{"kratosSpec": "minReplicas: [1"},
+			},
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "settings"},
+				Data:       map[string]string{"key": "value"},
+			},
+		).Build()
+	})
+
+	It("List autoscalers of all namespaces", func() {
+		autoscalers, err := listAutoscalers(context.Background(), c, "")
+		Expect(err).To(BeNil())
+		Expect(autoscalers).To(HaveLen(3), "ConfigMaps without 'kratosSpec' key aren't autoscalers")
+
+		output := &bytes.Buffer{}
+		Expect(writeList(output, autoscalers, now)).To(Succeed())
+		Expect(output.String()).To(Equal(
+			"NAMESPACE  NAME           KIND       TARGET               CURRENT  DESIRED  MIN     MAX     LAST SCALE\n" +
+				"apps       broken-scaler  ConfigMap  <invalid>            <none>   <none>   <none>  <none>  <none>\n" +
+				"default    nginx-scaler   Kratos     Deployment/nginx     3        4        1       10      5m\n" +
+				"default    nginx-scaler   ConfigMap  Deployment/nginx-cm  2        2        2       5       <none>\n"))
+	})
+
+	It("List autoscalers of a namespace", func() {
+		autoscalers, err := listAutoscalers(context.Background(), c, "apps")
+		Expect(err).To(BeNil())
+		Expect(autoscalers).To(HaveLen(1))
+		Expect(autoscalers[0].Error).NotTo(BeNil())
+	})
+
+	It("Get autoscaler", func() {
+		item, err := getAutoscaler(context.Background(), c, "default", "nginx-scaler")
+		Expect(err).To(BeNil())
+		Expect(item.Kind).To(Equal(kratosKind), "Kratos resources should be looked up first")
+
+		item, err = getAutoscaler(context.Background(), c, "default", "cm/nginx-scaler")
+		Expect(err).To(BeNil())
+		Expect(item.Kind).To(Equal(configMapKind))
+		Expect(item.Spec.Target.Name).To(Equal("nginx-cm"))
+		Expect(item.Status.CurrentReplicas).To(Equal(int32(2)))
+
+		_, err = getAutoscaler(context.Background(), c, "default", "configmap/settings")
+		Expect(err).To(MatchError(ContainSubstring("has no 'kratosSpec' key")))
+
+		_, err = getAutoscaler(context.Background(), c, "default", "deployment/nginx")
+		Expect(err).To(MatchError(ContainSubstring("unknown kind")))
+
+		_, err = getAutoscaler(context.Background(), c, "default", "missing")
+		Expect(err).To(MatchError("autoscaler default/missing not found"))
+	})
+})
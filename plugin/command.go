@@ -0,0 +1,143 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package plugin
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const usage = `Usage: kubectl kratos <command> [flags]
+
+Inspects the autoscalers of the Kratos operator, Kratos resources and ConfigMaps with a 'kratosSpec' key.
+
+Commands:
+  list      List autoscalers with their replicas and last scale time
+  explain   Explain a single autoscaler: windows, recommendations, scale events, conditions and decisions
+  validate  Validate autoscalers in a local file the way the operator reads them
+  convert   Convert HorizontalPodAutoscalers in a local file to Kratos specs and back
+
+Run 'kubectl kratos <command> -h' for the flags of a command.
+`
+
+// Run executes the plugin with the arguments following 'kubectl kratos' on the command line
+func Run(args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stdout, usage)
+		return fmt.Errorf("a command is required")
+	}
+
+	switch args[0] {
+	case "list":
+		return runList(args[1:], stdout)
+	case "explain":
+		return runExplain(args[1:], stdout)
+	case "validate":
+		return runValidate(args[1:], stdout)
+	case "convert":
+		return runConvert(args[1:], stdout)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return nil
+	}
+
+	fmt.Fprint(stdout, usage)
+	return fmt.Errorf("unknown command '%s'", args[0])
+}
+
+// clientOptions are the flags selecting the cluster and namespace, they follow the kubectl flags of the same name
+type clientOptions struct {
+	kubeconfig string
+	context    string
+	namespace  string
+}
+
+func addClientFlags(flags *flag.FlagSet) *clientOptions {
+	options := &clientOptions{}
+
+	flags.StringVar(&options.kubeconfig, "kubeconfig", "", "Path to the kubeconfig file, KUBECONFIG and ~/.kube/config are used by default.")
+	flags.StringVar(&options.context, "context", "", "The kubeconfig context to use.")
+	flags.StringVar(&options.namespace, "namespace", "", "Namespace of the autoscalers, the namespace of the context by default.")
+	flags.StringVar(&options.namespace, "n", "", "Shorthand for --namespace.")
+
+	return options
+}
+
+// newClient creates a client for the selected cluster and returns it with the selected namespace
+func (o *clientOptions) newClient() (client.Client, string, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = o.kubeconfig
+
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: o.context}
+	overrides.Context.Namespace = o.namespace
+
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides)
+
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return nil, "", err
+	}
+
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, "", err
+	}
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return nil, "", err
+	}
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		return nil, "", err
+	}
+
+	c, err := client.New(restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return c, namespace, nil
+}
+
+// parseArgs parses flags placed before and after the positional arguments, like kubectl does, and returns the positional ones
+func parseArgs(flags *flag.FlagSet, args []string) ([]string, error) {
+	positional := make([]string, 0)
+
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// isHelp returns true when the flags were parsed for -h, the usage was printed already
+func isHelp(err error) bool {
+	return errors.Is(err, flag.ErrHelp)
+}
@@ -0,0 +1,272 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package plugin
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/adobe/kratos/api/common"
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/adobe/kratos/convert"
+	"github.com/adobe/kratos/scale"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+const listKind = "List"
+
+// convertOptions select the form of the converted documents
+type convertOptions struct {
+	// kind written for HorizontalPodAutoscalers, Kratos or ConfigMap
+	kind string
+	// API version of the HorizontalPodAutoscalers converted from Kratos resources and ConfigMaps
+	hpaAPIVersion   string
+	defaultsUpdater *scale.DefaultsUpdater
+}
+
+func runConvert(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("convert", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+
+	filename := ""
+	flags.StringVar(&filename, "filename", "", "YAML file with HorizontalPodAutoscalers, Kratos resources or ConfigMaps, '-' reads standard input.")
+	flags.StringVar(&filename, "f", "", "Shorthand for --filename.")
+	kind := flags.String("to", "kratos", "Kind written for HorizontalPodAutoscalers, kratos or configmap.")
+	hpaAPIVersion := flags.String("hpa-api-version", convert.HPAAPIVersion, fmt.Sprintf("API version of the HorizontalPodAutoscalers written for Kratos resources and ConfigMaps, %s or %s.", convert.HPAAPIVersion, convert.HPABetaAPIVersion))
+	stabilizationWindowSeconds := flags.Int("stabilization-window-seconds", 300, "Default stabilization window in seconds, as set on the operator.")
+
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: kubectl kratos convert -f <file> [flags]\n\n"+
+			"Converts HorizontalPodAutoscalers to Kratos resources or ConfigMaps, and Kratos resources and ConfigMaps with\n"+
+			"a 'kratosSpec' key to HorizontalPodAutoscalers. Fields which can't be represented are reported as comments\n"+
+			"in front of the converted document. Documents of other kinds are skipped.\n\n")
+		flags.PrintDefaults()
+	}
+
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		if isHelp(err) {
+			return nil
+		}
+		return err
+	}
+
+	if filename == "" || len(positional) > 0 {
+		flags.Usage()
+		return fmt.Errorf("a single file is required with --filename")
+	}
+
+	options := &convertOptions{
+		hpaAPIVersion:   *hpaAPIVersion,
+		defaultsUpdater: scale.NewDefaultsUpdater(&common.KratosParameters{StabilizationWindowSeconds: int32(*stabilizationWindowSeconds)}),
+	}
+
+	options.kind, err = parseKind(*kind)
+	if err != nil {
+		return err
+	}
+
+	if options.hpaAPIVersion != convert.HPAAPIVersion && options.hpaAPIVersion != convert.HPABetaAPIVersion {
+		return fmt.Errorf("unsupported API version '%s', expected %s or %s", options.hpaAPIVersion, convert.HPAAPIVersion, convert.HPABetaAPIVersion)
+	}
+
+	var content []byte
+	if filename == "-" {
+		content, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		content, err = ioutil.ReadFile(filename)
+	}
+	if err != nil {
+		return err
+	}
+
+	return convertDocuments(content, options, stdout)
+}
+
+// conversion is a converted object with the warnings for the fields which couldn't be represented
+type conversion struct {
+	Object   runtime.Object
+	Warnings []string
+}
+
+// convertDocuments converts every document of a YAML stream and writes the converted ones, items of lists are converted one by one.
+// Documents which can't be converted are reported in the returned error after the others are written.
+func convertDocuments(content []byte, options *convertOptions, writer io.Writer) error {
+	conversions := make([]conversion, 0)
+	failures := make([]string, 0)
+
+	for _, document := range documentSeparator.Split(string(content), -1) {
+		if strings.TrimSpace(document) == "" {
+			continue
+		}
+
+		items, err := splitList([]byte(document))
+		if err != nil {
+			failures = append(failures, err.Error())
+			continue
+		}
+
+		for _, item := range items {
+			result, err := convertDocument(item, options)
+			if err != nil {
+				failures = append(failures, err.Error())
+				continue
+			}
+			if result != nil {
+				conversions = append(conversions, *result)
+			}
+		}
+	}
+
+	for i, result := range conversions {
+		if i > 0 {
+			fmt.Fprintln(writer, "---")
+		}
+		if err := writeConversion(writer, &result); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case len(failures) > 0:
+		return fmt.Errorf("%d documents can't be converted:\n  %s", len(failures), strings.Join(failures, "\n  "))
+	case len(conversions) == 0:
+		return fmt.Errorf("no HorizontalPodAutoscaler, Kratos resource or ConfigMap with a '%s' key found", specKey)
+	}
+
+	return nil
+}
+
+// splitList returns the items of a List document or the document itself
+func splitList(document []byte) ([][]byte, error) {
+	object := &metav1.PartialObjectMetadata{}
+	if err := yaml.Unmarshal(document, object); err != nil {
+		return nil, fmt.Errorf("can't parse document: %v", err)
+	}
+
+	if object.Kind != listKind {
+		return [][]byte{document}, nil
+	}
+
+	list := &struct {
+		Items []json.RawMessage `json:"items"`
+	}{}
+	if err := yaml.Unmarshal(document, list); err != nil {
+		return nil, fmt.Errorf("can't parse list: %v", err)
+	}
+
+	items := make([][]byte, 0, len(list.Items))
+	for _, item := range list.Items {
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// convertDocument converts a HorizontalPodAutoscaler, a Kratos resource or a ConfigMap with a 'kratosSpec' key.
+// Returns nil for documents of other kinds.
+func convertDocument(document []byte, options *convertOptions) (*conversion, error) {
+	object := &metav1.PartialObjectMetadata{}
+	if err := yaml.Unmarshal(document, object); err != nil {
+		return nil, fmt.Errorf("can't parse document: %v", err)
+	}
+
+	switch object.Kind {
+	case convert.HPAKind:
+		if object.APIVersion != convert.HPAAPIVersion && object.APIVersion != convert.HPABetaAPIVersion {
+			return nil, fmt.Errorf("%s/%s: unsupported API version '%s', expected %s or %s", object.Kind, object.Name, object.APIVersion, convert.HPAAPIVersion, convert.HPABetaAPIVersion)
+		}
+
+		// not strict, the status written by newer clusters may have fields this version doesn't know
+		hpa := &autoscalingv2beta2.HorizontalPodAutoscaler{}
+		if err := yaml.Unmarshal(document, hpa); err != nil {
+			return nil, fmt.Errorf("%s/%s: %v", object.Kind, object.Name, err)
+		}
+
+		if options.kind == configMapKind {
+			configMap, warnings, err := convert.ToConfigMap(hpa)
+			if err != nil {
+				return nil, fmt.Errorf("%s/%s: %v", object.Kind, object.Name, err)
+			}
+			return &conversion{Object: configMap, Warnings: warnings}, nil
+		}
+
+		kratos, warnings := convert.ToKratos(hpa)
+		return &conversion{Object: kratos, Warnings: warnings}, nil
+	case kratosKind:
+		kratos := &v1alpha1.Kratos{}
+		if err := yaml.UnmarshalStrict(document, kratos); err != nil {
+			return nil, fmt.Errorf("%s/%s: %v", object.Kind, object.Name, err)
+		}
+
+		options.defaultsUpdater.UpdateSpecWithDefaults(&kratos.Spec)
+
+		hpa, warnings := convert.ToHPA(&kratos.ObjectMeta, &kratos.Spec, options.hpaAPIVersion)
+		return &conversion{Object: hpa, Warnings: warnings}, nil
+	case configMapKind:
+		configMap := &corev1.ConfigMap{}
+		if err := yaml.Unmarshal(document, configMap); err != nil {
+			return nil, fmt.Errorf("%s/%s: %v", object.Kind, object.Name, err)
+		}
+
+		if _, found := configMap.Data[specKey]; !found {
+			return nil, nil
+		}
+
+		spec, _, err := scale.UnmarshallConfigMapData(configMap.Data)
+		if err != nil {
+			return nil, fmt.Errorf("%s/%s: %v", object.Kind, object.Name, err)
+		}
+
+		options.defaultsUpdater.UpdateSpecWithDefaults(spec)
+
+		hpa, warnings := convert.ToHPA(&configMap.ObjectMeta, spec, options.hpaAPIVersion)
+		return &conversion{Object: hpa, Warnings: warnings}, nil
+	}
+
+	return nil, nil
+}
+
+// writeConversion writes the warnings as comments followed by the object without its status and creation timestamp
+func writeConversion(writer io.Writer, result *conversion) error {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(result.Object)
+	if err != nil {
+		return err
+	}
+
+	unstructured.RemoveNestedField(content, "status")
+	unstructured.RemoveNestedField(content, "metadata", "creationTimestamp")
+
+	document, err := yaml.Marshal(content)
+	if err != nil {
+		return err
+	}
+
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(writer, "# warning: %s\n", warning)
+	}
+
+	_, err = writer.Write(document)
+	return err
+}
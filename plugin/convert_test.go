@@ -0,0 +1,183 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/adobe/kratos/api/common"
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/adobe/kratos/convert"
+	"github.com/adobe/kratos/scale"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const nginxHPA = `apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: nginx
+  namespace: default
+  creationTimestamp: "2021-07-05T10:00:00Z"
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: nginx
+  minReplicas: 2
+  maxReplicas: 10
+  metrics:
+    - type: Resource
+      resource:
+        name: cpu
+        target:
+          type: Utilization
+          averageUtilization: 70
+status:
+  currentReplicas: 2
+  desiredReplicas: 2
+`
+
+const prometheusKratos = `apiVersion: scaling.core.adobe.com/v1alpha1
+kind: Kratos
+metadata:
+  name: nginx-scaler
+spec:
+  target:
+    kind: Deployment
+    name: nginx
+  minReplicas: 1
+  maxReplicas: 10
+  metrics:
+    - type: Prometheus
+      prometheus:
+        metricQuery: sum(rate(requests_total[1m]))
+        target:
+          type: Value
+          value: "100"
+`
+
+func newConvertOptions(kind string) *convertOptions {
+	return &convertOptions{
+		kind:            kind,
+		hpaAPIVersion:   convert.HPAAPIVersion,
+		defaultsUpdater: scale.NewDefaultsUpdater(&common.KratosParameters{StabilizationWindowSeconds: 300}),
+	}
+}
+
+var _ = Describe("Convert", func() {
+	It("Converts HorizontalPodAutoscalers to Kratos resources", func() {
+		output := &bytes.Buffer{}
+		Expect(convertDocuments([]byte(nginxHPA), newConvertOptions(kratosKind), output)).To(Succeed())
+
+		Expect(output.String()).To(HavePrefix("# warning: behavior.scaleUp.stabilizationWindowSeconds"))
+		Expect(output.String()).NotTo(ContainSubstring("status"))
+		Expect(output.String()).NotTo(ContainSubstring("creationTimestamp"))
+
+		kratos := &v1alpha1.Kratos{}
+		Expect(yaml.UnmarshalStrict(output.Bytes(), kratos)).To(Succeed())
+		Expect(kratos.Kind).To(Equal(kratosKind))
+		Expect(kratos.Name).To(Equal("nginx"))
+		Expect(kratos.Namespace).To(Equal("default"))
+		Expect(kratos.Spec.GetMinReplicas()).To(Equal(int32(2)))
+		Expect(kratos.Spec.Metrics).To(HaveLen(1))
+	})
+
+	It("Converts HorizontalPodAutoscalers to ConfigMaps the operator can read", func() {
+		output := &bytes.Buffer{}
+		Expect(convertDocuments([]byte(nginxHPA), newConvertOptions(configMapKind), output)).To(Succeed())
+
+		configMap := &corev1.ConfigMap{}
+		Expect(yaml.UnmarshalStrict(output.Bytes(), configMap)).To(Succeed())
+		Expect(configMap.Kind).To(Equal(configMapKind))
+		Expect(configMap.Labels).To(HaveKeyWithValue(v1alpha1.KratosSpecLabel, "true"), "the spec should be validated on admission")
+
+		spec, _, err := scale.UnmarshallConfigMapData(configMap.Data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(spec.MaxReplicas).To(Equal(int32(10)))
+		Expect(spec.Behavior.ScaleDown.SelectPolicy).To(Equal(v1alpha1.MaxPolicySelect))
+	})
+
+	It("Converts Kratos resources to HorizontalPodAutoscalers and reports Prometheus metrics", func() {
+		output := &bytes.Buffer{}
+		Expect(convertDocuments([]byte(validKratos+"---\n"+prometheusKratos), newConvertOptions(kratosKind), output)).To(Succeed())
+
+		documents := documentSeparator.Split(output.String(), -1)
+		Expect(documents).To(HaveLen(2))
+		Expect(documents[0]).NotTo(ContainSubstring("# warning"))
+		Expect(strings.TrimSpace(documents[1])).To(HavePrefix("# warning: metrics[0]: HorizontalPodAutoscalers can't query Prometheus"))
+
+		hpa := &autoscalingv2beta2.HorizontalPodAutoscaler{}
+		Expect(yaml.UnmarshalStrict([]byte(documents[0]), hpa)).To(Succeed())
+		Expect(hpa.APIVersion).To(Equal(convert.HPAAPIVersion))
+		Expect(hpa.Kind).To(Equal(convert.HPAKind))
+		Expect(hpa.Name).To(Equal("nginx-scaler"))
+		Expect(hpa.Spec.Metrics).To(HaveLen(1))
+		Expect(*hpa.Spec.Behavior.ScaleDown.StabilizationWindowSeconds).To(Equal(int32(300)))
+	})
+
+	It("Converts the items of lists and skips other kinds", func() {
+		list := `apiVersion: v1
+kind: List
+items:
+  - apiVersion: autoscaling/v2beta2
+    kind: HorizontalPodAutoscaler
+    metadata:
+      name: nginx
+    spec:
+      scaleTargetRef:
+        kind: Deployment
+        name: nginx
+      maxReplicas: 5
+  - apiVersion: v1
+    kind: Service
+    metadata:
+      name: nginx
+`
+		output := &bytes.Buffer{}
+		Expect(convertDocuments([]byte(list), newConvertOptions(kratosKind), output)).To(Succeed())
+
+		kratos := &v1alpha1.Kratos{}
+		Expect(yaml.Unmarshal(output.Bytes(), kratos)).To(Succeed())
+		Expect(kratos.Spec.MaxReplicas).To(Equal(int32(5)))
+	})
+
+	It("Reports unsupported API versions", func() {
+		hpa := `apiVersion: autoscaling/v1
+kind: HorizontalPodAutoscaler
+metadata:
+  name: legacy
+spec:
+  scaleTargetRef:
+    kind: Deployment
+    name: nginx
+  maxReplicas: 5
+`
+		output := &bytes.Buffer{}
+		err := convertDocuments([]byte(nginxHPA+"---\n"+hpa), newConvertOptions(kratosKind), output)
+
+		Expect(err).To(MatchError(ContainSubstring("HorizontalPodAutoscaler/legacy: unsupported API version 'autoscaling/v1'")))
+		Expect(output.String()).To(ContainSubstring("name: nginx"), "supported documents should still be converted")
+	})
+
+	It("Fails without autoscalers", func() {
+		err := convertDocuments([]byte("apiVersion: v1\nkind: Service\nmetadata:\n  name: nginx\n"), newConvertOptions(kratosKind), &bytes.Buffer{})
+
+		Expect(err).To(MatchError(ContainSubstring("no HorizontalPodAutoscaler")))
+	})
+})
@@ -0,0 +1,219 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package plugin
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/adobe/kratos/api/common"
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/adobe/kratos/scale"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func runExplain(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("explain", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+
+	options := addClientFlags(flags)
+	stabilizationWindowSeconds := flags.Int("stabilization-window-seconds", 300, "Default stabilization window in seconds, as set on the operator.")
+
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: kubectl kratos explain [kratos/|configmap/]<name> [flags]\n\n"+
+			"Explains an autoscaler: its windows and policies with defaults applied, recent recommendations,\n"+
+			"scale events, conditions, metrics and decisions. A Kratos resource is looked up before a ConfigMap.\n\n")
+		flags.PrintDefaults()
+	}
+
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		if isHelp(err) {
+			return nil
+		}
+		return err
+	}
+
+	if len(positional) != 1 {
+		flags.Usage()
+		return fmt.Errorf("exactly one autoscaler name is required")
+	}
+
+	c, namespace, err := options.newClient()
+	if err != nil {
+		return err
+	}
+
+	item, err := getAutoscaler(context.Background(), c, namespace, positional[0])
+	if err != nil {
+		return err
+	}
+
+	if item.Error != nil {
+		return fmt.Errorf("can't read %s %s/%s: %v", item.Kind, item.Namespace, item.Name, item.Error)
+	}
+
+	scale.NewDefaultsUpdater(&common.KratosParameters{StabilizationWindowSeconds: int32(*stabilizationWindowSeconds)}).UpdateSpecWithDefaults(item.Spec)
+
+	return writeExplanation(stdout, item, time.Now())
+}
+
+// writeExplanation describes an autoscaler with defaults applied to its spec, lists are written oldest first
+func writeExplanation(writer io.Writer, item *autoscaler, now time.Time) error {
+	spec := item.Spec
+	status := item.Status
+
+	w := tabwriter.NewWriter(writer, 0, 8, 2, ' ', 0)
+
+	fmt.Fprintf(w, "Name:\t%s\n", item.Name)
+	fmt.Fprintf(w, "Namespace:\t%s\n", item.Namespace)
+	fmt.Fprintf(w, "Kind:\t%s\n", item.Kind)
+	fmt.Fprintf(w, "Target:\t%s\n", describeScaleTarget(&spec.Target))
+	fmt.Fprintf(w, "Mode:\t%s\n", spec.Mode)
+	fmt.Fprintf(w, "Algorithm:\t%s\n", spec.Algorithm.Type)
+	fmt.Fprintf(w, "Replicas:\tcurrent %d, desired %d, min %d, max %d\n", status.CurrentReplicas, status.DesiredReplicas, spec.GetMinReplicas(), spec.MaxReplicas)
+	fmt.Fprintf(w, "Last scale:\t%s\n", formatTime(status.LastScaleTime, now))
+
+	if status.ActiveSchedule != "" {
+		fmt.Fprintf(w, "Active schedule:\t%s\n", status.ActiveSchedule)
+	}
+
+	if status.Parked {
+		fmt.Fprintf(w, "Parked:\tsince %s\n", formatTime(status.IdleSince, now))
+	}
+
+	fmt.Fprintln(w, "Windows:")
+	if spec.Behavior == nil {
+		fmt.Fprintf(w, "  Stabilization:\t%ds\n", spec.StabilizationWindowSeconds)
+		fmt.Fprintf(w, "  Scale up limit:\t2 times current replicas, at least 4\n")
+	} else {
+		fmt.Fprintf(w, "  Scale up:\t%s\n", describeScaleRules(spec.Behavior.ScaleUp))
+		fmt.Fprintf(w, "  Scale down:\t%s\n", describeScaleRules(spec.Behavior.ScaleDown))
+	}
+
+	fmt.Fprintln(w, "Conditions:")
+	if len(status.Conditions) == 0 {
+		fmt.Fprintf(w, "  %s\n", none)
+	} else {
+		fmt.Fprintln(w, "  TYPE\tSTATUS\tREASON\tSINCE\tMESSAGE")
+		for _, condition := range status.Conditions {
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", condition.Type, condition.Status, condition.Reason, age(&condition.LastTransitionTime, now), condition.Message)
+		}
+	}
+
+	fmt.Fprintln(w, "Metrics:")
+	if len(status.CurrentMetrics) == 0 {
+		fmt.Fprintf(w, "  %s\n", none)
+	} else {
+		fmt.Fprintln(w, "  TYPE\tNAME\tVALUE\tPROPOSAL\tERROR")
+		for _, metric := range status.CurrentMetrics {
+			value := none
+			if metric.Value != nil {
+				value = metric.Value.String()
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", metric.Type, metric.Name, value, formatInt32(metric.ProposedReplicas), orNone(metric.Error))
+		}
+	}
+
+	fmt.Fprintln(w, "Recommendations:")
+	if len(status.Recommendations) == 0 {
+		fmt.Fprintf(w, "  %s\n", none)
+	} else {
+		fmt.Fprintln(w, "  TIME\tREPLICAS")
+		for _, recommendation := range status.Recommendations {
+			fmt.Fprintf(w, "  %s\t%d\n", formatTime(&recommendation.Timestamp, now), recommendation.Replicas)
+		}
+	}
+
+	writeScaleEvents(w, "Scale up events:", status.ScaleUpEvents, now)
+	writeScaleEvents(w, "Scale down events:", status.ScaleDownEvents, now)
+
+	fmt.Fprintln(w, "Decisions:")
+	if len(status.Decisions) == 0 {
+		fmt.Fprintf(w, "  %s\n", none)
+	} else {
+		fmt.Fprintln(w, "  TIME\tCURRENT\tWINNING METRIC\tDESIRED\tSTABILIZED\tNORMALIZED\tLIMIT")
+		for _, decision := range status.Decisions {
+			fmt.Fprintf(w, "  %s\t%d\t%s\t%d\t%d\t%d\t%s\n", formatTime(&decision.Timestamp, now), decision.CurrentReplicas, orNone(decision.WinningMetric),
+				decision.DesiredReplicas, decision.StabilizedReplicas, decision.NormalizedReplicas, describeLimit(&decision))
+		}
+	}
+
+	return w.Flush()
+}
+
+func writeScaleEvents(w io.Writer, title string, events []v1alpha1.ScaleChangeEvent, now time.Time) {
+	fmt.Fprintln(w, title)
+	if len(events) == 0 {
+		fmt.Fprintf(w, "  %s\n", none)
+		return
+	}
+
+	fmt.Fprintln(w, "  TIME\tCHANGE")
+	for _, event := range events {
+		fmt.Fprintf(w, "  %s\t%d\n", formatTime(&event.Timestamp, now), event.ReplicaChange)
+	}
+}
+
+// describeScaleRules formats the stabilization window and policies of a scaling direction
+func describeScaleRules(rules *v1alpha1.ScaleRules) string {
+	if rules == nil {
+		return none
+	}
+
+	if rules.SelectPolicy == v1alpha1.DisabledPolicySelect {
+		return fmt.Sprintf("stabilization %ds, disabled", rules.StabilizationWindowSeconds)
+	}
+
+	policies := make([]string, 0, len(rules.Policies))
+	for _, policy := range rules.Policies {
+		policies = append(policies, fmt.Sprintf("%s %d per %ds", policy.Type, policy.Value, policy.PeriodSeconds))
+	}
+
+	return fmt.Sprintf("stabilization %ds, %s of %s", rules.StabilizationWindowSeconds, rules.SelectPolicy, strings.Join(policies, ", "))
+}
+
+// describeLimit formats the limit reason of a decision with the policy which limited the replicas
+func describeLimit(decision *v1alpha1.ScalingDecision) string {
+	switch {
+	case decision.Error != "":
+		return fmt.Sprintf("Fallback: %s", decision.Error)
+	case decision.Limit != "":
+		return fmt.Sprintf("%s: %s", decision.LimitReason, decision.Limit)
+	}
+
+	return orNone(decision.LimitReason)
+}
+
+// formatTime formats a timestamp with the time passed since, e.g. '2021-07-05T10:00:00Z (5m ago)'
+func formatTime(t *metav1.Time, now time.Time) string {
+	if t == nil || t.IsZero() {
+		return none
+	}
+
+	return fmt.Sprintf("%s (%s ago)", t.UTC().Format(time.RFC3339), age(t, now))
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return none
+	}
+
+	return value
+}
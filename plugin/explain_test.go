@@ -0,0 +1,86 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+var _ = Describe("Explain", func() {
+	now := time.Date(2021, 7, 5, 10, 0, 0, 0, time.UTC)
+	ago := func(d time.Duration) metav1.Time {
+		return metav1.NewTime(now.Add(-d))
+	}
+
+	It("Explain autoscaler", func() {
+		item := &autoscaler{
+			Kind:      kratosKind,
+			Namespace: "default",
+			Name:      "nginx-scaler",
+			Spec: &v1alpha1.KratosSpec{
+				Target:      v1alpha1.ScaleTargetReference{Kind: "Deployment", Name: "nginx"},
+				Mode:        v1alpha1.ActiveAutoscalerMode,
+				Algorithm:   v1alpha1.Algorithm{Type: v1alpha1.HpaAlgorithmType},
+				MinReplicas: pointer.Int32Ptr(1),
+				MaxReplicas: 10,
+				Behavior: &v1alpha1.ScaleBehavior{
+					ScaleUp: &v1alpha1.ScaleRules{
+						SelectPolicy: v1alpha1.MaxPolicySelect,
+						Policies:     []v1alpha1.ScalingPolicy{{Type: v1alpha1.PodsScalingPolicy, Value: 4, PeriodSeconds: 60}},
+					},
+					ScaleDown: &v1alpha1.ScaleRules{StabilizationWindowSeconds: 300, SelectPolicy: v1alpha1.DisabledPolicySelect},
+				},
+			},
+			Status: &v1alpha1.KratosStatus{
+				CurrentReplicas: 5,
+				DesiredReplicas: 5,
+				LastScaleTime:   &metav1.Time{Time: now.Add(-time.Minute)},
+				Conditions: []metav1.Condition{
+					{Type: v1alpha1.AbleToScaleCondition, Status: metav1.ConditionTrue, Reason: "SucceededRescale", Message: "scaled target from 3 to 5 replicas", LastTransitionTime: ago(time.Minute)},
+				},
+				Recommendations: []v1alpha1.Recommendation{{Timestamp: ago(time.Minute), Replicas: 8}},
+				ScaleUpEvents:   []v1alpha1.ScaleChangeEvent{{Timestamp: ago(time.Minute), ReplicaChange: 2}},
+				Decisions: []v1alpha1.ScalingDecision{
+					{Timestamp: ago(time.Minute), CurrentReplicas: 3, WinningMetric: "cpu", DesiredReplicas: 8, StabilizedReplicas: 8, NormalizedReplicas: 5,
+						LimitReason: "ScaleUpLimit", Limit: "Pods 2 per 60s"},
+				},
+			},
+		}
+
+		output := &bytes.Buffer{}
+		Expect(writeExplanation(output, item, now)).To(Succeed())
+
+		Expect(output.String()).To(ContainSubstring("Replicas:    current 5, desired 5, min 1, max 10\n"))
+		Expect(output.String()).To(ContainSubstring("Last scale:  2021-07-05T09:59:00Z (60s ago)\n"))
+		Expect(output.String()).To(ContainSubstring("  Scale up:    stabilization 0s, Max of Pods 4 per 60s\n"))
+		Expect(output.String()).To(ContainSubstring("  Scale down:  stabilization 300s, disabled\n"))
+		Expect(output.String()).To(MatchRegexp(`AbleToScale\s+True\s+SucceededRescale\s+60s\s+scaled target from 3 to 5 replicas`))
+		Expect(output.String()).To(MatchRegexp(`Recommendations:\n\s+TIME\s+REPLICAS\n\s+2021-07-05T09:59:00Z \(60s ago\)\s+8\n`))
+		Expect(output.String()).To(MatchRegexp(`Scale down events:\n\s+<none>\n`))
+		Expect(output.String()).To(MatchRegexp(`cpu\s+8\s+8\s+5\s+ScaleUpLimit: Pods 2 per 60s`))
+	})
+
+	It("Describe limit", func() {
+		Expect(describeLimit(&v1alpha1.ScalingDecision{})).To(Equal(none))
+		Expect(describeLimit(&v1alpha1.ScalingDecision{LimitReason: "MaxReplicas"})).To(Equal("MaxReplicas"))
+		Expect(describeLimit(&v1alpha1.ScalingDecision{Error: "none of the 1 metrics could be evaluated"})).To(Equal("Fallback: none of the 1 metrics could be evaluated"))
+	})
+})
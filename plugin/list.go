@@ -0,0 +1,126 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package plugin
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+const none = "<none>"
+
+func runList(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("list", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+
+	options := addClientFlags(flags)
+	allNamespaces := false
+	flags.BoolVar(&allNamespaces, "all-namespaces", false, "List the autoscalers of all namespaces.")
+	flags.BoolVar(&allNamespaces, "A", false, "Shorthand for --all-namespaces.")
+
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: kubectl kratos list [flags]\n\n"+
+			"Lists Kratos resources and ConfigMaps with a 'kratosSpec' key with their replicas and last scale time.\n\n")
+		flags.PrintDefaults()
+	}
+
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		if isHelp(err) {
+			return nil
+		}
+		return err
+	}
+
+	if len(positional) > 0 {
+		return fmt.Errorf("unexpected arguments: %v", positional)
+	}
+
+	c, namespace, err := options.newClient()
+	if err != nil {
+		return err
+	}
+
+	if allNamespaces {
+		namespace = ""
+	}
+
+	autoscalers, err := listAutoscalers(context.Background(), c, namespace)
+	if err != nil {
+		return err
+	}
+
+	if len(autoscalers) == 0 {
+		fmt.Fprintln(stdout, "No autoscalers found.")
+		return nil
+	}
+
+	return writeList(stdout, autoscalers, time.Now())
+}
+
+// writeList writes a row with the replicas and last scale time of every autoscaler
+func writeList(writer io.Writer, autoscalers []autoscaler, now time.Time) error {
+	tableWriter := tabwriter.NewWriter(writer, 0, 8, 2, ' ', 0)
+
+	fmt.Fprintln(tableWriter, "NAMESPACE\tNAME\tKIND\tTARGET\tCURRENT\tDESIRED\tMIN\tMAX\tLAST SCALE")
+
+	for _, item := range autoscalers {
+		if item.Error != nil {
+			fmt.Fprintf(tableWriter, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", item.Namespace, item.Name, item.Kind, "<invalid>", none, none, none, none, none)
+			continue
+		}
+
+		fmt.Fprintf(tableWriter, "%s\t%s\t%s\t%s\t%d\t%d\t%d\t%d\t%s\n",
+			item.Namespace, item.Name, item.Kind, describeScaleTarget(&item.Spec.Target),
+			item.Status.CurrentReplicas, item.Status.DesiredReplicas, item.Spec.GetMinReplicas(), item.Spec.MaxReplicas,
+			age(item.Status.LastScaleTime, now))
+	}
+
+	return tableWriter.Flush()
+}
+
+// describeScaleTarget formats the target as kind/name
+func describeScaleTarget(target *v1alpha1.ScaleTargetReference) string {
+	if target.Name == "" {
+		return none
+	}
+
+	return fmt.Sprintf("%s/%s", target.Kind, target.Name)
+}
+
+// age formats the time since t like kubectl, e.g. '5m'
+func age(t *metav1.Time, now time.Time) string {
+	if t == nil || t.IsZero() {
+		return none
+	}
+
+	return duration.HumanDuration(now.Sub(t.Time))
+}
+
+func formatInt32(value *int32) string {
+	if value == nil {
+		return none
+	}
+
+	return strconv.Itoa(int(*value))
+}
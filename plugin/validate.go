@@ -0,0 +1,185 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package plugin
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/adobe/kratos/api/common"
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/adobe/kratos/replicas"
+	"github.com/adobe/kratos/scale"
+	"github.com/adobe/kratos/webhooks"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
+)
+
+var documentSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+
+// validation is the outcome of validating a single document, skipped documents aren't autoscalers
+type validation struct {
+	Kind    string
+	Name    string
+	Skipped bool
+	Errors  []string
+}
+
+func runValidate(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+
+	filename := ""
+	flags.StringVar(&filename, "filename", "", "YAML file with Kratos resources or ConfigMaps, documents of other kinds are skipped.")
+	flags.StringVar(&filename, "f", "", "Shorthand for --filename.")
+	stabilizationWindowSeconds := flags.Int("stabilization-window-seconds", 300, "Default stabilization window in seconds, as set on the operator.")
+
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: kubectl kratos validate -f <file> [flags]\n\n"+
+			"Validates the autoscalers of a local file without a cluster. Specs are read and defaulted by the same code\n"+
+			"as the operator and checked like the admission webhooks do.\n\n")
+		flags.PrintDefaults()
+	}
+
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		if isHelp(err) {
+			return nil
+		}
+		return err
+	}
+
+	if filename == "" || len(positional) > 0 {
+		flags.Usage()
+		return fmt.Errorf("a single file is required with --filename")
+	}
+
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	defaultsUpdater := scale.NewDefaultsUpdater(&common.KratosParameters{StabilizationWindowSeconds: int32(*stabilizationWindowSeconds)})
+	validations := validateDocuments(content, defaultsUpdater, replicas.NewAlgorithmRegistry())
+
+	validated := 0
+	invalid := 0
+	for _, result := range validations {
+		if result.Skipped {
+			continue
+		}
+
+		validated++
+		if len(result.Errors) == 0 {
+			fmt.Fprintf(stdout, "%s/%s: valid\n", result.Kind, result.Name)
+			continue
+		}
+
+		invalid++
+		fmt.Fprintf(stdout, "%s/%s: invalid\n", result.Kind, result.Name)
+		for _, validationError := range result.Errors {
+			fmt.Fprintf(stdout, "  %s\n", validationError)
+		}
+	}
+
+	switch {
+	case validated == 0:
+		return fmt.Errorf("no Kratos resource or ConfigMap with a '%s' key found in %s", specKey, filename)
+	case invalid > 0:
+		return fmt.Errorf("%d of %d autoscalers in %s are invalid", invalid, validated, filename)
+	}
+
+	return nil
+}
+
+// validateDocuments validates every document of a YAML stream
+func validateDocuments(content []byte, defaultsUpdater *scale.DefaultsUpdater, algorithms *replicas.AlgorithmRegistry) []validation {
+	result := make([]validation, 0)
+
+	for _, document := range documentSeparator.Split(string(content), -1) {
+		if strings.TrimSpace(document) == "" {
+			continue
+		}
+
+		result = append(result, validateDocument([]byte(document), defaultsUpdater, algorithms))
+	}
+
+	return result
+}
+
+// validateDocument validates a Kratos resource or a ConfigMap with a 'kratosSpec' key. Unknown fields are errors
+// like in the admission webhooks. ConfigMaps are also read the way the operator reads them, including their status.
+func validateDocument(document []byte, defaultsUpdater *scale.DefaultsUpdater, algorithms *replicas.AlgorithmRegistry) validation {
+	object := &metav1.PartialObjectMetadata{}
+	if err := yaml.Unmarshal(document, object); err != nil {
+		return validation{Kind: "<unknown>", Name: "<unknown>", Errors: []string{fmt.Sprintf("can't parse document: %v", err)}}
+	}
+
+	result := validation{Kind: object.Kind, Name: object.Name}
+
+	var spec *v1alpha1.KratosSpec
+	var specPath *field.Path
+
+	switch object.Kind {
+	case kratosKind:
+		kratos := &v1alpha1.Kratos{}
+		if err := yaml.UnmarshalStrict(document, kratos); err != nil {
+			result.Errors = append(result.Errors, err.Error())
+			return result
+		}
+		spec = &kratos.Spec
+		specPath = field.NewPath("spec")
+	case configMapKind:
+		configMap := &corev1.ConfigMap{}
+		if err := yaml.Unmarshal(document, configMap); err != nil {
+			result.Errors = append(result.Errors, err.Error())
+			return result
+		}
+
+		specAsString, found := configMap.Data[specKey]
+		if !found {
+			result.Skipped = true
+			return result
+		}
+
+		var err error
+		spec, _, err = scale.UnmarshallConfigMapData(configMap.Data)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("can't be read by the operator: %v", err))
+			return result
+		}
+
+		specPath = field.NewPath("data").Key(specKey)
+		if err := yaml.UnmarshalStrict([]byte(specAsString), &v1alpha1.KratosSpec{}); err != nil {
+			result.Errors = append(result.Errors, field.Invalid(specPath, "", err.Error()).Error())
+		}
+	default:
+		result.Skipped = true
+		return result
+	}
+
+	defaultsUpdater.SetDefaults(spec)
+
+	for _, err := range webhooks.ValidateSpec(spec, algorithms, specPath) {
+		result.Errors = append(result.Errors, err.Error())
+	}
+
+	return result
+}
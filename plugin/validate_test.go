@@ -0,0 +1,127 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/adobe/kratos/api/common"
+	"github.com/adobe/kratos/replicas"
+	"github.com/adobe/kratos/scale"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const validKratos = `apiVersion: scaling.core.adobe.com/v1alpha1
+kind: Kratos
+metadata:
+  name: nginx-scaler
+spec:
+  target:
+    kind: Deployment
+    name: nginx
+  minReplicas: 1
+  maxReplicas: 10
+  metrics:
+    - type: Resource
+      resource:
+        name: cpu
+        target:
+          type: Utilization
+          averageUtilization: 70
+`
+
+const invalidConfigMap = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: nginx-scaler
+data:
+  kratosSpec: |
+    target:
+      kind: Deployment
+      name: nginx
+    minReplicas: 5
+    maxReplicas: 2
+    unknownField: true
+    metrics:
+      - type: Resource
+        resource:
+          name: cpu
+          target:
+            type: Utilization
+            averageUtilization: 70
+`
+
+var _ = Describe("Validate", func() {
+	defaultsUpdater := scale.NewDefaultsUpdater(&common.KratosParameters{StabilizationWindowSeconds: 300})
+	algorithms := replicas.NewAlgorithmRegistry()
+
+	It("Validate documents", func() {
+		deployment := "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: nginx\n"
+		validations := validateDocuments([]byte(validKratos+"---\n"+invalidConfigMap+"---\n"+deployment), defaultsUpdater, algorithms)
+
+		Expect(validations).To(HaveLen(3))
+
+		Expect(validations[0].Kind).To(Equal("Kratos"))
+		Expect(validations[0].Errors).To(BeEmpty())
+
+		Expect(validations[1].Kind).To(Equal("ConfigMap"))
+		Expect(validations[1].Errors).To(ConsistOf(
+			ContainSubstring(`data[kratosSpec]: Invalid value: "": error unmarshaling JSON: while decoding JSON: json: unknown field "unknownField"`),
+			ContainSubstring("data[kratosSpec].minReplicas"),
+		))
+
+		Expect(validations[2].Skipped).To(BeTrue(), "documents of other kinds should be skipped")
+	})
+
+	It("Report negative values", func() {
+		kratos := strings.Replace(validKratos, "  minReplicas: 1\n", "  minReplicas: 1\n  idleCooldownSeconds: -60\n  stabilizationWindowSeconds: -1\n", 1)
+
+		validations := validateDocuments([]byte(kratos), defaultsUpdater, algorithms)
+
+		Expect(validations).To(HaveLen(1))
+		Expect(validations[0].Errors).To(ConsistOf(
+			ContainSubstring("spec.idleCooldownSeconds"),
+			ContainSubstring("spec.stabilizationWindowSeconds"),
+		), "negative values should not be replaced by defaults")
+	})
+
+	It("Report specs the operator can't read", func() {
+		configMap := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: nginx-scaler\ndata:\n  kratosSpec: '{'\n"
+
+		validations := validateDocuments([]byte(configMap), defaultsUpdater, algorithms)
+
+		Expect(validations).To(HaveLen(1))
+		Expect(validations[0].Errors).To(ConsistOf(ContainSubstring("can't be read by the operator")))
+	})
+
+	It("Validate command", func() {
+		dir, err := ioutil.TempDir("", "kratos-validate")
+		Expect(err).To(BeNil())
+		defer os.RemoveAll(dir)
+
+		file := filepath.Join(dir, "scalers.yaml")
+		Expect(ioutil.WriteFile(file, []byte(validKratos+"---\n"+invalidConfigMap), 0644)).To(Succeed())
+
+		output := &bytes.Buffer{}
+		err = Run([]string{"validate", "-f", file}, output)
+
+		Expect(err).To(MatchError(ContainSubstring("1 of 2 autoscalers")))
+		Expect(output.String()).To(HavePrefix("Kratos/nginx-scaler: valid\nConfigMap/nginx-scaler: invalid\n"))
+	})
+})
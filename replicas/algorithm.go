@@ -0,0 +1,229 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package replicas
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/adobe/kratos/metrics"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	toleranceOption = "tolerance"
+	roundingOption  = "rounding"
+	stepOption      = "step"
+
+	defaultTolerance = 0.1
+)
+
+var roundingModes = map[string]func(float64) float64{
+	"ceil":  math.Ceil,
+	"floor": math.Floor,
+	"round": math.Round,
+}
+
+// Algorithm calculates a replica proposal for a single metric
+type Algorithm interface {
+	CalculateReplicas(currentReplicas int32, requestedResources map[string]*corev1.ResourceList, scaleMetric v1alpha1.ScaleMetric, metricValues []metrics.MetricValue) (int32, error)
+}
+
+// Calculation explains a replica proposal
+type Calculation struct {
+	Replicas int32
+	// ratio of the metric value to its target, nil when the algorithm doesn't use one
+	UsageRatio *float64
+	// true when the current replicas are kept because the usage ratio is within the tolerance
+	WithinTolerance bool
+}
+
+// ExplainingAlgorithm is implemented by algorithms which explain how their proposal was calculated
+type ExplainingAlgorithm interface {
+	Algorithm
+	ExplainReplicas(currentReplicas int32, requestedResources map[string]*corev1.ResourceList, scaleMetric v1alpha1.ScaleMetric, metricValues []metrics.MetricValue) (Calculation, error)
+}
+
+// ExplainReplicas calculates the proposal of an algorithm, only the replicas are known for algorithms which don't explain their proposals
+func ExplainReplicas(algorithm Algorithm, currentReplicas int32, requestedResources map[string]*corev1.ResourceList, scaleMetric v1alpha1.ScaleMetric, metricValues []metrics.MetricValue) (Calculation, error) {
+	if explaining, ok := algorithm.(ExplainingAlgorithm); ok {
+		return explaining.ExplainReplicas(currentReplicas, requestedResources, scaleMetric, metricValues)
+	}
+
+	replicaCount, err := algorithm.CalculateReplicas(currentReplicas, requestedResources, scaleMetric, metricValues)
+	return Calculation{Replicas: replicaCount}, err
+}
+
+// AlgorithmFactory creates an algorithm from the options of the spec, options must be validated by the factory
+type AlgorithmFactory func(options map[string]string) (Algorithm, error)
+
+// AlgorithmRegistry resolves the algorithm of a spec by its type
+type AlgorithmRegistry struct {
+	factories map[string]AlgorithmFactory
+}
+
+// NewAlgorithmRegistry creates a registry with the built-in algorithms registered
+func NewAlgorithmRegistry() *AlgorithmRegistry {
+	registry := &AlgorithmRegistry{
+		factories: make(map[string]AlgorithmFactory),
+	}
+
+	registry.Register(v1alpha1.HpaAlgorithmType, newHpaAlgorithm)
+	registry.Register(v1alpha1.StepAlgorithmType, newStepAlgorithm)
+
+	return registry
+}
+
+// Register adds a factory for the algorithm type, replacing an existing one
+func (r *AlgorithmRegistry) Register(algorithmType string, factory AlgorithmFactory) {
+	r.factories[algorithmType] = factory
+}
+
+// GetAlgorithm creates the algorithm for the spec, returns an error for unknown types or invalid options
+func (r *AlgorithmRegistry) GetAlgorithm(algorithm *v1alpha1.Algorithm) (Algorithm, error) {
+	factory, found := r.factories[algorithm.Type]
+
+	if !found {
+		return nil, fmt.Errorf("unknown algorithm type: '%s', supported types: %s", algorithm.Type, strings.Join(r.types(), ", "))
+	}
+
+	result, err := factory(algorithm.Options)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid options for algorithm type '%s': %v", algorithm.Type, err)
+	}
+
+	return result, nil
+}
+
+func (r *AlgorithmRegistry) types() []string {
+	result := make([]string, 0, len(r.factories))
+	for algorithmType := range r.factories {
+		result = append(result, algorithmType)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// newHpaAlgorithm creates the HorizontalPodAutoscaler like calculator, supported options: tolerance, rounding
+func newHpaAlgorithm(options map[string]string) (Algorithm, error) {
+	err := checkOptions(options, toleranceOption, roundingOption)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseReplicaCalculator(options)
+}
+
+type stepAlgorithm struct {
+	calculator *ReplicaCalculator
+	step       int32
+}
+
+// newStepAlgorithm creates the step algorithm, supported options: step (required), tolerance, rounding
+func newStepAlgorithm(options map[string]string) (Algorithm, error) {
+	err := checkOptions(options, stepOption, toleranceOption, roundingOption)
+	if err != nil {
+		return nil, err
+	}
+
+	stepAsString, found := options[stepOption]
+	if !found {
+		return nil, fmt.Errorf("'%s' option is required", stepOption)
+	}
+
+	step, err := strconv.ParseInt(stepAsString, 10, 32)
+	if err != nil || step < 1 {
+		return nil, fmt.Errorf("'%s' option must be a positive integer, got: '%s'", stepOption, stepAsString)
+	}
+
+	calculator, err := parseReplicaCalculator(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stepAlgorithm{
+		calculator: calculator,
+		step:       int32(step),
+	}, nil
+}
+
+// CalculateReplicas rounds the hpa replica proposal up to the next multiple of step
+func (a *stepAlgorithm) CalculateReplicas(currentReplicas int32, requestedResources map[string]*corev1.ResourceList, scaleMetric v1alpha1.ScaleMetric, metricValues []metrics.MetricValue) (int32, error) {
+	calculation, err := a.ExplainReplicas(currentReplicas, requestedResources, scaleMetric, metricValues)
+	return calculation.Replicas, err
+}
+
+// ExplainReplicas explains the hpa replica proposal rounded up to the next multiple of step
+func (a *stepAlgorithm) ExplainReplicas(currentReplicas int32, requestedResources map[string]*corev1.ResourceList, scaleMetric v1alpha1.ScaleMetric, metricValues []metrics.MetricValue) (Calculation, error) {
+	calculation, err := a.calculator.ExplainReplicas(currentReplicas, requestedResources, scaleMetric, metricValues)
+
+	if err != nil {
+		return calculation, err
+	}
+
+	calculation.Replicas = ((calculation.Replicas + a.step - 1) / a.step) * a.step
+	return calculation, nil
+}
+
+func parseReplicaCalculator(options map[string]string) (*ReplicaCalculator, error) {
+	tolerance := defaultTolerance
+	if toleranceAsString, found := options[toleranceOption]; found {
+		value, err := strconv.ParseFloat(toleranceAsString, 64)
+		if err != nil || value < 0 || value >= 1 {
+			return nil, fmt.Errorf("'%s' option must be a number in range [0, 1), got: '%s'", toleranceOption, toleranceAsString)
+		}
+		tolerance = value
+	}
+
+	round := math.Ceil
+	if rounding, found := options[roundingOption]; found {
+		roundingMode, supported := roundingModes[rounding]
+		if !supported {
+			return nil, fmt.Errorf("'%s' option must be one of ceil, floor, round, got: '%s'", roundingOption, rounding)
+		}
+		round = roundingMode
+	}
+
+	return newReplicaCalculator(tolerance, round), nil
+}
+
+func checkOptions(options map[string]string, supported ...string) error {
+	unknown := make([]string, 0)
+
+	for option := range options {
+		found := false
+		for _, name := range supported {
+			if option == name {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			unknown = append(unknown, option)
+		}
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown options: %s, supported options: %s", strings.Join(unknown, ", "), strings.Join(supported, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package replicas
+
+import (
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/adobe/kratos/metrics"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var prometheusScaleMetric = v1alpha1.ScaleMetric{
+	Type: v1alpha1.PrometheusScaleMetricType,
+	Prometheus: &v1alpha1.PrometheusMetricSource{
+		Target: v1alpha1.MetricTarget{
+			Type:         v1alpha1.AverageValueMetricType,
+			AverageValue: resource.NewQuantity(4, resource.DecimalSI),
+		},
+	},
+}
+
+// fixedAlgorithm proposes the same replicas for every metric without explaining them
+type fixedAlgorithm int32
+
+func (a fixedAlgorithm) CalculateReplicas(_ int32, _ map[string]*corev1.ResourceList, _ v1alpha1.ScaleMetric, _ []metrics.MetricValue) (int32, error) {
+	return int32(a), nil
+}
+
+var _ = Describe("AlgorithmRegistry", func() {
+	It("Unknown algorithm type", func() {
+		registry := NewAlgorithmRegistry()
+
+		_, err := registry.GetAlgorithm(&v1alpha1.Algorithm{Type: "unknown"})
+
+		Expect(err).NotTo(BeNil(), "unknown algorithm type should result in error")
+		Expect(err.Error()).To(ContainSubstring("hpa, step"), "error should list supported types")
+	})
+
+	It("Unknown option", func() {
+		registry := NewAlgorithmRegistry()
+
+		_, err := registry.GetAlgorithm(&v1alpha1.Algorithm{Type: v1alpha1.HpaAlgorithmType, Options: map[string]string{"tolerence": "0.2"}})
+
+		Expect(err).NotTo(BeNil(), "unknown option should result in error")
+	})
+
+	It("Invalid tolerance and rounding", func() {
+		registry := NewAlgorithmRegistry()
+
+		_, err := registry.GetAlgorithm(&v1alpha1.Algorithm{Type: v1alpha1.HpaAlgorithmType, Options: map[string]string{toleranceOption: "1.5"}})
+		Expect(err).NotTo(BeNil(), "tolerance out of range should result in error")
+
+		_, err = registry.GetAlgorithm(&v1alpha1.Algorithm{Type: v1alpha1.HpaAlgorithmType, Options: map[string]string{roundingOption: "up"}})
+		Expect(err).NotTo(BeNil(), "unsupported rounding should result in error")
+	})
+
+	It("Hpa algorithm with rounding", func() {
+		registry := NewAlgorithmRegistry()
+
+		metricValues := []metrics.MetricValue{{Value: 10}}
+
+		ceilAlgorithm, err := registry.GetAlgorithm(&v1alpha1.Algorithm{Type: v1alpha1.HpaAlgorithmType})
+		Expect(err).To(BeNil(), "no errors expected for default options")
+		replicas, err := ceilAlgorithm.CalculateReplicas(1, noRequestedResources, prometheusScaleMetric, metricValues)
+		Expect(err).To(BeNil())
+		Expect(replicas).To(Equal(int32(3)), "replicas should be rounded up by default")
+
+		floorAlgorithm, err := registry.GetAlgorithm(&v1alpha1.Algorithm{Type: v1alpha1.HpaAlgorithmType, Options: map[string]string{roundingOption: "floor", toleranceOption: "0"}})
+		Expect(err).To(BeNil(), "no errors expected for valid options")
+		replicas, err = floorAlgorithm.CalculateReplicas(1, noRequestedResources, prometheusScaleMetric, metricValues)
+		Expect(err).To(BeNil())
+		Expect(replicas).To(Equal(int32(2)), "replicas should be rounded down")
+	})
+
+	It("Step algorithm", func() {
+		registry := NewAlgorithmRegistry()
+
+		_, err := registry.GetAlgorithm(&v1alpha1.Algorithm{Type: v1alpha1.StepAlgorithmType})
+		Expect(err).NotTo(BeNil(), "step option is required")
+
+		_, err = registry.GetAlgorithm(&v1alpha1.Algorithm{Type: v1alpha1.StepAlgorithmType, Options: map[string]string{stepOption: "0"}})
+		Expect(err).NotTo(BeNil(), "step must be positive")
+
+		algorithm, err := registry.GetAlgorithm(&v1alpha1.Algorithm{Type: v1alpha1.StepAlgorithmType, Options: map[string]string{stepOption: "5"}})
+		Expect(err).To(BeNil(), "no errors expected for valid options")
+
+		replicas, err := algorithm.CalculateReplicas(1, noRequestedResources, prometheusScaleMetric, []metrics.MetricValue{{Value: 10}})
+		Expect(err).To(BeNil())
+		Expect(replicas).To(Equal(int32(5)), "replicas should be rounded up to a multiple of step")
+	})
+
+	It("Explain replicas", func() {
+		algorithm, err := NewAlgorithmRegistry().GetAlgorithm(&v1alpha1.Algorithm{Type: v1alpha1.HpaAlgorithmType})
+		Expect(err).To(BeNil())
+
+		calculation, err := ExplainReplicas(algorithm, 2, noRequestedResources, prometheusScaleMetric, []metrics.MetricValue{{Value: 10}})
+		Expect(err).To(BeNil())
+		Expect(calculation.Replicas).To(Equal(int32(3)))
+		Expect(*calculation.UsageRatio).To(BeNumerically("~", 1.25, 0.001))
+		Expect(calculation.WithinTolerance).To(BeFalse())
+
+		calculation, err = ExplainReplicas(algorithm, 2, noRequestedResources, prometheusScaleMetric, []metrics.MetricValue{{Value: 8.4}})
+		Expect(err).To(BeNil())
+		Expect(calculation.Replicas).To(Equal(int32(2)), "current replicas should be kept within tolerance")
+		Expect(*calculation.UsageRatio).To(BeNumerically("~", 1.05, 0.001))
+		Expect(calculation.WithinTolerance).To(BeTrue())
+
+		calculation, err = ExplainReplicas(fixedAlgorithm(7), 2, noRequestedResources, prometheusScaleMetric, []metrics.MetricValue{{Value: 10}})
+		Expect(err).To(BeNil())
+		Expect(calculation.Replicas).To(Equal(int32(7)))
+		Expect(calculation.UsageRatio).To(BeNil(), "only the replicas are known for algorithms which don't explain them")
+	})
+})
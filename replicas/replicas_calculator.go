@@ -20,129 +20,188 @@ import (
 	"github.com/adobe/kratos/api/v1alpha1"
 	"github.com/adobe/kratos/metrics"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 type ReplicaCalculator struct {
 	tolerance float64
+	round     func(float64) float64
 }
 
 func NewReplicaCalculator(tolerance float64) *ReplicaCalculator {
+	return newReplicaCalculator(tolerance, math.Ceil)
+}
+
+func newReplicaCalculator(tolerance float64, round func(float64) float64) *ReplicaCalculator {
 	return &ReplicaCalculator{
 		tolerance: tolerance,
+		round:     round,
 	}
 }
 
 func (rc *ReplicaCalculator) CalculateReplicas(currentReplicas int32, requestedResources map[string]*corev1.ResourceList, scaleMetric v1alpha1.ScaleMetric, metricValues []metrics.MetricValue) (int32, error) {
+	calculation, err := rc.ExplainReplicas(currentReplicas, requestedResources, scaleMetric, metricValues)
+	return calculation.Replicas, err
+}
+
+// ExplainReplicas calculates the replica proposal along with the usage ratio and the tolerance verdict
+func (rc *ReplicaCalculator) ExplainReplicas(currentReplicas int32, requestedResources map[string]*corev1.ResourceList, scaleMetric v1alpha1.ScaleMetric, metricValues []metrics.MetricValue) (Calculation, error) {
 	metricTarget, err := scaleMetric.GetMetricTarget()
 	if err != nil {
-		return 0, fmt.Errorf("unknown metric type: %s", scaleMetric.Type)
+		return Calculation{}, err
 	}
 
 	switch metricTarget.Type {
 	case v1alpha1.ValueMetricType:
 		return rc.calculateValue(currentReplicas, metricTarget, metricValues)
 	case v1alpha1.AverageValueMetricType:
+		if scaleMetric.Type == v1alpha1.PodScaleMetricType {
+			return rc.calculatePodsAverageValue(currentReplicas, metricTarget, metricValues)
+		}
 		return rc.calculateAverageValue(currentReplicas, metricTarget, metricValues)
 	case v1alpha1.UtilizationMetricType:
+		if scaleMetric.Resource == nil {
+			return Calculation{}, fmt.Errorf("utilization target is only supported for resource metrics, got: %s", scaleMetric.Type)
+		}
 		return rc.calculateUtilization(currentReplicas, scaleMetric, requestedResources, metricValues)
 	}
-	return 0, fmt.Errorf("replica calculator not implemented yet: %s", metricTarget.Type)
+	return Calculation{}, fmt.Errorf("replica calculator not implemented yet: %s", metricTarget.Type)
 }
 
-func (rc *ReplicaCalculator) calculateUtilization(currentReplicas int32, scaleMetric v1alpha1.ScaleMetric, requestedResources map[string]*corev1.ResourceList, metricValues []metrics.MetricValue) (int32, error) {
-	utilization := int64(0)
-	for _, metricValue := range metricValues {
-		utilization = utilization + metricValue.Value
-	}
+func (rc *ReplicaCalculator) calculateUtilization(currentReplicas int32, scaleMetric v1alpha1.ScaleMetric, requestedResources map[string]*corev1.ResourceList, metricValues []metrics.MetricValue) (Calculation, error) {
+	utilization := sumMetricValues(metricValues)
 
 	totalResources, err := rc.getPodRequestedResource(requestedResources[scaleMetric.Resource.Container], scaleMetric.Resource.Name)
 
 	if err != nil {
-		return currentReplicas, err
+		return Calculation{Replicas: currentReplicas}, err
 	}
 
 	if totalResources == 0 {
-		return currentReplicas, fmt.Errorf("no resource requests configured for %s", scaleMetric.Resource.Name)
+		return Calculation{Replicas: currentReplicas}, fmt.Errorf("no resource requests configured for %s", scaleMetric.Resource.Name)
 	}
 
 	metricTarget, _ := scaleMetric.GetMetricTarget()
-	usageRatio := (float64(utilization) / totalResources) / (float64(*metricTarget.AverageUtilization) / 100.0)
+	usageRatio := (utilization / totalResources) / (float64(*metricTarget.AverageUtilization) / 100.0)
 
 	if math.Abs(1.0-usageRatio) <= rc.tolerance {
 		// return the current replicas if the change would be too small
-		return currentReplicas, nil
+		return Calculation{Replicas: currentReplicas, UsageRatio: &usageRatio, WithinTolerance: true}, nil
 	}
 
-	replicaCount := int32(math.Ceil(usageRatio * float64(currentReplicas)))
+	replicaCount := int32(rc.round(usageRatio * float64(currentReplicas)))
 
-	return replicaCount, nil
+	return Calculation{Replicas: replicaCount, UsageRatio: &usageRatio}, nil
 
 }
 
 func (rc *ReplicaCalculator) getPodRequestedResource(resources *corev1.ResourceList, resourceName corev1.ResourceName) (float64, error) {
 	switch resourceName {
 	case corev1.ResourceCPU:
-		return float64(resources.Cpu().Value()), nil
+		return metrics.QuantityValue(resources.Cpu()), nil
 	case corev1.ResourceMemory:
-		return float64(resources.Memory().Value()), nil
+		return metrics.QuantityValue(resources.Memory()), nil
 	case corev1.ResourceStorage:
-		return float64(resources.Storage().Value()), nil
+		return metrics.QuantityValue(resources.Storage()), nil
 	case corev1.ResourceEphemeralStorage:
-		return float64(resources.StorageEphemeral().Value()), nil
+		return metrics.QuantityValue(resources.StorageEphemeral()), nil
 	case corev1.ResourcePods:
-		return float64(resources.Pods().Value()), nil
+		return metrics.QuantityValue(resources.Pods()), nil
 	}
 
 	return 0, fmt.Errorf("unknown resource metric type: %s", resourceName)
 }
 
-func (rc *ReplicaCalculator) calculateValue(currentReplicas int32, metricTarget *v1alpha1.MetricTarget, metricValues []metrics.MetricValue) (int32, error) {
-	utilization := int64(0)
-	for _, metricValue := range metricValues {
-		utilization = utilization + metricValue.Value
+func (rc *ReplicaCalculator) calculateValue(currentReplicas int32, metricTarget *v1alpha1.MetricTarget, metricValues []metrics.MetricValue) (Calculation, error) {
+	targetValue, err := getTargetValue(metricTarget.Value, "value")
+	if err != nil {
+		return Calculation{}, err
 	}
 
-	usageRatio := float64(utilization) / float64(metricTarget.Value.Value())
+	utilization := sumMetricValues(metricValues)
 
-	replicaCount, err := rc.getUsageRatioReplicaCount(currentReplicas, usageRatio)
+	usageRatio := utilization / targetValue
 
-	if err != nil {
-		return 0, err
-	}
-
-	return replicaCount, nil
+	return rc.getUsageRatioReplicaCount(currentReplicas, usageRatio), nil
 }
 
-func (rc *ReplicaCalculator) calculateAverageValue(currentReplicas int32, metricTarget *v1alpha1.MetricTarget, metricValues []metrics.MetricValue) (replicaCount int32, err error) {
-	utilization := int64(0)
-	for _, metricValue := range metricValues {
-		utilization = utilization + metricValue.Value
+func (rc *ReplicaCalculator) calculateAverageValue(currentReplicas int32, metricTarget *v1alpha1.MetricTarget, metricValues []metrics.MetricValue) (Calculation, error) {
+	targetAverageValue, err := getTargetValue(metricTarget.AverageValue, "averageValue")
+	if err != nil {
+		return Calculation{}, err
 	}
 
+	utilization := sumMetricValues(metricValues)
+
 	// update number of replicas if the change is large enough
 	if currentReplicas != 0 {
-		usageRatio := float64(utilization) / (float64(metricTarget.AverageValue.Value()) * float64(currentReplicas))
+		usageRatio := utilization / (targetAverageValue * float64(currentReplicas))
 		if math.Abs(1.0-usageRatio) <= rc.tolerance {
 			// return the current replicas if the change would be too small
-			return currentReplicas, nil
+			return Calculation{Replicas: currentReplicas, UsageRatio: &usageRatio, WithinTolerance: true}, nil
 		}
+		return Calculation{Replicas: int32(rc.round(utilization / targetAverageValue)), UsageRatio: &usageRatio}, nil
 	}
-	replicaCount = int32(math.Ceil(float64(utilization) / float64(metricTarget.AverageValue.Value())))
 
-	return replicaCount, err
+	return Calculation{Replicas: int32(rc.round(utilization / targetAverageValue))}, nil
 }
 
-func (rc *ReplicaCalculator) getUsageRatioReplicaCount(currentReplicas int32, usageRatio float64) (replicaCount int32, err error) {
-	if currentReplicas != 0 {
-		if math.Abs(1.0-usageRatio) <= rc.tolerance {
-			// return the current replicas if the change would be too small
-			return currentReplicas, nil
-		}
-		replicaCount = int32(math.Ceil(usageRatio * float64(currentReplicas)))
-	} else {
+// calculatePodsAverageValue calculates the replicas from the values of the pods which report the metric, like the
+// HorizontalPodAutoscaler, pods without a value aren't counted as idle
+func (rc *ReplicaCalculator) calculatePodsAverageValue(currentReplicas int32, metricTarget *v1alpha1.MetricTarget, metricValues []metrics.MetricValue) (Calculation, error) {
+	targetAverageValue, err := getTargetValue(metricTarget.AverageValue, "averageValue")
+	if err != nil {
+		return Calculation{}, err
+	}
+
+	if len(metricValues) == 0 {
+		return Calculation{Replicas: currentReplicas}, fmt.Errorf("no pod reports the metric")
+	}
+
+	reportingPods := float64(len(metricValues))
+	usageRatio := sumMetricValues(metricValues) / reportingPods / targetAverageValue
+
+	if currentReplicas != 0 && math.Abs(1.0-usageRatio) <= rc.tolerance {
+		// return the current replicas if the change would be too small
+		return Calculation{Replicas: currentReplicas, UsageRatio: &usageRatio, WithinTolerance: true}, nil
+	}
+
+	return Calculation{Replicas: int32(rc.round(usageRatio * reportingPods)), UsageRatio: &usageRatio}, nil
+}
+
+func (rc *ReplicaCalculator) getUsageRatioReplicaCount(currentReplicas int32, usageRatio float64) Calculation {
+	if currentReplicas == 0 {
 		// Scale to zero or n pods depending on usageRatio
-		replicaCount = int32(math.Ceil(usageRatio))
+		return Calculation{Replicas: int32(rc.round(usageRatio)), UsageRatio: &usageRatio}
+	}
+
+	if math.Abs(1.0-usageRatio) <= rc.tolerance {
+		// return the current replicas if the change would be too small
+		return Calculation{Replicas: currentReplicas, UsageRatio: &usageRatio, WithinTolerance: true}
 	}
 
-	return replicaCount, err
+	return Calculation{Replicas: int32(rc.round(usageRatio * float64(currentReplicas))), UsageRatio: &usageRatio}
+}
+
+// getTargetValue converts the target quantity, a missing or non positive target can't be used as a divisor
+func getTargetValue(target *resource.Quantity, name string) (float64, error) {
+	if target == nil {
+		return 0, fmt.Errorf("%s is not defined in metric target", name)
+	}
+
+	value := metrics.QuantityValue(target)
+
+	if value <= 0 {
+		return 0, fmt.Errorf("%s must be greater than zero in metric target, got: %s", name, target.String())
+	}
+
+	return value, nil
+}
+
+func sumMetricValues(metricValues []metrics.MetricValue) float64 {
+	sum := float64(0)
+	for _, metricValue := range metricValues {
+		sum = sum + metricValue.Value
+	}
+	return sum
 }
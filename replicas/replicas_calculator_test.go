@@ -140,4 +140,219 @@ var _ = Describe("ReplicaCalculator", func() {
 		Expect(err).To(BeNil(), "no errors expected for valid arguments")
 		Expect(replicas).To(Equal(int32(2)), "wrong number of replicas")
 	})
+	It("Pods target average value calculator", func() {
+		replicaCalculator := NewReplicaCalculator(0.1)
+
+		scaleMetric := v1alpha1.ScaleMetric{
+			Type: v1alpha1.PodScaleMetricType,
+			Pods: &v1alpha1.PodsMetricSource{
+				Metric: v1alpha1.MetricIdentifier{
+					Name: "http_requests_per_second",
+				},
+				Target: v1alpha1.MetricTarget{
+					Type:         v1alpha1.AverageValueMetricType,
+					AverageValue: resource.NewQuantity(10, resource.DecimalSI),
+				},
+			},
+		}
+
+		metrics := []metrics.MetricValue{
+			{
+				Value: 20,
+			},
+			{
+				Value: 25,
+			},
+		}
+		replicas, err := replicaCalculator.CalculateReplicas(2, noRequestedResources, scaleMetric, metrics)
+
+		Expect(err).To(BeNil(), "no errors expected for valid arguments")
+		Expect(replicas).To(Equal(int32(5)), "wrong number of replicas")
+	})
+
+	It("Pods target average over reporting pods", func() {
+		replicaCalculator := NewReplicaCalculator(0.1)
+
+		scaleMetric := v1alpha1.ScaleMetric{
+			Type: v1alpha1.PodScaleMetricType,
+			Pods: &v1alpha1.PodsMetricSource{
+				Metric: v1alpha1.MetricIdentifier{
+					Name: "http_requests_per_second",
+				},
+				Target: v1alpha1.MetricTarget{
+					Type:         v1alpha1.AverageValueMetricType,
+					AverageValue: resource.NewQuantity(10, resource.DecimalSI),
+				},
+			},
+		}
+
+		// 2 of 4 pods report the metric, e.g. new pods not scraped yet
+		calculation, err := replicaCalculator.ExplainReplicas(4, noRequestedResources, scaleMetric, []metrics.MetricValue{{Value: 10}, {Value: 10}})
+
+		Expect(err).To(BeNil())
+		Expect(calculation.Replicas).To(Equal(int32(4)), "pods without a value should not be counted as idle")
+		Expect(*calculation.UsageRatio).To(Equal(1.0))
+		Expect(calculation.WithinTolerance).To(BeTrue())
+
+		_, err = replicaCalculator.ExplainReplicas(4, noRequestedResources, scaleMetric, nil)
+		Expect(err).NotTo(BeNil(), "no reported value should result in error")
+	})
+
+	It("Object target value calculator", func() {
+		replicaCalculator := NewReplicaCalculator(0.1)
+
+		scaleMetric := v1alpha1.ScaleMetric{
+			Type: v1alpha1.ObjectScaleMetricType,
+			Object: &v1alpha1.ObjectMetricSource{
+				Metric: v1alpha1.MetricIdentifier{
+					Name: "requests_per_second",
+				},
+				Target: v1alpha1.MetricTarget{
+					Type:  v1alpha1.ValueMetricType,
+					Value: resource.NewQuantity(100, resource.DecimalSI),
+				},
+			},
+		}
+
+		metrics := []metrics.MetricValue{
+			{
+				Value: 300,
+			},
+		}
+		replicas, err := replicaCalculator.CalculateReplicas(2, noRequestedResources, scaleMetric, metrics)
+
+		Expect(err).To(BeNil(), "no errors expected for valid arguments")
+		Expect(replicas).To(Equal(int32(6)), "wrong number of replicas")
+	})
+
+	It("Object target average value calculator", func() {
+		replicaCalculator := NewReplicaCalculator(0.1)
+
+		scaleMetric := v1alpha1.ScaleMetric{
+			Type: v1alpha1.ObjectScaleMetricType,
+			Object: &v1alpha1.ObjectMetricSource{
+				Metric: v1alpha1.MetricIdentifier{
+					Name: "requests_per_second",
+				},
+				Target: v1alpha1.MetricTarget{
+					Type:         v1alpha1.AverageValueMetricType,
+					AverageValue: resource.NewQuantity(100, resource.DecimalSI),
+				},
+			},
+		}
+
+		metrics := []metrics.MetricValue{
+			{
+				Value: 300,
+			},
+		}
+		replicas, err := replicaCalculator.CalculateReplicas(2, noRequestedResources, scaleMetric, metrics)
+
+		Expect(err).To(BeNil(), "no errors expected for valid arguments")
+		Expect(replicas).To(Equal(int32(3)), "wrong number of replicas")
+	})
+	It("External target average value calculator", func() {
+		replicaCalculator := NewReplicaCalculator(0.1)
+
+		scaleMetric := v1alpha1.ScaleMetric{
+			Type: v1alpha1.ExternalScaleMetricType,
+			External: &v1alpha1.ExternalMetricSource{
+				Metric: v1alpha1.MetricIdentifier{
+					Name: "queue_messages_ready",
+				},
+				Target: v1alpha1.MetricTarget{
+					Type:         v1alpha1.AverageValueMetricType,
+					AverageValue: resource.NewQuantity(20, resource.DecimalSI),
+				},
+			},
+		}
+
+		metrics := []metrics.MetricValue{
+			{
+				Value: 30,
+			},
+			{
+				Value: 50,
+			},
+		}
+		replicas, err := replicaCalculator.CalculateReplicas(2, noRequestedResources, scaleMetric, metrics)
+
+		Expect(err).To(BeNil(), "no errors expected for valid arguments")
+		Expect(replicas).To(Equal(int32(4)), "total of all series should be divided by the average value target")
+	})
+	It("Resource target - sub-core requests", func() {
+		replicaCalculator := NewReplicaCalculator(0.1)
+
+		averageUtilization := int32(50)
+		scaleMetric := v1alpha1.ScaleMetric{
+			Type: v1alpha1.ResourceScaleMetricType,
+			Resource: &v1alpha1.ResourceMetricSource{
+				Name: corev1.ResourceCPU,
+				Target: v1alpha1.MetricTarget{
+					Type:               v1alpha1.UtilizationMetricType,
+					AverageUtilization: &averageUtilization,
+				},
+			},
+		}
+
+		subCoreRequestedResources := map[string]*corev1.ResourceList{
+			"": {
+				corev1.ResourceCPU: *resource.NewMilliQuantity(200, resource.DecimalSI),
+			},
+		}
+
+		metrics := []metrics.MetricValue{
+			{
+				Value: 0.3,
+			},
+		}
+		replicas, err := replicaCalculator.CalculateReplicas(2, subCoreRequestedResources, scaleMetric, metrics)
+
+		Expect(err).To(BeNil(), "no errors expected for valid arguments")
+		Expect(replicas).To(Equal(int32(6)), "wrong number of replicas")
+	})
+
+	It("Fractional metric values and target", func() {
+		replicaCalculator := NewReplicaCalculator(0.1)
+
+		scaleMetric := v1alpha1.ScaleMetric{
+			Type: v1alpha1.PrometheusScaleMetricType,
+			Prometheus: &v1alpha1.PrometheusMetricSource{
+				Target: v1alpha1.MetricTarget{
+					Type:         v1alpha1.AverageValueMetricType,
+					AverageValue: resource.NewMilliQuantity(250, resource.DecimalSI),
+				},
+			},
+		}
+
+		metrics := []metrics.MetricValue{
+			{
+				Value: 0.75,
+			},
+		}
+		replicas, err := replicaCalculator.CalculateReplicas(1, noRequestedResources, scaleMetric, metrics)
+
+		Expect(err).To(BeNil(), "no errors expected for valid arguments")
+		Expect(replicas).To(Equal(int32(3)), "wrong number of replicas")
+	})
+
+	It("Missing or zero target value", func() {
+		replicaCalculator := NewReplicaCalculator(0.1)
+
+		scaleMetric := v1alpha1.ScaleMetric{
+			Type: v1alpha1.PrometheusScaleMetricType,
+			Prometheus: &v1alpha1.PrometheusMetricSource{
+				Target: v1alpha1.MetricTarget{
+					Type: v1alpha1.ValueMetricType,
+				},
+			},
+		}
+
+		_, err := replicaCalculator.CalculateReplicas(1, noRequestedResources, scaleMetric, []metrics.MetricValue{{Value: 1}})
+		Expect(err).NotTo(BeNil(), "missing target value should result in error")
+
+		scaleMetric.Prometheus.Target.Value = resource.NewQuantity(0, resource.DecimalSI)
+		_, err = replicaCalculator.CalculateReplicas(1, noRequestedResources, scaleMetric, []metrics.MetricValue{{Value: 1}})
+		Expect(err).NotTo(BeNil(), "zero target value should result in error")
+	})
 })
@@ -0,0 +1,107 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package scale
+
+import (
+	"time"
+
+	"github.com/adobe/kratos/api/common"
+	"github.com/adobe/kratos/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// requiresPods returns true for metrics measured on the pods of the target, they have no value while it's scaled to zero
+func requiresPods(metric *v1alpha1.ScaleMetric) bool {
+	return metric.Type == v1alpha1.ResourceScaleMetricType || metric.Type == v1alpha1.PodScaleMetricType
+}
+
+// applyActivation decides when a target with min replicas 0 is scaled to zero and when it's activated again.
+// A parked target stays at zero until a metric is above its activation threshold, it's then started with at least one replica.
+// A running target is scaled to zero once all metrics were at or below their thresholds for the idle cooldown,
+// before that it keeps at least one replica. Metrics which couldn't be evaluated keep the target in its current state.
+func (f *ScaleFacade) applyActivation(item client.Object, spec *v1alpha1.KratosSpec, status *v1alpha1.KratosStatus, desiredReplicas int32, now time.Time) int32 {
+	if spec.GetMinReplicas() > 0 {
+		status.IdleSince = nil
+		return desiredReplicas
+	}
+
+	active, unknown := metricsActivity(spec, status)
+	parked := status.CurrentReplicas == 0
+
+	switch {
+	case active:
+		status.IdleSince = nil
+		if parked {
+			f.eventRecorder.Eventf(item, corev1.EventTypeNormal, "Activated", "metrics are above their activation threshold, starting target from zero replicas")
+		}
+		return common.Max(desiredReplicas, 1)
+	case parked:
+		return 0
+	case unknown:
+		status.IdleSince = nil
+		return common.Max(desiredReplicas, 1)
+	}
+
+	if status.IdleSince == nil {
+		status.IdleSince = &metav1.Time{Time: now}
+	}
+
+	cooldown := time.Duration(spec.IdleCooldownSeconds) * time.Second
+	if now.Sub(status.IdleSince.Time) < cooldown {
+		return common.Max(desiredReplicas, 1)
+	}
+
+	f.eventRecorder.Eventf(item, corev1.EventTypeNormal, "ScaleToZero", "metrics are at or below their activation threshold since %s, scaling target to zero replicas",
+		status.IdleSince.Format(time.RFC3339))
+
+	return 0
+}
+
+// metricsActivity returns whether any metric is above its activation threshold and whether any metric couldn't be evaluated.
+// Metrics are matched with their status by position, metrics requiring pods are skipped while the target has no replicas.
+func metricsActivity(spec *v1alpha1.KratosSpec, status *v1alpha1.KratosStatus) (bool, bool) {
+	active := false
+	unknown := false
+
+	for i := range spec.Metrics {
+		metric := &spec.Metrics[i]
+
+		if status.CurrentReplicas == 0 && requiresPods(metric) {
+			continue
+		}
+
+		if i >= len(status.CurrentMetrics) || status.CurrentMetrics[i].Value == nil {
+			unknown = true
+			continue
+		}
+
+		if status.CurrentMetrics[i].Value.Cmp(activationThreshold(metric)) > 0 {
+			active = true
+		}
+	}
+
+	return active, unknown
+}
+
+func activationThreshold(metric *v1alpha1.ScaleMetric) resource.Quantity {
+	target, err := metric.GetMetricTarget()
+	if err != nil || target.ActivationThreshold == nil {
+		return resource.Quantity{}
+	}
+
+	return *target.ActivationThreshold
+}
@@ -0,0 +1,149 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package scale
+
+import (
+	"time"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+)
+
+var _ = Describe("Activation", func() {
+	var facade *ScaleFacade
+	var recorder *record.FakeRecorder
+	var spec *v1alpha1.KratosSpec
+	var status *v1alpha1.KratosStatus
+	item := &v1alpha1.Kratos{}
+	now := time.Date(2021, 7, 5, 12, 0, 0, 0, time.UTC)
+
+	metricValue := func(value string) v1alpha1.MetricStatus {
+		quantity := resource.MustParse(value)
+		return v1alpha1.MetricStatus{Type: v1alpha1.PrometheusScaleMetricType, Value: &quantity}
+	}
+
+	BeforeEach(func() {
+		recorder = record.NewFakeRecorder(10)
+		facade = &ScaleFacade{eventRecorder: recorder}
+		averageValue := resource.MustParse("100")
+		threshold := resource.MustParse("5")
+		spec = &v1alpha1.KratosSpec{
+			MinReplicas:         pointer.Int32Ptr(0),
+			MaxReplicas:         10,
+			IdleCooldownSeconds: 300,
+			Metrics: []v1alpha1.ScaleMetric{
+				{
+					Type: v1alpha1.PrometheusScaleMetricType,
+					Prometheus: &v1alpha1.PrometheusMetricSource{
+						Target: v1alpha1.MetricTarget{Type: v1alpha1.AverageValueMetricType, AverageValue: &averageValue, ActivationThreshold: &threshold},
+					},
+				},
+				{
+					Type:     v1alpha1.ResourceScaleMetricType,
+					Resource: &v1alpha1.ResourceMetricSource{Name: "cpu", Target: v1alpha1.MetricTarget{Type: v1alpha1.UtilizationMetricType}},
+				},
+			},
+		}
+		status = &v1alpha1.KratosStatus{CurrentReplicas: 0}
+	})
+
+	It("Stay parked below activation threshold", func() {
+		status.CurrentMetrics = []v1alpha1.MetricStatus{metricValue("5"), {Type: v1alpha1.ResourceScaleMetricType}}
+
+		replicas := facade.applyActivation(item, spec, status, 1, now)
+
+		Expect(replicas).To(Equal(int32(0)), "a value equal to the threshold should not activate the target")
+		Expect(recorder.Events).NotTo(Receive())
+	})
+
+	It("Activate above threshold", func() {
+		status.CurrentMetrics = []v1alpha1.MetricStatus{metricValue("6"), {Type: v1alpha1.ResourceScaleMetricType}}
+
+		replicas := facade.applyActivation(item, spec, status, 0, now)
+
+		Expect(replicas).To(Equal(int32(1)), "an activated target should start with at least one replica")
+		Expect(recorder.Events).To(Receive(ContainSubstring("Activated")))
+	})
+
+	It("Keep replicas during idle cooldown", func() {
+		status.CurrentReplicas = 2
+		status.CurrentMetrics = []v1alpha1.MetricStatus{metricValue("1"), metricValue("0")}
+
+		replicas := facade.applyActivation(item, spec, status, 0, now)
+
+		Expect(replicas).To(Equal(int32(1)), "the target should keep one replica until the cooldown elapsed")
+		Expect(status.IdleSince.Time).To(Equal(now))
+	})
+
+	It("Scale to zero after idle cooldown", func() {
+		status.CurrentReplicas = 2
+		status.CurrentMetrics = []v1alpha1.MetricStatus{metricValue("1"), metricValue("0")}
+		status.IdleSince = &metav1.Time{Time: now.Add(-5 * time.Minute)}
+
+		replicas := facade.applyActivation(item, spec, status, 1, now)
+
+		Expect(replicas).To(Equal(int32(0)))
+		Expect(recorder.Events).To(Receive(ContainSubstring("ScaleToZero")))
+	})
+
+	It("Reset idle time when a metric is active", func() {
+		status.CurrentReplicas = 2
+		status.CurrentMetrics = []v1alpha1.MetricStatus{metricValue("1"), metricValue("300m")}
+		status.IdleSince = &metav1.Time{Time: now.Add(-10 * time.Minute)}
+
+		replicas := facade.applyActivation(item, spec, status, 0, now)
+
+		Expect(replicas).To(Equal(int32(1)), "resource usage above the default threshold of 0 should keep the target running")
+		Expect(status.IdleSince).To(BeNil())
+	})
+
+	It("Don't scale to zero on unavailable metrics", func() {
+		status.CurrentReplicas = 2
+		status.CurrentMetrics = []v1alpha1.MetricStatus{{Type: v1alpha1.PrometheusScaleMetricType, Error: "timeout"}, metricValue("0")}
+		status.IdleSince = &metav1.Time{Time: now.Add(-10 * time.Minute)}
+
+		replicas := facade.applyActivation(item, spec, status, 0, now)
+
+		Expect(replicas).To(Equal(int32(1)))
+		Expect(status.IdleSince).To(BeNil())
+	})
+
+	It("Ignore activation with min replicas", func() {
+		spec.MinReplicas = pointer.Int32Ptr(2)
+		status.CurrentReplicas = 3
+		status.CurrentMetrics = []v1alpha1.MetricStatus{metricValue("0"), metricValue("0")}
+
+		replicas := facade.applyActivation(item, spec, status, 2, now)
+
+		Expect(replicas).To(Equal(int32(2)))
+		Expect(status.IdleSince).To(BeNil())
+	})
+
+	It("Ignore activation without min replicas", func() {
+		spec.MinReplicas = nil
+		status.CurrentReplicas = 1
+		status.CurrentMetrics = []v1alpha1.MetricStatus{metricValue("0"), metricValue("0")}
+		status.IdleSince = &metav1.Time{Time: now.Add(-10 * time.Minute)}
+
+		replicas := facade.applyActivation(item, spec, status, 1, now)
+
+		Expect(replicas).To(Equal(int32(1)), "scaling to zero should require min replicas 0")
+		Expect(status.IdleSince).To(BeNil())
+	})
+})
@@ -0,0 +1,97 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package scale
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/adobe/kratos/metrics"
+	"github.com/adobe/kratos/monitoring"
+	"github.com/adobe/kratos/normalizer"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func setCondition(item client.Object, status *v1alpha1.KratosStatus, conditionType string, conditionStatus metav1.ConditionStatus, reason string, message string) {
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             conditionStatus,
+		ObservedGeneration: item.GetGeneration(),
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// setScalingLimitedCondition explains why the normalized replicas differ from the metrics proposal
+func setScalingLimitedCondition(item client.Object, spec *v1alpha1.KratosSpec, status *v1alpha1.KratosStatus, desiredReplicas int32, normalizedReplicas int32, limitReason normalizer.LimitReason) {
+	switch ScalingLimitReason(spec, desiredReplicas, limitReason) {
+	case normalizer.MinReplicasLimit:
+		setCondition(item, status, v1alpha1.ScalingLimitedCondition, metav1.ConditionTrue, "TooFewReplicas",
+			fmt.Sprintf("the desired replica count %d is less than the minimum replica count %d", desiredReplicas, spec.GetMinReplicas()))
+	case normalizer.MaxReplicasLimit:
+		setCondition(item, status, v1alpha1.ScalingLimitedCondition, metav1.ConditionTrue, "TooManyReplicas",
+			fmt.Sprintf("the desired replica count %d is more than the maximum replica count %d", desiredReplicas, spec.MaxReplicas))
+	case normalizer.NotLimited:
+		setCondition(item, status, v1alpha1.ScalingLimitedCondition, metav1.ConditionFalse, "DesiredWithinRange", "the desired replica count is within the acceptable range")
+	default:
+		setCondition(item, status, v1alpha1.ScalingLimitedCondition, metav1.ConditionTrue, string(limitReason),
+			fmt.Sprintf("the desired replica count %d is limited to %d by %s", desiredReplicas, normalizedReplicas, limitReason))
+	}
+}
+
+// ScalingLimitReason returns the min or max replicas limit when the metrics proposal is out of bounds, otherwise the normalizer reason
+func ScalingLimitReason(spec *v1alpha1.KratosSpec, desiredReplicas int32, limitReason normalizer.LimitReason) normalizer.LimitReason {
+	switch {
+	case desiredReplicas < spec.GetMinReplicas():
+		return normalizer.MinReplicasLimit
+	case desiredReplicas > spec.MaxReplicas:
+		return normalizer.MaxReplicasLimit
+	default:
+		return limitReason
+	}
+}
+
+// recordMetricStatuses exports the fetched values and replica proposals of the evaluated metrics,
+// series of metrics removed from the spec are deleted
+func recordMetricStatuses(recorder *monitoring.Recorder, spec *v1alpha1.KratosSpec, metricStatuses []v1alpha1.MetricStatus) {
+	current := make([]monitoring.MetricKey, 0, len(spec.Metrics))
+	for _, metric := range spec.Metrics {
+		current = append(current, monitoring.MetricKey{Type: string(metric.Type), Name: metric.GetMetricName()})
+	}
+	recorder.RetainMetrics(current)
+
+	for _, metricStatus := range metricStatuses {
+		if metricStatus.Value != nil {
+			recorder.RecordMetricValue(string(metricStatus.Type), metricStatus.Name, metrics.QuantityValue(metricStatus.Value))
+		}
+
+		if metricStatus.ProposedReplicas != nil {
+			recorder.RecordMetricProposal(string(metricStatus.Type), metricStatus.Name, *metricStatus.ProposedReplicas)
+		}
+	}
+}
+
+// sumMetricValues converts the total of fetched values to a quantity for the metric status
+func sumMetricValues(metricValues []metrics.MetricValue) *resource.Quantity {
+	sum := float64(0)
+	for _, metricValue := range metricValues {
+		sum += metricValue.Value
+	}
+
+	return resource.NewMilliQuantity(int64(math.Round(sum*1000)), resource.DecimalSI)
+}
@@ -0,0 +1,61 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package scale
+
+import (
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/adobe/kratos/metrics"
+	"github.com/adobe/kratos/normalizer"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+var _ = Describe("Conditions", func() {
+	item := &v1alpha1.Kratos{ObjectMeta: metav1.ObjectMeta{Generation: 3}}
+	spec := &v1alpha1.KratosSpec{MinReplicas: pointer.Int32Ptr(2), MaxReplicas: 10}
+
+	It("Condition observed generation", func() {
+		status := &v1alpha1.KratosStatus{}
+		setCondition(item, status, v1alpha1.AbleToScaleCondition, metav1.ConditionTrue, "ReadyForNewScale", "")
+
+		condition := meta.FindStatusCondition(status.Conditions, v1alpha1.AbleToScaleCondition)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.ObservedGeneration).To(Equal(int64(3)))
+	})
+
+	It("Scaling limited reasons", func() {
+		status := &v1alpha1.KratosStatus{}
+
+		setScalingLimitedCondition(item, spec, status, 1, 2, normalizer.MinReplicasLimit)
+		Expect(meta.FindStatusCondition(status.Conditions, v1alpha1.ScalingLimitedCondition).Reason).To(Equal("TooFewReplicas"))
+
+		setScalingLimitedCondition(item, spec, status, 12, 10, normalizer.MaxReplicasLimit)
+		Expect(meta.FindStatusCondition(status.Conditions, v1alpha1.ScalingLimitedCondition).Reason).To(Equal("TooManyReplicas"))
+
+		setScalingLimitedCondition(item, spec, status, 8, 5, normalizer.ScaleUpLimit)
+		Expect(meta.FindStatusCondition(status.Conditions, v1alpha1.ScalingLimitedCondition).Reason).To(Equal("ScaleUpLimit"))
+
+		setScalingLimitedCondition(item, spec, status, 5, 5, normalizer.NotLimited)
+		Expect(meta.IsStatusConditionFalse(status.Conditions, v1alpha1.ScalingLimitedCondition)).To(BeTrue(), "desired replicas within range should not be limited")
+	})
+
+	It("Metric status value", func() {
+		value := sumMetricValues([]metrics.MetricValue{{Value: 0.25}, {Value: 1.5}})
+
+		Expect(value.String()).To(Equal("1750m"), "metric values should be summed with milli precision")
+	})
+})
@@ -0,0 +1,90 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package scale
+
+import (
+	"fmt"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	kratosOwnerKind    = "Kratos"
+	configMapOwnerKind = "ConfigMap"
+)
+
+// claimTarget refuses to scale a target controlled by a HorizontalPodAutoscaler or by an older autoscaler, unless the
+// item allows a takeover with its annotation. Autoscalers in dry run don't scale their target, they neither own it nor conflict.
+// Returns false when the target must not be scaled, the conflict is reported with the Conflict condition and an event.
+func (f *ScaleFacade) claimTarget(item client.Object, spec *v1alpha1.KratosSpec, status *v1alpha1.KratosStatus, dryRun bool) bool {
+	autoscaler := autoscalerOwner(item)
+
+	if dryRun {
+		f.scaleTarget.ReleaseTarget(autoscaler)
+		meta.RemoveStatusCondition(&status.Conditions, v1alpha1.ConflictCondition)
+		return true
+	}
+
+	allowTakeover := item.GetAnnotations()[v1alpha1.AllowTakeoverAnnotation] == "true"
+	other, claimed := f.scaleTarget.ClaimTarget(autoscaler, allowTakeover, item.GetCreationTimestamp().Time, item.GetNamespace(), &spec.Target)
+	target := fmt.Sprintf("%s/%s", spec.Target.Kind, spec.Target.Name)
+	previous := meta.FindStatusCondition(status.Conditions, v1alpha1.ConflictCondition)
+
+	switch {
+	case !claimed:
+		reason := "ConflictingAutoscaler"
+		if other.Kind == hpaKind {
+			reason = "ConflictingHorizontalPodAutoscaler"
+		}
+		message := fmt.Sprintf("target %s is controlled by %s, set the annotation %s=true to take it over", target, other, v1alpha1.AllowTakeoverAnnotation)
+
+		if previous == nil || previous.Status != metav1.ConditionTrue || previous.Message != message {
+			f.eventRecorder.Event(item, corev1.EventTypeWarning, "Conflict", message)
+		}
+		setCondition(item, status, v1alpha1.ConflictCondition, metav1.ConditionTrue, reason, message)
+		setCondition(item, status, v1alpha1.AbleToScaleCondition, metav1.ConditionFalse, "Conflict", message)
+
+		return false
+	case other != nil:
+		message := fmt.Sprintf("target %s is taken over from %s", target, other)
+
+		if previous == nil || previous.Message != message {
+			f.eventRecorder.Event(item, corev1.EventTypeWarning, "TakeOver", message)
+		}
+		setCondition(item, status, v1alpha1.ConflictCondition, metav1.ConditionFalse, "TakeoverAllowed", message)
+	default:
+		setCondition(item, status, v1alpha1.ConflictCondition, metav1.ConditionFalse, "NoConflict", fmt.Sprintf("target %s is only scaled by this autoscaler", target))
+	}
+
+	return true
+}
+
+// ReleaseTarget forgets the scale target owned by a deleted Kratos resource or ConfigMap so that other autoscalers may scale it
+func (f *ScaleFacade) ReleaseTarget(kind string, name types.NamespacedName) {
+	f.scaleTarget.ReleaseTarget(TargetOwner{Kind: kind, Namespace: name.Namespace, Name: name.Name})
+}
+
+func autoscalerOwner(item client.Object) TargetOwner {
+	kind := kratosOwnerKind
+	if _, ok := item.(*corev1.ConfigMap); ok {
+		kind = configMapOwnerKind
+	}
+
+	return TargetOwner{Kind: kind, Namespace: item.GetNamespace(), Name: item.GetName()}
+}
@@ -0,0 +1,217 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package scale
+
+import (
+	"time"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Conflicts", func() {
+	var facade *ScaleFacade
+	var hpaIndexer cache.Indexer
+	var recorder *record.FakeRecorder
+	var spec *v1alpha1.KratosSpec
+	var created time.Time
+
+	// every autoscaler is created a minute after the previous one
+	newObjectMeta := func(name string, annotations map[string]string) metav1.ObjectMeta {
+		created = created.Add(time.Minute)
+		return metav1.ObjectMeta{Namespace: "default", Name: name, Annotations: annotations, CreationTimestamp: metav1.NewTime(created)}
+	}
+
+	newKratos := func(name string, annotations map[string]string) *v1alpha1.Kratos {
+		return &v1alpha1.Kratos{ObjectMeta: newObjectMeta(name, annotations)}
+	}
+
+	allowTakeover := map[string]string{v1alpha1.AllowTakeoverAnnotation: "true"}
+
+	BeforeEach(func() {
+		hpaIndexer = cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{hpaTargetIndex: indexHPAByTarget})
+		recorder = record.NewFakeRecorder(10)
+		facade = &ScaleFacade{
+			eventRecorder: recorder,
+			scaleTarget:   &ScaleTarget{log: ctrl.Log.WithName("test"), hpaIndexer: hpaIndexer, owners: newTargetOwners()},
+		}
+		created = time.Date(2021, 7, 5, 10, 0, 0, 0, time.UTC)
+		spec = &v1alpha1.KratosSpec{Target: v1alpha1.ScaleTargetReference{Kind: "Deployment", Name: "nginx", APIVersion: "apps/v1"}}
+	})
+
+	addHPA := func(namespace string, name string, target string) {
+		Expect(hpaIndexer.Add(&autoscalingv1.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec: autoscalingv1.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{Kind: "Deployment", Name: target, APIVersion: "apps/v1"},
+			},
+		})).To(Succeed())
+	}
+
+	It("Claims a target without other controllers", func() {
+		addHPA("default", "other", "other")
+		addHPA("other", "nginx", "nginx")
+		status := &v1alpha1.KratosStatus{}
+
+		Expect(facade.claimTarget(newKratos("nginx-scaler", nil), spec, status, false)).To(BeTrue())
+
+		condition := meta.FindStatusCondition(status.Conditions, v1alpha1.ConflictCondition)
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal("NoConflict"))
+		Expect(recorder.Events).To(BeEmpty())
+	})
+
+	It("Refuses a target scaled by a HorizontalPodAutoscaler", func() {
+		addHPA("default", "nginx", "nginx")
+		status := &v1alpha1.KratosStatus{}
+		item := newKratos("nginx-scaler", nil)
+
+		Expect(facade.claimTarget(item, spec, status, false)).To(BeFalse())
+
+		condition := meta.FindStatusCondition(status.Conditions, v1alpha1.ConflictCondition)
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal("ConflictingHorizontalPodAutoscaler"))
+		Expect(condition.Message).To(ContainSubstring("HorizontalPodAutoscaler default/nginx"))
+		Expect(meta.IsStatusConditionFalse(status.Conditions, v1alpha1.AbleToScaleCondition)).To(BeTrue())
+		Expect(recorder.Events).To(Receive(HavePrefix("Warning Conflict target Deployment/nginx is controlled by HorizontalPodAutoscaler default/nginx")))
+
+		Expect(facade.claimTarget(item, spec, status, false)).To(BeFalse())
+		Expect(recorder.Events).To(BeEmpty(), "the same conflict should be reported once")
+	})
+
+	It("Refuses a target owned by another autoscaler until it's released", func() {
+		first := newKratos("first", nil)
+		second := &corev1.ConfigMap{ObjectMeta: newObjectMeta("second", nil)}
+
+		Expect(facade.claimTarget(first, spec, &v1alpha1.KratosStatus{}, false)).To(BeTrue())
+
+		status := &v1alpha1.KratosStatus{}
+		Expect(facade.claimTarget(second, spec, status, false)).To(BeFalse())
+		condition := meta.FindStatusCondition(status.Conditions, v1alpha1.ConflictCondition)
+		Expect(condition.Reason).To(Equal("ConflictingAutoscaler"))
+		Expect(condition.Message).To(ContainSubstring("Kratos default/first"))
+
+		Expect(facade.claimTarget(first, spec, &v1alpha1.KratosStatus{}, false)).To(BeTrue(), "the owner should keep its target")
+
+		facade.ReleaseTarget("Kratos", types.NamespacedName{Namespace: "default", Name: "first"})
+		Expect(facade.claimTarget(second, spec, status, false)).To(BeTrue())
+		Expect(meta.IsStatusConditionFalse(status.Conditions, v1alpha1.ConflictCondition)).To(BeTrue())
+	})
+
+	It("Same owner regardless of the order of the claims", func() {
+		items := []client.Object{
+			newKratos("oldest", nil),
+			&corev1.ConfigMap{ObjectMeta: newObjectMeta("newer", nil)},
+			newKratos("newest", nil),
+		}
+
+		claimAll := func(items []client.Object) []bool {
+			claimed := make([]bool, len(items))
+			for i, item := range items {
+				claimed[i] = facade.claimTarget(item, spec, &v1alpha1.KratosStatus{}, false)
+			}
+			return claimed
+		}
+
+		Expect(claimAll(items)).To(Equal([]bool{true, false, false}))
+
+		// an operator restart rebuilds the index in the order in which autoscalers are reconciled
+		facade.scaleTarget.owners = newTargetOwners()
+		reversed := []client.Object{items[2], items[1], items[0]}
+		claimAll(reversed)
+
+		Expect(claimAll(reversed)).To(Equal([]bool{false, false, true}), "the oldest autoscaler should own the target")
+	})
+
+	It("Owner of autoscalers created at the same time", func() {
+		first := newKratos("nginx-scaler", nil)
+		second := &corev1.ConfigMap{ObjectMeta: *first.ObjectMeta.DeepCopy()}
+
+		Expect(facade.claimTarget(first, spec, &v1alpha1.KratosStatus{}, false)).To(BeTrue())
+		Expect(facade.claimTarget(second, spec, &v1alpha1.KratosStatus{}, false)).To(BeTrue(), "ties should be broken by kind")
+		Expect(facade.claimTarget(first, spec, &v1alpha1.KratosStatus{}, false)).To(BeFalse())
+	})
+
+	It("Releases the previous target when the target changes", func() {
+		first := newKratos("first", nil)
+		Expect(facade.claimTarget(first, spec, &v1alpha1.KratosStatus{}, false)).To(BeTrue())
+
+		otherSpec := spec.DeepCopy()
+		otherSpec.Target.Name = "other"
+		Expect(facade.claimTarget(first, otherSpec, &v1alpha1.KratosStatus{}, false)).To(BeTrue())
+
+		Expect(facade.claimTarget(newKratos("second", nil), spec, &v1alpha1.KratosStatus{}, false)).To(BeTrue())
+	})
+
+	It("Releases the previous target when the new target is scaled by a HorizontalPodAutoscaler", func() {
+		addHPA("default", "other", "other")
+		first := newKratos("first", nil)
+		Expect(facade.claimTarget(first, spec, &v1alpha1.KratosStatus{}, false)).To(BeTrue())
+
+		otherSpec := spec.DeepCopy()
+		otherSpec.Target.Name = "other"
+		Expect(facade.claimTarget(first, otherSpec, &v1alpha1.KratosStatus{}, false)).To(BeFalse())
+
+		Expect(facade.claimTarget(newKratos("second", nil), spec, &v1alpha1.KratosStatus{}, false)).To(BeTrue())
+	})
+
+	It("Allows takeovers with the annotation", func() {
+		addHPA("default", "nginx", "nginx")
+		owner := newKratos("owner", nil)
+		Expect(facade.claimTarget(owner, spec, &v1alpha1.KratosStatus{}, false)).To(BeFalse())
+
+		status := &v1alpha1.KratosStatus{}
+		Expect(facade.claimTarget(newKratos("taker", allowTakeover), spec, status, false)).To(BeTrue())
+		condition := meta.FindStatusCondition(status.Conditions, v1alpha1.ConflictCondition)
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal("TakeoverAllowed"))
+		Expect(condition.Message).To(ContainSubstring("HorizontalPodAutoscaler default/nginx"))
+
+		Expect(hpaIndexer.Delete(&autoscalingv1.HorizontalPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nginx"}})).To(Succeed())
+		Expect(facade.claimTarget(owner, spec, &v1alpha1.KratosStatus{}, false)).To(BeFalse(), "the taker should keep the target")
+		Expect(facade.claimTarget(newKratos("another-taker", allowTakeover), spec, &v1alpha1.KratosStatus{}, false)).To(BeFalse(),
+			"a takeover from an autoscaler which allows takeovers should be refused")
+	})
+
+	It("Takes over from autoscalers without the annotation", func() {
+		owner := newKratos("owner", nil)
+		Expect(facade.claimTarget(owner, spec, &v1alpha1.KratosStatus{}, false)).To(BeTrue())
+
+		status := &v1alpha1.KratosStatus{}
+		Expect(facade.claimTarget(newKratos("taker", allowTakeover), spec, status, false)).To(BeTrue())
+		Expect(meta.FindStatusCondition(status.Conditions, v1alpha1.ConflictCondition).Message).To(ContainSubstring("taken over from Kratos default/owner"))
+
+		Expect(facade.claimTarget(owner, spec, &v1alpha1.KratosStatus{}, false)).To(BeFalse(), "the previous owner should be refused")
+	})
+
+	It("Ignores dry run autoscalers", func() {
+		addHPA("default", "nginx", "nginx")
+		status := &v1alpha1.KratosStatus{}
+		item := newKratos("nginx-scaler", nil)
+
+		Expect(facade.claimTarget(item, spec, status, false)).To(BeFalse())
+		Expect(facade.claimTarget(item, spec, status, true)).To(BeTrue())
+		Expect(meta.FindStatusCondition(status.Conditions, v1alpha1.ConflictCondition)).To(BeNil())
+	})
+})
@@ -0,0 +1,86 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package scale
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/adobe/kratos/metrics"
+	"github.com/adobe/kratos/replicas"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// recordDecision logs the decision and keeps it in status along with the previous ones, up to the decision history of the operator
+func (f *ScaleFacade) recordDecision(item client.Object, status *v1alpha1.KratosStatus, decision *v1alpha1.ScalingDecision) {
+	f.log.WithName("decision").Info("scaling decision", "namespace", item.GetNamespace(), "name", item.GetName(), "decision", decision)
+
+	if f.decisionHistory <= 0 {
+		status.Decisions = nil
+		return
+	}
+
+	status.Decisions = append(status.Decisions, *decision)
+	if overflow := len(status.Decisions) - int(f.decisionHistory); overflow > 0 {
+		status.Decisions = append(status.Decisions[:0], status.Decisions[overflow:]...)
+	}
+}
+
+// maxDecisionValues bounds the values kept in a metric decision, Resource and Pod metrics have one value per pod
+const maxDecisionValues = 10
+
+// explainValues records the number of fetched values and the first maxDecisionValues of them in the metric decision
+func explainValues(metricValues []metrics.MetricValue, metricDecision *v1alpha1.MetricDecision) {
+	metricDecision.ValueCount = int32(len(metricValues))
+
+	if len(metricValues) > maxDecisionValues {
+		metricValues = metricValues[:maxDecisionValues]
+	}
+
+	metricDecision.Values = make([]resource.Quantity, 0, len(metricValues))
+	for _, metricValue := range metricValues {
+		metricDecision.Values = append(metricDecision.Values, *resource.NewMilliQuantity(int64(math.Round(metricValue.Value*1000)), resource.DecimalSI))
+	}
+}
+
+// explainCalculation records the target, usage ratio and tolerance verdict of a calculation in the metric decision
+func explainCalculation(metric *v1alpha1.ScaleMetric, calculation replicas.Calculation, metricDecision *v1alpha1.MetricDecision) {
+	metricDecision.Target = describeTarget(metric)
+	metricDecision.WithinTolerance = calculation.WithinTolerance
+
+	if calculation.UsageRatio != nil {
+		metricDecision.UsageRatio = fmt.Sprintf("%.3f", *calculation.UsageRatio)
+	}
+}
+
+// describeTarget formats the target of a metric, e.g. 'averageValue 100'
+func describeTarget(metric *v1alpha1.ScaleMetric) string {
+	target, err := metric.GetMetricTarget()
+	if err != nil {
+		return ""
+	}
+
+	switch {
+	case target.Type == v1alpha1.ValueMetricType && target.Value != nil:
+		return fmt.Sprintf("value %s", target.Value.String())
+	case target.Type == v1alpha1.AverageValueMetricType && target.AverageValue != nil:
+		return fmt.Sprintf("averageValue %s", target.AverageValue.String())
+	case target.Type == v1alpha1.UtilizationMetricType && target.AverageUtilization != nil:
+		return fmt.Sprintf("averageUtilization %d%%", *target.AverageUtilization)
+	}
+
+	return string(target.Type)
+}
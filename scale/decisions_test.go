@@ -0,0 +1,99 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package scale
+
+import (
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/adobe/kratos/metrics"
+	"github.com/adobe/kratos/replicas"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("Decisions", func() {
+	item := &v1alpha1.Kratos{}
+	utilization := int32(70)
+
+	It("Keep the last decisions", func() {
+		facade := &ScaleFacade{log: ctrl.Log.WithName("test"), decisionHistory: 3}
+		status := &v1alpha1.KratosStatus{}
+
+		for i := int32(1); i <= 5; i++ {
+			facade.recordDecision(item, status, &v1alpha1.ScalingDecision{NormalizedReplicas: i})
+		}
+
+		Expect(status.Decisions).To(HaveLen(3))
+		Expect(status.Decisions[0].NormalizedReplicas).To(Equal(int32(3)))
+		Expect(status.Decisions[2].NormalizedReplicas).To(Equal(int32(5)), "newest decision should be last")
+	})
+
+	It("Keep no decisions", func() {
+		facade := &ScaleFacade{log: ctrl.Log.WithName("test")}
+		status := &v1alpha1.KratosStatus{Decisions: []v1alpha1.ScalingDecision{{NormalizedReplicas: 1}}}
+
+		facade.recordDecision(item, status, &v1alpha1.ScalingDecision{NormalizedReplicas: 2})
+
+		Expect(status.Decisions).To(BeNil())
+	})
+
+	It("Keep the first values", func() {
+		metricValues := make([]metrics.MetricValue, 0, 12)
+		for i := 1; i <= 12; i++ {
+			metricValues = append(metricValues, metrics.MetricValue{Value: float64(i) / 2})
+		}
+		metricDecision := &v1alpha1.MetricDecision{}
+
+		explainValues(metricValues, metricDecision)
+
+		Expect(metricDecision.ValueCount).To(Equal(int32(12)))
+		Expect(metricDecision.Values).To(HaveLen(maxDecisionValues))
+		Expect(metricDecision.Values[0].String()).To(Equal("500m"))
+		Expect(metricDecision.Values[9].String()).To(Equal("5"))
+	})
+
+	It("Explain calculation", func() {
+		averageValue := resource.MustParse("100")
+		metric := &v1alpha1.ScaleMetric{
+			Type: v1alpha1.PrometheusScaleMetricType,
+			Prometheus: &v1alpha1.PrometheusMetricSource{
+				Target: v1alpha1.MetricTarget{Type: v1alpha1.AverageValueMetricType, AverageValue: &averageValue},
+			},
+		}
+		usageRatio := 1.04
+		metricDecision := &v1alpha1.MetricDecision{}
+
+		explainCalculation(metric, replicas.Calculation{Replicas: 2, UsageRatio: &usageRatio, WithinTolerance: true}, metricDecision)
+
+		Expect(metricDecision.Target).To(Equal("averageValue 100"))
+		Expect(metricDecision.UsageRatio).To(Equal("1.040"))
+		Expect(metricDecision.WithinTolerance).To(BeTrue())
+	})
+
+	DescribeTable("Describe target",
+		func(target v1alpha1.MetricTarget, expected string) {
+			metric := &v1alpha1.ScaleMetric{
+				Type:     v1alpha1.ResourceScaleMetricType,
+				Resource: &v1alpha1.ResourceMetricSource{Name: "cpu", Target: target},
+			}
+			Expect(describeTarget(metric)).To(Equal(expected))
+		},
+
+		Entry("value", v1alpha1.MetricTarget{Type: v1alpha1.ValueMetricType, Value: resource.NewMilliQuantity(500, resource.DecimalSI)}, "value 500m"),
+		Entry("average value", v1alpha1.MetricTarget{Type: v1alpha1.AverageValueMetricType, AverageValue: resource.NewQuantity(10, resource.DecimalSI)}, "averageValue 10"),
+		Entry("utilization", v1alpha1.MetricTarget{Type: v1alpha1.UtilizationMetricType, AverageUtilization: &utilization}, "averageUtilization 70%"),
+	)
+})
@@ -14,38 +14,230 @@ written permission of Adobe.
 package scale
 
 import (
+	"time"
+
 	"github.com/adobe/kratos/api/common"
 	"github.com/adobe/kratos/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	defaultFallbackFailureThreshold = 3
+	defaultIdleCooldownSeconds      = 300
+
+	defaultPredictionSeason         = 24 * time.Hour
+	defaultPredictionHistorySeasons = 2
+	defaultPredictionStep           = 5 * time.Minute
+
+	// the default step and EWMA half-life of Prometheus ranges are fractions of the window
+	defaultRangeStepsPerWindow     = 10
+	defaultRangeHalfLivesPerWindow = 4
 )
 
+// DefaultsUpdater sets the default values of optional spec fields, it's used on admission and before every evaluation
 type DefaultsUpdater struct {
 	stabilizationWindowSeconds int32
 }
 
-func newDefaultsUpdater(params *common.KratosParameters) *DefaultsUpdater {
+func NewDefaultsUpdater(params *common.KratosParameters) *DefaultsUpdater {
 	return &DefaultsUpdater{
 		stabilizationWindowSeconds: params.StabilizationWindowSeconds,
 	}
 }
 
-func (p *DefaultsUpdater) updateSpecWithDefaults(spec *v1alpha1.KratosSpec) {
+// UpdateSpecWithDefaults sets the defaults in place before a spec is evaluated. Negative values are reset first,
+// i.e. negative replicas become 0 and other negative values their default, as ConfigMap specs may not be validated on admission.
+func (p *DefaultsUpdater) UpdateSpecWithDefaults(spec *v1alpha1.KratosSpec) {
+	p.resetNegativeValues(spec)
+	p.SetDefaults(spec)
+}
+
+// SetDefaults sets the defaults of unset fields in place. Invalid values are kept so that they're rejected by validation.
+func (p *DefaultsUpdater) SetDefaults(spec *v1alpha1.KratosSpec) {
+	p.updateMinReplicas(spec)
+	p.updateAlgorithm(spec)
+	p.updateMode(spec)
 	p.updateStabilizationWindow(spec)
-	p.updateReplicas(spec)
+	p.updateIdleCooldown(spec)
 	p.updateScaleRules(spec)
+	p.updateFallback(spec)
+	p.updatePredictions(spec)
+	p.updatePrometheusRanges(spec)
+	p.updateKafkaLag(spec)
+	p.updateRabbitMQQueueMetrics(spec)
+}
+
+func (p *DefaultsUpdater) updateMinReplicas(spec *v1alpha1.KratosSpec) {
+	if spec.MinReplicas == nil {
+		minReplicas := v1alpha1.DefaultMinReplicas
+		spec.MinReplicas = &minReplicas
+	}
+}
+
+func (p *DefaultsUpdater) updateAlgorithm(spec *v1alpha1.KratosSpec) {
+	if spec.Algorithm.Type == "" {
+		spec.Algorithm.Type = v1alpha1.HpaAlgorithmType
+	}
+}
+
+func (p *DefaultsUpdater) updateMode(spec *v1alpha1.KratosSpec) {
+	if spec.Mode == "" {
+		spec.Mode = v1alpha1.ActiveAutoscalerMode
+	}
+}
+
+func (p *DefaultsUpdater) updateFallback(spec *v1alpha1.KratosSpec) {
+	if spec.Fallback == nil {
+		spec.Fallback = &v1alpha1.Fallback{}
+	}
+
+	if spec.Fallback.Policy == "" {
+		spec.Fallback.Policy = v1alpha1.HoldFallbackPolicy
+	}
+
+	if spec.Fallback.FailureThreshold == 0 {
+		spec.Fallback.FailureThreshold = defaultFallbackFailureThreshold
+	}
+}
+
+func (p *DefaultsUpdater) updatePredictions(spec *v1alpha1.KratosSpec) {
+	for _, metric := range spec.Metrics {
+		if metric.Prometheus == nil || metric.Prometheus.Prediction == nil {
+			continue
+		}
+
+		prediction := metric.Prometheus.Prediction
+
+		if prediction.Mode == "" {
+			prediction.Mode = v1alpha1.ObservePredictionMode
+		}
+
+		if prediction.Model == "" {
+			prediction.Model = v1alpha1.SeasonalNaivePredictionModel
+		}
+
+		if prediction.Season.Duration == 0 {
+			prediction.Season.Duration = defaultPredictionSeason
+		}
+
+		if prediction.HistorySeasons == 0 {
+			prediction.HistorySeasons = defaultPredictionHistorySeasons
+		}
+
+		if prediction.Step.Duration == 0 {
+			prediction.Step.Duration = defaultPredictionStep
+		}
+
+		if prediction.LeadTime.Duration == 0 {
+			prediction.LeadTime.Duration = prediction.Step.Duration
+		}
+	}
 }
 
-func (p *DefaultsUpdater) updateReplicas(spec *v1alpha1.KratosSpec) {
-	if spec.MinReplicas < 0 {
-		spec.MinReplicas = 0
+func (p *DefaultsUpdater) updatePrometheusRanges(spec *v1alpha1.KratosSpec) {
+	for _, metric := range spec.Metrics {
+		if metric.Prometheus == nil || metric.Prometheus.Range == nil {
+			continue
+		}
+
+		window := metric.Prometheus.Range
+
+		if window.Aggregation == "" {
+			window.Aggregation = v1alpha1.AvgAggregation
+		}
+
+		if window.Step.Duration == 0 && window.Window.Duration > 0 {
+			window.Step.Duration = window.Window.Duration / defaultRangeStepsPerWindow
+			if window.Step.Duration < time.Second {
+				window.Step.Duration = time.Second
+			}
+		}
+
+		if window.Aggregation == v1alpha1.EWMAAggregation && window.HalfLife.Duration == 0 {
+			window.HalfLife.Duration = window.Window.Duration / defaultRangeHalfLivesPerWindow
+		}
+	}
+}
+
+func (p *DefaultsUpdater) updateKafkaLag(spec *v1alpha1.KratosSpec) {
+	for _, metric := range spec.Metrics {
+		if metric.Kafka != nil && metric.Kafka.Lag == "" {
+			metric.Kafka.Lag = v1alpha1.TotalKafkaLag
+		}
+	}
+}
+
+func (p *DefaultsUpdater) updateRabbitMQQueueMetrics(spec *v1alpha1.KratosSpec) {
+	for _, metric := range spec.Metrics {
+		if metric.RabbitMQ != nil && metric.RabbitMQ.QueueMetric == "" {
+			metric.RabbitMQ.QueueMetric = v1alpha1.MessagesRabbitMQQueueMetric
+		}
+	}
+}
+
+// resetNegativeValues sets negative values to 0, the defaults of unset fields are applied to them afterwards
+func (p *DefaultsUpdater) resetNegativeValues(spec *v1alpha1.KratosSpec) {
+	if spec.MinReplicas != nil && *spec.MinReplicas < 0 {
+		minReplicas := int32(0)
+		spec.MinReplicas = &minReplicas
+	}
+	spec.MaxReplicas = nonNegative(spec.MaxReplicas)
+	spec.StabilizationWindowSeconds = nonNegative(spec.StabilizationWindowSeconds)
+	spec.IdleCooldownSeconds = nonNegative(spec.IdleCooldownSeconds)
+
+	if spec.Behavior != nil {
+		// the stabilization window of disabled scale rules isn't used
+		for _, scaleRules := range []*v1alpha1.ScaleRules{spec.Behavior.ScaleUp, spec.Behavior.ScaleDown} {
+			if scaleRules != nil && scaleRules.SelectPolicy != v1alpha1.DisabledPolicySelect {
+				scaleRules.StabilizationWindowSeconds = nonNegative(scaleRules.StabilizationWindowSeconds)
+			}
+		}
+	}
+
+	if spec.Fallback != nil {
+		spec.Fallback.FailureThreshold = nonNegative(spec.Fallback.FailureThreshold)
+	}
+
+	for _, metric := range spec.Metrics {
+		if metric.Prometheus == nil {
+			continue
+		}
+
+		if prediction := metric.Prometheus.Prediction; prediction != nil {
+			prediction.HistorySeasons = nonNegative(prediction.HistorySeasons)
+			resetNegativeDurations(&prediction.Season, &prediction.Step, &prediction.LeadTime)
+		}
+
+		if window := metric.Prometheus.Range; window != nil {
+			resetNegativeDurations(&window.Step, &window.HalfLife)
+		}
+	}
+}
+
+func nonNegative(value int32) int32 {
+	if value < 0 {
+		return 0
+	}
+
+	return value
+}
+
+func resetNegativeDurations(durations ...*metav1.Duration) {
+	for _, duration := range durations {
+		if duration.Duration < 0 {
+			duration.Duration = 0
+		}
 	}
+}
 
-	if spec.MaxReplicas < 0 {
-		spec.MaxReplicas = 0
+func (p *DefaultsUpdater) updateIdleCooldown(spec *v1alpha1.KratosSpec) {
+	if spec.IdleCooldownSeconds == 0 {
+		spec.IdleCooldownSeconds = defaultIdleCooldownSeconds
 	}
 }
 
 func (p *DefaultsUpdater) updateStabilizationWindow(spec *v1alpha1.KratosSpec) {
-	if spec.StabilizationWindowSeconds <= 0 {
+	if spec.StabilizationWindowSeconds == 0 {
 		spec.StabilizationWindowSeconds = p.stabilizationWindowSeconds
 	}
 }
@@ -61,7 +253,7 @@ func (p *DefaultsUpdater) updateScaleRules(spec *v1alpha1.KratosSpec) {
 			scaleRules.SelectPolicy = v1alpha1.MaxPolicySelect
 		}
 
-		if scaleRules.StabilizationWindowSeconds <= 0 && scaleRules.SelectPolicy != v1alpha1.DisabledPolicySelect {
+		if scaleRules.StabilizationWindowSeconds == 0 && scaleRules.SelectPolicy != v1alpha1.DisabledPolicySelect {
 			scaleRules.StabilizationWindowSeconds = p.stabilizationWindowSeconds
 		}
 	} else {
@@ -76,7 +268,7 @@ func (p *DefaultsUpdater) updateScaleRules(spec *v1alpha1.KratosSpec) {
 			scaleRules.SelectPolicy = v1alpha1.MinPolicySelect
 		}
 
-		if scaleRules.StabilizationWindowSeconds <= 0 && scaleRules.SelectPolicy != v1alpha1.DisabledPolicySelect {
+		if scaleRules.StabilizationWindowSeconds == 0 && scaleRules.SelectPolicy != v1alpha1.DisabledPolicySelect {
 			scaleRules.StabilizationWindowSeconds = p.stabilizationWindowSeconds
 		}
 	} else {
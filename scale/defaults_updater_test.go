@@ -14,10 +14,14 @@ written permission of Adobe.
 package scale
 
 import (
+	"time"
+
 	"github.com/adobe/kratos/api/common"
 	"github.com/adobe/kratos/api/v1alpha1"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 )
 
 var _ = Describe("DefaultsUpdater", func() {
@@ -26,31 +30,165 @@ var _ = Describe("DefaultsUpdater", func() {
 			StabilizationWindowSeconds: 200,
 		}
 
-		updater := newDefaultsUpdater(params)
+		updater := NewDefaultsUpdater(params)
 
 		spec := &v1alpha1.KratosSpec{}
 
 		Expect(spec.StabilizationWindowSeconds).To(Equal(int32(0)))
-		updater.updateSpecWithDefaults(spec)
+		updater.UpdateSpecWithDefaults(spec)
 
 		Expect(spec.StabilizationWindowSeconds).To(Equal(params.StabilizationWindowSeconds), "stabilization should be set to default")
 		Expect(spec.Behavior).To(BeNil())
 	})
 
+	It("Algorithm type", func() {
+		params := &common.KratosParameters{
+			StabilizationWindowSeconds: 200,
+		}
+
+		updater := NewDefaultsUpdater(params)
+
+		spec := &v1alpha1.KratosSpec{}
+		updater.UpdateSpecWithDefaults(spec)
+		Expect(spec.Algorithm.Type).To(Equal(v1alpha1.HpaAlgorithmType), "algorithm type should default to hpa")
+
+		spec = &v1alpha1.KratosSpec{Algorithm: v1alpha1.Algorithm{Type: v1alpha1.StepAlgorithmType}}
+		updater.UpdateSpecWithDefaults(spec)
+		Expect(spec.Algorithm.Type).To(Equal(v1alpha1.StepAlgorithmType), "algorithm type should not be overridden")
+	})
+
+	It("Prediction", func() {
+		params := &common.KratosParameters{
+			StabilizationWindowSeconds: 200,
+		}
+
+		updater := NewDefaultsUpdater(params)
+
+		spec := &v1alpha1.KratosSpec{Metrics: []v1alpha1.ScaleMetric{{
+			Type: v1alpha1.PrometheusScaleMetricType,
+			Prometheus: &v1alpha1.PrometheusMetricSource{
+				Prediction: &v1alpha1.Prediction{Step: metav1.Duration{Duration: time.Minute}},
+			},
+		}}}
+		updater.UpdateSpecWithDefaults(spec)
+
+		prediction := spec.Metrics[0].Prometheus.Prediction
+		Expect(prediction.Mode).To(Equal(v1alpha1.ObservePredictionMode), "prediction should only be observed by default")
+		Expect(prediction.Model).To(Equal(v1alpha1.SeasonalNaivePredictionModel))
+		Expect(prediction.Season.Duration).To(Equal(24 * time.Hour))
+		Expect(prediction.HistorySeasons).To(Equal(int32(2)))
+		Expect(prediction.Step.Duration).To(Equal(time.Minute), "step should not be overridden")
+		Expect(prediction.LeadTime.Duration).To(Equal(time.Minute), "lead time should default to the step")
+	})
+
+	It("Prometheus range", func() {
+		updater := NewDefaultsUpdater(&common.KratosParameters{})
+
+		spec := &v1alpha1.KratosSpec{Metrics: []v1alpha1.ScaleMetric{{
+			Type: v1alpha1.PrometheusScaleMetricType,
+			Prometheus: &v1alpha1.PrometheusMetricSource{
+				Range: &v1alpha1.PrometheusRange{Window: metav1.Duration{Duration: 5 * time.Minute}},
+			},
+		}, {
+			Type: v1alpha1.PrometheusScaleMetricType,
+			Prometheus: &v1alpha1.PrometheusMetricSource{
+				Range: &v1alpha1.PrometheusRange{Window: metav1.Duration{Duration: 4 * time.Minute}, Aggregation: v1alpha1.EWMAAggregation},
+			},
+		}}}
+		updater.UpdateSpecWithDefaults(spec)
+
+		window := spec.Metrics[0].Prometheus.Range
+		Expect(window.Aggregation).To(Equal(v1alpha1.AvgAggregation))
+		Expect(window.Step.Duration).To(Equal(30*time.Second), "step should default to a tenth of the window")
+		Expect(window.HalfLife.Duration).To(BeZero(), "half-life is only used by EWMA")
+		Expect(spec.Metrics[1].Prometheus.Range.HalfLife.Duration).To(Equal(time.Minute), "half-life should default to a quarter of the window")
+	})
+
+	It("Kafka lag", func() {
+		updater := NewDefaultsUpdater(&common.KratosParameters{})
+
+		spec := &v1alpha1.KratosSpec{Metrics: []v1alpha1.ScaleMetric{{
+			Type:  v1alpha1.KafkaScaleMetricType,
+			Kafka: &v1alpha1.KafkaMetricSource{},
+		}, {
+			Type:  v1alpha1.KafkaScaleMetricType,
+			Kafka: &v1alpha1.KafkaMetricSource{Lag: v1alpha1.PartitionKafkaLag},
+		}}}
+		updater.UpdateSpecWithDefaults(spec)
+
+		Expect(spec.Metrics[0].Kafka.Lag).To(Equal(v1alpha1.TotalKafkaLag), "lag should default to the total of all partitions")
+		Expect(spec.Metrics[1].Kafka.Lag).To(Equal(v1alpha1.PartitionKafkaLag))
+	})
+
+	It("RabbitMQ queue metric", func() {
+		updater := NewDefaultsUpdater(&common.KratosParameters{})
+
+		spec := &v1alpha1.KratosSpec{Metrics: []v1alpha1.ScaleMetric{{
+			Type:     v1alpha1.RabbitMQScaleMetricType,
+			RabbitMQ: &v1alpha1.RabbitMQMetricSource{},
+		}}}
+		updater.UpdateSpecWithDefaults(spec)
+
+		Expect(spec.Metrics[0].RabbitMQ.QueueMetric).To(Equal(v1alpha1.MessagesRabbitMQQueueMetric), "queue metric should default to the queue depth")
+	})
+
+	It("Fallback", func() {
+		params := &common.KratosParameters{
+			StabilizationWindowSeconds: 200,
+		}
+
+		updater := NewDefaultsUpdater(params)
+
+		spec := &v1alpha1.KratosSpec{}
+		updater.UpdateSpecWithDefaults(spec)
+		Expect(spec.Fallback).NotTo(BeNil(), "fallback should be defaulted")
+		Expect(spec.Fallback.Policy).To(Equal(v1alpha1.HoldFallbackPolicy), "fallback should hold current replicas by default")
+		Expect(spec.Fallback.FailureThreshold).To(Equal(int32(defaultFallbackFailureThreshold)))
+
+		spec = &v1alpha1.KratosSpec{Fallback: &v1alpha1.Fallback{Policy: v1alpha1.MaxFallbackPolicy, FailureThreshold: 5}}
+		updater.UpdateSpecWithDefaults(spec)
+		Expect(spec.Fallback.Policy).To(Equal(v1alpha1.MaxFallbackPolicy), "fallback policy should not be overridden")
+		Expect(spec.Fallback.FailureThreshold).To(Equal(int32(5)), "failure threshold should not be overridden")
+	})
+
+	It("Mode", func() {
+		updater := NewDefaultsUpdater(&common.KratosParameters{})
+
+		spec := &v1alpha1.KratosSpec{}
+		updater.UpdateSpecWithDefaults(spec)
+		Expect(spec.Mode).To(Equal(v1alpha1.ActiveAutoscalerMode))
+
+		spec = &v1alpha1.KratosSpec{Mode: v1alpha1.DryRunAutoscalerMode}
+		updater.UpdateSpecWithDefaults(spec)
+		Expect(spec.Mode).To(Equal(v1alpha1.DryRunAutoscalerMode), "mode should not be overridden")
+	})
+
+	It("Idle cooldown", func() {
+		updater := NewDefaultsUpdater(&common.KratosParameters{})
+
+		spec := &v1alpha1.KratosSpec{}
+		updater.UpdateSpecWithDefaults(spec)
+		Expect(spec.IdleCooldownSeconds).To(Equal(int32(defaultIdleCooldownSeconds)))
+
+		spec = &v1alpha1.KratosSpec{IdleCooldownSeconds: 60}
+		updater.UpdateSpecWithDefaults(spec)
+		Expect(spec.IdleCooldownSeconds).To(Equal(int32(60)), "idle cooldown should not be overridden")
+	})
+
 	It("Negative MinReplicas", func() {
 		params := &common.KratosParameters{
 			StabilizationWindowSeconds: 200,
 		}
 
-		updater := newDefaultsUpdater(params)
+		updater := NewDefaultsUpdater(params)
 
 		spec := &v1alpha1.KratosSpec{
-			MinReplicas: -10,
+			MinReplicas: pointer.Int32Ptr(-10),
 		}
 
-		Expect(spec.MinReplicas).To(Equal(int32(-10)))
-		updater.updateSpecWithDefaults(spec)
-		Expect(spec.MinReplicas).To(Equal(int32(0)), "negative min replicas should be set to 0")
+		Expect(spec.GetMinReplicas()).To(Equal(int32(-10)))
+		updater.UpdateSpecWithDefaults(spec)
+		Expect(spec.GetMinReplicas()).To(Equal(int32(0)), "negative min replicas should be set to 0")
 	})
 
 	It("Negative MaxReplicas", func() {
@@ -58,23 +196,49 @@ var _ = Describe("DefaultsUpdater", func() {
 			StabilizationWindowSeconds: 200,
 		}
 
-		updater := newDefaultsUpdater(params)
+		updater := NewDefaultsUpdater(params)
 
 		spec := &v1alpha1.KratosSpec{
 			MaxReplicas: -10,
 		}
 
 		Expect(spec.MaxReplicas).To(Equal(int32(-10)))
-		updater.updateSpecWithDefaults(spec)
+		updater.UpdateSpecWithDefaults(spec)
 		Expect(spec.MaxReplicas).To(Equal(int32(0)), "negative max replicas should be set to 0")
 	})
 
+	It("Negative values kept for validation", func() {
+		updater := NewDefaultsUpdater(&common.KratosParameters{StabilizationWindowSeconds: 200})
+
+		spec := &v1alpha1.KratosSpec{
+			MinReplicas:                pointer.Int32Ptr(-1),
+			StabilizationWindowSeconds: -60,
+			IdleCooldownSeconds:        -300,
+			Fallback:                   &v1alpha1.Fallback{FailureThreshold: -3},
+		}
+
+		updater.SetDefaults(spec)
+
+		Expect(spec.GetMinReplicas()).To(Equal(int32(-1)))
+		Expect(spec.StabilizationWindowSeconds).To(Equal(int32(-60)), "negative values should not be replaced by defaults")
+		Expect(spec.IdleCooldownSeconds).To(Equal(int32(-300)))
+		Expect(spec.Fallback.FailureThreshold).To(Equal(int32(-3)))
+		Expect(spec.Fallback.Policy).To(Equal(v1alpha1.HoldFallbackPolicy), "unset fields should be defaulted")
+
+		updater.UpdateSpecWithDefaults(spec)
+
+		Expect(spec.GetMinReplicas()).To(Equal(int32(0)))
+		Expect(spec.StabilizationWindowSeconds).To(Equal(int32(200)), "negative values should be defaulted before an evaluation")
+		Expect(spec.IdleCooldownSeconds).To(Equal(int32(300)))
+		Expect(spec.Fallback.FailureThreshold).To(Equal(int32(3)))
+	})
+
 	It("Scale policies", func() {
 		params := &common.KratosParameters{
 			StabilizationWindowSeconds: 200,
 		}
 
-		updater := newDefaultsUpdater(params)
+		updater := NewDefaultsUpdater(params)
 
 		behavior := &v1alpha1.ScaleBehavior{}
 		behavior.ScaleUp = &v1alpha1.ScaleRules{
@@ -90,7 +254,7 @@ var _ = Describe("DefaultsUpdater", func() {
 			Behavior: behavior,
 		}
 
-		updater.updateSpecWithDefaults(spec)
+		updater.UpdateSpecWithDefaults(spec)
 		Expect(behavior.ScaleUp.StabilizationWindowSeconds).To(Equal(int32(-1)), "stabilization window should not be updated on  disabled scale")
 		Expect(behavior.ScaleDown.StabilizationWindowSeconds).To(Equal(params.StabilizationWindowSeconds), "stabilization window should be updated to default")
 		Expect(behavior.ScaleDown.SelectPolicy).To(Equal(v1alpha1.MinPolicySelect), "default scale down select policy should be MinPolicySelect")
@@ -0,0 +1,40 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package scale
+
+import (
+	"fmt"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/adobe/kratos/monitoring"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// recordDryRunScale records a scale operation which isn't applied to the target. It isn't added to the scale events,
+// behavior policies limit changes of the replicas of the target, which don't change in dry run.
+// The event and metric are only recorded when the would-be scale differs from the previous one.
+func (f *ScaleFacade) recordDryRunScale(item client.Object, recorder *monitoring.Recorder, status *v1alpha1.KratosStatus, currentReplicas int32, desiredReplicas int32) {
+	message := fmt.Sprintf("would scale target from %d to %d replicas", currentReplicas, desiredReplicas)
+
+	previous := status.DryRunScale
+	if previous == nil || previous.FromReplicas != currentReplicas || previous.ToReplicas != desiredReplicas {
+		status.DryRunScale = &v1alpha1.DryRunScale{FromReplicas: currentReplicas, ToReplicas: desiredReplicas, Timestamp: metav1.NewTime(f.clock.Now())}
+		f.eventRecorder.Event(item, corev1.EventTypeNormal, "DryRunScale", message)
+		recorder.RecordDryRunScale(currentReplicas, desiredReplicas)
+	}
+
+	setCondition(item, status, v1alpha1.AbleToScaleCondition, metav1.ConditionTrue, "DryRun", message)
+}
@@ -0,0 +1,83 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package scale
+
+import (
+	"time"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/adobe/kratos/monitoring"
+	"github.com/adobe/kratos/normalizer"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/tools/record"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+var _ = Describe("Dry run", func() {
+	It("Record would-be scale", func() {
+		recorder := record.NewFakeRecorder(10)
+		now := time.Date(2021, 7, 5, 10, 0, 0, 0, time.UTC)
+		facade := &ScaleFacade{eventRecorder: recorder, clock: testingclock.NewFakeClock(now)}
+		item := &v1alpha1.Kratos{}
+		status := &v1alpha1.KratosStatus{CurrentReplicas: 2}
+		defer monitoring.Forget("Kratos", "default", "dry-run")
+
+		facade.recordDryRunScale(item, monitoring.ForAutoscaler("Kratos", "default", "dry-run", "Deployment/nginx"), status, 2, 5)
+
+		Expect(status.DryRunScale).NotTo(BeNil())
+		Expect(status.DryRunScale.FromReplicas).To(Equal(int32(2)))
+		Expect(status.DryRunScale.ToReplicas).To(Equal(int32(5)))
+		Expect(status.DryRunScale.Timestamp.Time).To(Equal(now))
+		Expect(status.ScaleUpEvents).To(BeEmpty(), "would-be scale should not limit behavior policies")
+
+		condition := meta.FindStatusCondition(status.Conditions, v1alpha1.AbleToScaleCondition)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Reason).To(Equal("DryRun"))
+		Expect(condition.Message).To(Equal("would scale target from 2 to 5 replicas"))
+		Expect(recorder.Events).To(Receive(ContainSubstring("would scale target from 2 to 5 replicas")))
+	})
+
+	It("Same would-be scale on consecutive evaluations", func() {
+		recorder := record.NewFakeRecorder(10)
+		clock := testingclock.NewFakeClock(time.Date(2021, 7, 5, 10, 0, 0, 0, time.UTC))
+		facade := &ScaleFacade{eventRecorder: recorder, clock: clock, replicaNormalizer: normalizer.NewReplicaNormalizer(clock)}
+		item := &v1alpha1.Kratos{}
+		spec := &v1alpha1.KratosSpec{
+			MaxReplicas: 20,
+			Behavior: &v1alpha1.ScaleBehavior{
+				ScaleUp: &v1alpha1.ScaleRules{
+					SelectPolicy: v1alpha1.MaxPolicySelect,
+					Policies:     []v1alpha1.ScalingPolicy{{Type: v1alpha1.PodsScalingPolicy, Value: 4, PeriodSeconds: 60}},
+				},
+				ScaleDown: &v1alpha1.ScaleRules{SelectPolicy: v1alpha1.DisabledPolicySelect},
+			},
+		}
+		status := &v1alpha1.KratosStatus{CurrentReplicas: 2}
+		monitoringRecorder := monitoring.ForAutoscaler("Kratos", "default", "dry-run", "Deployment/nginx")
+		defer monitoring.Forget("Kratos", "default", "dry-run")
+
+		for i := 0; i < 2; i++ {
+			normalization := facade.replicaNormalizer.Normalize(spec, status, 10)
+			Expect(normalization.Replicas).To(Equal(int32(6)), "the limit should start from the replicas of the target")
+
+			facade.recordDryRunScale(item, monitoringRecorder, status, 2, normalization.Replicas)
+			clock.Step(10 * time.Second)
+		}
+
+		Expect(status.DryRunScale.ToReplicas).To(Equal(int32(6)))
+		Expect(recorder.Events).To(HaveLen(1), "the same would-be scale should be reported once")
+	})
+})
@@ -0,0 +1,70 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package scale
+
+import (
+	"fmt"
+
+	"github.com/adobe/kratos/api/common"
+	"github.com/adobe/kratos/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// applyFallback counts the failed evaluation and returns the replicas to use without metrics.
+// The current replicas are kept until the failure threshold is reached, then the fallback policy is applied.
+func (f *ScaleFacade) applyFallback(item client.Object, spec *v1alpha1.KratosSpec, status *v1alpha1.KratosStatus, cause error) int32 {
+	status.ConsecutiveFailures++
+
+	if status.ConsecutiveFailures < spec.Fallback.FailureThreshold {
+		f.eventRecorder.Eventf(item, corev1.EventTypeWarning, "MetricsUnavailable", "keeping %d replicas, failed evaluations: %d/%d, error: %v",
+			status.CurrentReplicas, status.ConsecutiveFailures, spec.Fallback.FailureThreshold, cause.Error())
+		return status.CurrentReplicas
+	}
+
+	fallbackReplicas := getFallbackReplicas(spec, status.CurrentReplicas)
+	message := fmt.Sprintf("%s fallback policy sets %d replicas after %d failed evaluations: %v", spec.Fallback.Policy, fallbackReplicas, status.ConsecutiveFailures, cause.Error())
+
+	f.eventRecorder.Event(item, corev1.EventTypeWarning, "FallbackActive", message)
+	setCondition(item, status, v1alpha1.FallbackCondition, metav1.ConditionTrue, "FailureThresholdReached", message)
+
+	return fallbackReplicas
+}
+
+// resetFallback clears the failure counter once metrics are evaluated again
+func (f *ScaleFacade) resetFallback(item client.Object, status *v1alpha1.KratosStatus) {
+	if meta.IsStatusConditionTrue(status.Conditions, v1alpha1.FallbackCondition) {
+		f.eventRecorder.Eventf(item, corev1.EventTypeNormal, "FallbackResolved", "metrics are available again after %d failed evaluations", status.ConsecutiveFailures)
+	}
+
+	status.ConsecutiveFailures = 0
+	setCondition(item, status, v1alpha1.FallbackCondition, metav1.ConditionFalse, "MetricsEvaluated", "replicas are calculated from metrics")
+}
+
+// getFallbackReplicas returns the replicas of the fallback policy bounded by the min and max replicas,
+// which are already overridden by an active schedule
+func getFallbackReplicas(spec *v1alpha1.KratosSpec, currentReplicas int32) int32 {
+	fallbackReplicas := currentReplicas
+
+	switch spec.Fallback.Policy {
+	case v1alpha1.ReplicasFallbackPolicy:
+		fallbackReplicas = spec.Fallback.Replicas
+	case v1alpha1.MaxFallbackPolicy:
+		fallbackReplicas = spec.MaxReplicas
+	}
+
+	return common.Min(common.Max(fallbackReplicas, spec.GetMinReplicas()), spec.MaxReplicas)
+}
@@ -0,0 +1,108 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package scale
+
+import (
+	"errors"
+	"time"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+)
+
+var _ = Describe("Fallback", func() {
+	var facade *ScaleFacade
+	var item *v1alpha1.Kratos
+	var spec *v1alpha1.KratosSpec
+	var status *v1alpha1.KratosStatus
+	metricsError := errors.New("none of the 1 metrics could be evaluated")
+
+	BeforeEach(func() {
+		facade = &ScaleFacade{eventRecorder: record.NewFakeRecorder(10)}
+		item = &v1alpha1.Kratos{}
+		spec = &v1alpha1.KratosSpec{
+			MinReplicas: pointer.Int32Ptr(1),
+			MaxReplicas: 10,
+			Fallback: &v1alpha1.Fallback{
+				Policy:           v1alpha1.ReplicasFallbackPolicy,
+				Replicas:         6,
+				FailureThreshold: 2,
+			},
+		}
+		status = &v1alpha1.KratosStatus{CurrentReplicas: 4}
+	})
+
+	It("Hold current replicas below failure threshold", func() {
+		replicas := facade.applyFallback(item, spec, status, metricsError)
+
+		Expect(replicas).To(Equal(int32(4)), "current replicas should be kept below failure threshold")
+		Expect(status.ConsecutiveFailures).To(Equal(int32(1)))
+		Expect(meta.IsStatusConditionTrue(status.Conditions, v1alpha1.FallbackCondition)).To(BeFalse())
+	})
+
+	It("Apply policy at failure threshold", func() {
+		facade.applyFallback(item, spec, status, metricsError)
+		replicas := facade.applyFallback(item, spec, status, metricsError)
+
+		Expect(replicas).To(Equal(int32(6)), "fallback replicas should be used at failure threshold")
+		Expect(status.ConsecutiveFailures).To(Equal(int32(2)))
+		Expect(meta.IsStatusConditionTrue(status.Conditions, v1alpha1.FallbackCondition)).To(BeTrue())
+	})
+
+	It("Resume after metrics recover", func() {
+		facade.applyFallback(item, spec, status, metricsError)
+		facade.applyFallback(item, spec, status, metricsError)
+		facade.resetFallback(item, status)
+
+		Expect(status.ConsecutiveFailures).To(Equal(int32(0)))
+		Expect(meta.IsStatusConditionFalse(status.Conditions, v1alpha1.FallbackCondition)).To(BeTrue())
+	})
+
+	It("Fallback policies", func() {
+		spec.Fallback.Policy = v1alpha1.HoldFallbackPolicy
+		Expect(getFallbackReplicas(spec, 4)).To(Equal(int32(4)))
+
+		spec.Fallback.Policy = v1alpha1.ReplicasFallbackPolicy
+		Expect(getFallbackReplicas(spec, 4)).To(Equal(int32(6)))
+
+		spec.Fallback.Policy = v1alpha1.MaxFallbackPolicy
+		Expect(getFallbackReplicas(spec, 4)).To(Equal(int32(10)))
+	})
+	It("Bound fallback replicas by min and max replicas", func() {
+		spec.Fallback.Replicas = 15
+		Expect(getFallbackReplicas(spec, 4)).To(Equal(int32(10)))
+
+		spec.MinReplicas = pointer.Int32Ptr(8)
+		spec.Fallback.Replicas = 6
+		Expect(getFallbackReplicas(spec, 4)).To(Equal(int32(8)))
+
+		spec.Fallback.Policy = v1alpha1.HoldFallbackPolicy
+		Expect(getFallbackReplicas(spec, 4)).To(Equal(int32(8)), "held replicas should respect the min replicas of a schedule")
+	})
+
+	It("Apply fallback within the limits of an active schedule", func() {
+		spec.Schedules = []v1alpha1.Schedule{{Name: "business-hours", Cron: "0 8 * * MON-FRI", Duration: metav1.Duration{Duration: 10 * time.Hour}, MinReplicas: pointer.Int32Ptr(8)}}
+		facade.applySchedules(item, spec, status, time.Date(2021, 7, 5, 12, 0, 0, 0, time.UTC))
+
+		facade.applyFallback(item, spec, status, metricsError)
+		replicas := facade.applyFallback(item, spec, status, metricsError)
+
+		Expect(replicas).To(Equal(int32(8)), "fallback replicas below the scheduled min replicas should be raised")
+	})
+})
@@ -0,0 +1,64 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package scale
+
+import (
+	"math"
+
+	"github.com/adobe/kratos/api/common"
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/adobe/kratos/metrics"
+	"github.com/adobe/kratos/replicas"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// applyForecast records the forecast of the metric in metricStatus and returns the proposal used for scaling.
+// In Scale mode a forecast with enough confidence is combined with the proposal for the current value, the higher one wins.
+// A failed forecast is reported without failing the metric, the proposal for the current value is used.
+func (f *ScaleFacade) applyForecast(item client.Object, algorithm replicas.Algorithm, fetchContext *metrics.FetchContext, requestedResources map[string]*corev1.ResourceList, metric v1alpha1.ScaleMetric, replicaProposal int32, metricStatus *v1alpha1.MetricStatus) int32 {
+	prediction := metric.Prometheus.Prediction
+	forecastStatus := &v1alpha1.ForecastStatus{Model: prediction.Model}
+	metricStatus.Forecast = forecastStatus
+
+	forecast, err := f.predictor.Predict(&metric, fetchContext, f.clock.Now())
+	if err != nil {
+		f.eventRecorder.Eventf(item, corev1.EventTypeWarning, "ForecastError", "can't forecast metric: %s, error: %v", metricStatus.Name, err.Error())
+		forecastStatus.Error = err.Error()
+		return replicaProposal
+	}
+
+	forecastStatus.Value = sumMetricValues([]metrics.MetricValue{{Value: forecast.Value}})
+	forecastStatus.Time = &metav1.Time{Time: forecast.Time}
+	forecastStatus.Confidence = int32(math.Round(forecast.Confidence * 100))
+
+	forecastProposal, err := algorithm.CalculateReplicas(fetchContext.Replicas, requestedResources, metric, []metrics.MetricValue{{Value: forecast.Value}})
+	if err != nil {
+		forecastStatus.Error = err.Error()
+		return replicaProposal
+	}
+
+	forecastStatus.ProposedReplicas = &forecastProposal
+
+	f.log.V(1).Info("forecast replica proposal", "replicas", forecastProposal, "forecast", forecast)
+
+	if prediction.Mode != v1alpha1.ScalePredictionMode || forecastStatus.Confidence < prediction.MinConfidence {
+		return replicaProposal
+	}
+
+	forecastStatus.Applied = true
+
+	return common.Max(replicaProposal, forecastProposal)
+}
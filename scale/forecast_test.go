@@ -0,0 +1,122 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package scale
+
+import (
+	"errors"
+	"time"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/adobe/kratos/forecast"
+	"github.com/adobe/kratos/metrics"
+	"github.com/adobe/kratos/replicas"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	testingclock "k8s.io/utils/clock/testing"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// constantHistory returns the same value for the whole history, or an error when it isn't set
+type constantHistory struct {
+	value *float64
+}
+
+func (h *constantHistory) FetchRange(_ *v1alpha1.ScaleMetric, _ *metrics.FetchContext, start time.Time, end time.Time, step time.Duration) ([]metrics.Sample, error) {
+	if h.value == nil {
+		return nil, errors.New("prometheus unavailable")
+	}
+
+	samples := make([]metrics.Sample, 0)
+	for t := start; !t.After(end); t = t.Add(step) {
+		samples = append(samples, metrics.Sample{Timestamp: t, Value: *h.value})
+	}
+	return samples, nil
+}
+
+func (h *constantHistory) GetRangeFetcher(_ *v1alpha1.ScaleMetric) (metrics.RangeFetcher, error) {
+	return h, nil
+}
+
+var _ = Describe("Forecast", func() {
+	var facade *ScaleFacade
+	var history *constantHistory
+	var metric v1alpha1.ScaleMetric
+	var metricStatus *v1alpha1.MetricStatus
+	item := &v1alpha1.Kratos{}
+	fetchContext := &metrics.FetchContext{Namespace: "default", Replicas: 2}
+	algorithm, _ := replicas.NewAlgorithmRegistry().GetAlgorithm(&v1alpha1.Algorithm{Type: v1alpha1.HpaAlgorithmType})
+	averageValue := resource.MustParse("100")
+	historyValue := float64(800)
+
+	BeforeEach(func() {
+		history = &constantHistory{value: &historyValue}
+		facade = &ScaleFacade{log: ctrl.Log.WithName("test"), eventRecorder: record.NewFakeRecorder(10), predictor: forecast.NewPredictor(history),
+			clock: testingclock.NewFakeClock(time.Date(2021, 7, 5, 10, 0, 0, 0, time.UTC))}
+		metric = v1alpha1.ScaleMetric{
+			Type: v1alpha1.PrometheusScaleMetricType,
+			Prometheus: &v1alpha1.PrometheusMetricSource{
+				MetricQuery: "sum(rate(nginx_server_requests[1m]))",
+				Target:      v1alpha1.MetricTarget{Type: v1alpha1.AverageValueMetricType, AverageValue: &averageValue},
+				Prediction: &v1alpha1.Prediction{
+					Mode:           v1alpha1.ScalePredictionMode,
+					Model:          v1alpha1.SeasonalNaivePredictionModel,
+					Season:         metav1.Duration{Duration: time.Hour},
+					HistorySeasons: 2,
+					Step:           metav1.Duration{Duration: 5 * time.Minute},
+					LeadTime:       metav1.Duration{Duration: 10 * time.Minute},
+					MinConfidence:  80,
+				},
+			},
+		}
+		metricStatus = &v1alpha1.MetricStatus{Name: metric.GetMetricName()}
+	})
+
+	It("Forecast proposal applied", func() {
+		proposal := facade.applyForecast(item, algorithm, fetchContext, nil, metric, 2, metricStatus)
+
+		Expect(proposal).To(Equal(int32(8)), "forecast of 800 with average value 100 should propose 8 replicas")
+		Expect(metricStatus.Forecast.Applied).To(BeTrue())
+		Expect(metricStatus.Forecast.Confidence).To(Equal(int32(100)))
+		Expect(metricStatus.Forecast.Value.String()).To(Equal("800"))
+		Expect(*metricStatus.Forecast.ProposedReplicas).To(Equal(int32(8)))
+	})
+
+	It("Higher current proposal wins", func() {
+		proposal := facade.applyForecast(item, algorithm, fetchContext, nil, metric, 12, metricStatus)
+
+		Expect(proposal).To(Equal(int32(12)))
+	})
+
+	It("Observe mode", func() {
+		metric.Prometheus.Prediction.Mode = v1alpha1.ObservePredictionMode
+
+		proposal := facade.applyForecast(item, algorithm, fetchContext, nil, metric, 2, metricStatus)
+
+		Expect(proposal).To(Equal(int32(2)), "observed forecast should not change the proposal")
+		Expect(metricStatus.Forecast.Applied).To(BeFalse())
+		Expect(*metricStatus.Forecast.ProposedReplicas).To(Equal(int32(8)), "observed forecast should be reported")
+	})
+
+	It("Failed forecast", func() {
+		history.value = nil
+
+		proposal := facade.applyForecast(item, algorithm, fetchContext, nil, metric, 2, metricStatus)
+
+		Expect(proposal).To(Equal(int32(2)), "failed forecast should not fail the metric")
+		Expect(metricStatus.Forecast.Error).To(ContainSubstring("prometheus unavailable"))
+	})
+})
@@ -0,0 +1,41 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package scale
+
+import (
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/adobe/kratos/metrics"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// limitProposal caps the proposal of a metric at the replicas its source can keep busy, e.g. the partitions of Kafka topics.
+// The limit is recorded in the metric decision, the proposal is kept when the limit can't be fetched.
+func (f *ScaleFacade) limitProposal(item client.Object, limiter metrics.ReplicaLimiter, fetchContext *metrics.FetchContext, metric v1alpha1.ScaleMetric,
+	replicaProposal int32, metricDecision *v1alpha1.MetricDecision) int32 {
+	limit, err := limiter.MaxReplicas(&metric, fetchContext)
+
+	if err != nil {
+		f.eventRecorder.Eventf(item, corev1.EventTypeWarning, "MetricReplicaLimitError", "can't fetch replica limit of metric: %s, error: %v", metric.GetMetricName(), err.Error())
+		return replicaProposal
+	}
+
+	metricDecision.ReplicaLimit = &limit
+
+	if limit > 0 && replicaProposal > limit {
+		return limit
+	}
+
+	return replicaProposal
+}
@@ -0,0 +1,72 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package scale
+
+import (
+	"errors"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/adobe/kratos/metrics"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/tools/record"
+)
+
+type fakeLimiter struct {
+	limit int32
+	err   error
+}
+
+func (l *fakeLimiter) MaxReplicas(scaleMetric *v1alpha1.ScaleMetric, fetchContext *metrics.FetchContext) (int32, error) {
+	return l.limit, l.err
+}
+
+var _ = Describe("Replica limit", func() {
+	var facade *ScaleFacade
+	var recorder *record.FakeRecorder
+	var metricDecision *v1alpha1.MetricDecision
+	item := &v1alpha1.Kratos{}
+	metric := v1alpha1.ScaleMetric{
+		Type:  v1alpha1.KafkaScaleMetricType,
+		Kafka: &v1alpha1.KafkaMetricSource{ConsumerGroup: "processors", Topics: []string{"orders"}},
+	}
+
+	BeforeEach(func() {
+		recorder = record.NewFakeRecorder(10)
+		facade = &ScaleFacade{eventRecorder: recorder}
+		metricDecision = &v1alpha1.MetricDecision{}
+	})
+
+	It("Cap proposal above limit", func() {
+		replicas := facade.limitProposal(item, &fakeLimiter{limit: 6}, &metrics.FetchContext{}, metric, 9, metricDecision)
+
+		Expect(replicas).To(Equal(int32(6)), "proposal should be capped at the limit")
+		Expect(*metricDecision.ReplicaLimit).To(Equal(int32(6)))
+	})
+
+	It("Keep proposal below limit", func() {
+		replicas := facade.limitProposal(item, &fakeLimiter{limit: 6}, &metrics.FetchContext{}, metric, 4, metricDecision)
+
+		Expect(replicas).To(Equal(int32(4)))
+		Expect(*metricDecision.ReplicaLimit).To(Equal(int32(6)), "limit should be recorded when it doesn't apply")
+	})
+
+	It("Keep proposal without limit", func() {
+		replicas := facade.limitProposal(item, &fakeLimiter{err: errors.New("broker unavailable")}, &metrics.FetchContext{}, metric, 9, metricDecision)
+
+		Expect(replicas).To(Equal(int32(9)), "proposal should be kept when the limit can't be fetched")
+		Expect(metricDecision.ReplicaLimit).To(BeNil())
+		Expect(recorder.Events).To(Receive(ContainSubstring("MetricReplicaLimitError")))
+	})
+})
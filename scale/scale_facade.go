@@ -15,11 +15,15 @@ package scale
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/adobe/kratos/api/common"
 	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/adobe/kratos/forecast"
 	"github.com/adobe/kratos/metrics"
+	"github.com/adobe/kratos/monitoring"
 	"github.com/adobe/kratos/normalizer"
 	"github.com/adobe/kratos/replicas"
 	"github.com/go-logr/logr"
@@ -27,6 +31,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -44,10 +49,14 @@ type ScaleFacade struct {
 	log               logr.Logger
 	scaleTarget       *ScaleTarget
 	metricsFactory    *metrics.MetricsFactory
-	replicaCalculator *replicas.ReplicaCalculator
+	predictor         *forecast.Predictor
+	algorithmRegistry *replicas.AlgorithmRegistry
 	replicaNormalizer *normalizer.ReplicaNormalizer
 	eventRecorder     record.EventRecorder
 	defaultsUpdater   *DefaultsUpdater
+	dryRun            bool
+	decisionHistory   int32
+	clock             clock.Clock
 }
 
 func NewScaleFacade(params *common.KratosParameters) (*ScaleFacade, error) {
@@ -57,84 +66,209 @@ func NewScaleFacade(params *common.KratosParameters) (*ScaleFacade, error) {
 		return nil, err
 	}
 
+	metricsFactory := metrics.NewMetricsFactory(params)
+	clock := params.GetClock()
+
 	facade := &ScaleFacade{
 		client:            params.Client,
 		log:               ctrl.Log.WithName("scale-facade"),
 		scaleTarget:       scaleTarget,
-		metricsFactory:    metrics.NewMetricsFactory(params),
-		replicaCalculator: replicas.NewReplicaCalculator(0.1), //todo params?
-		replicaNormalizer: normalizer.NewReplicaNormalizer(),
+		metricsFactory:    metricsFactory,
+		predictor:         forecast.NewPredictor(metricsFactory),
+		algorithmRegistry: replicas.NewAlgorithmRegistry(),
+		replicaNormalizer: normalizer.NewReplicaNormalizer(clock),
 		eventRecorder:     params.EventRecorder,
-		defaultsUpdater:   newDefaultsUpdater(params),
+		defaultsUpdater:   NewDefaultsUpdater(params),
+		dryRun:            params.DryRun,
+		decisionHistory:   params.DecisionHistory,
+		clock:             clock,
 	}
 
 	return facade, nil
 }
 
-func (f *ScaleFacade) Scale(item *corev1.ConfigMap) {
+// ScaleKratos scales the target of a Kratos resource and writes the results to its status subresource
+func (f *ScaleFacade) ScaleKratos(item *v1alpha1.Kratos) {
+	log := f.log.WithValues("namespace", item.GetNamespace(), "name", item.GetName())
+
+	spec := item.Spec.DeepCopy()
+	status := item.Status.DeepCopy()
+
+	// status is written even when scaling failed, its conditions explain the failure
+	_ = f.Scale(item, spec, status)
+
+	err := f.updateKratosStatus(item, status)
+	if err != nil {
+		log.Error(err, "Error on updating object")
+	}
+}
+
+// ScaleConfigMap scales the target of the spec stored under the 'kratosSpec' key and writes the results to 'kratosStatus'
+func (f *ScaleFacade) ScaleConfigMap(item *corev1.ConfigMap) {
 	log := f.log.WithValues("namespace", item.GetNamespace(), "name", item.GetName())
 
 	log.V(1).Info("unmarshalling")
-	spec, status, err := f.unmarshall(item.Data)
+	spec, status, err := UnmarshallConfigMapData(item.Data)
 	if err != nil {
 		log.Error(err, "error on unmarshalling")
 		f.eventRecorder.Eventf(item, corev1.EventTypeWarning, "UnmarshallError", "can't unmarshall: %v", err.Error())
 		return
 	}
 
+	// status is written even when scaling failed, its conditions explain the failure
+	_ = f.Scale(item, spec, status)
+
+	err = f.updateConfigMap(item, spec, status)
+	if err != nil {
+		log.Error(err, "Error on updating object")
+	}
+}
+
+// Scale evaluates the spec of an autoscaler item, scales its target and records the outcome in status.
+// An error is returned when the scale target can't be retrieved, in which case only the AbleToScale condition is updated.
+// An invalid algorithm is reported with the ScalingActive condition and leaves the target untouched.
+// When no metric can be evaluated the replicas are set by the fallback policy of the spec.
+// An active schedule overrides the replica limits of the spec for the whole evaluation.
+// With min replicas 0 the target is scaled to zero after the idle cooldown and parked until a metric is activated.
+// In DryRun mode, or when the operator runs in dry run, the would-be scale operation is recorded instead of applied.
+// A target controlled by a HorizontalPodAutoscaler or another autoscaler isn't evaluated unless the item allows a takeover.
+// Every evaluation of metrics is explained by a decision which is logged and kept in status.
+func (f *ScaleFacade) Scale(item client.Object, spec *v1alpha1.KratosSpec, status *v1alpha1.KratosStatus) error {
+	log := f.log.WithValues("namespace", item.GetNamespace(), "name", item.GetName())
+
 	log.V(1).Info("updating defaults")
-	f.defaultsUpdater.updateSpecWithDefaults(spec)
+	f.defaultsUpdater.UpdateSpecWithDefaults(spec)
+
+	log.V(1).Info("creating scaling algorithm", "algorithm", spec.Algorithm)
+	algorithm, err := f.algorithmRegistry.GetAlgorithm(&spec.Algorithm)
+	if err != nil {
+		// the spec can't be evaluated until it's fixed, report it in status without touching the target
+		log.Error(err, "error on creating scaling algorithm")
+		f.eventRecorder.Eventf(item, corev1.EventTypeWarning, "InvalidAlgorithm", "can't create scaling algorithm: %v", err.Error())
+		setCondition(item, status, v1alpha1.ScalingActiveCondition, metav1.ConditionFalse, "InvalidAlgorithm", err.Error())
+		return nil
+	}
 
 	log.V(1).Info("retrieving scale target")
-	scaleObject, groupResource, err := f.scaleTarget.GetScaleTarget(item.Namespace, &spec.Target)
+	scaleObject, groupResource, err := f.scaleTarget.GetScaleTarget(item.GetNamespace(), &spec.Target)
 	if err != nil {
 		log.Error(err, "error on retrieving scale target")
 		f.eventRecorder.Eventf(item, corev1.EventTypeWarning, "RetrieveScaleTargetError", "can't retrieve scale target: %v", err.Error())
-		return
+		setCondition(item, status, v1alpha1.AbleToScaleCondition, metav1.ConditionFalse, "FailedGetScale", fmt.Sprintf("can't retrieve scale target: %v", err))
+		return err
 	}
 
 	currentReplicas := scaleObject.Status.Replicas
 	status.CurrentReplicas = currentReplicas
 
+	recorder := monitoring.ForAutoscaler(autoscalerOwner(item).Kind, item.GetNamespace(), item.GetName(), fmt.Sprintf("%s/%s", spec.Target.Kind, spec.Target.Name))
+
 	log.V(1).Info("retrieved scale target", "scaleObject", scaleObject, "status", status)
 
-	defer func() {
-		err := f.updateObjects(item, spec, status)
-		if err != nil {
-			log.Error(err, "Error on updating object")
-		}
-	}()
+	dryRun := f.dryRun || spec.Mode == v1alpha1.DryRunAutoscalerMode
+
+	log.V(1).Info("claiming scale target")
+	if !f.claimTarget(item, spec, status, dryRun) {
+		log.Info("scale target is controlled by another controller, skipping evaluation")
+		return nil
+	}
 
 	f.expireRecommendationsAndScaleEvents(spec, status)
 
+	log.V(1).Info("applying schedules")
+	f.applySchedules(item, spec, status, f.clock.Now())
+
+	decision := &v1alpha1.ScalingDecision{Timestamp: metav1.NewTime(f.clock.Now()), CurrentReplicas: currentReplicas}
+
 	log.V(1).Info("calculating max replicas using metrics")
-	desiredReplicas := f.calculateMaxScaleReplicas(item, currentReplicas, spec)
-	log.V(1).Info("desired max replicas", "replicas", desiredReplicas)
+	desiredReplicas, err := f.calculateMaxScaleReplicas(item, algorithm, currentReplicas, spec, status, decision)
+	recordMetricStatuses(recorder, spec, status.CurrentMetrics)
+
+	var normalizedReplicas int32
+	if err != nil {
+		log.Error(err, "error on evaluating metrics")
+		setCondition(item, status, v1alpha1.ScalingActiveCondition, metav1.ConditionFalse, "FailedGetMetrics", err.Error())
+		normalizedReplicas = f.applyFallback(item, spec, status, err)
+		log.V(1).Info("fallback replicas", "replicas", normalizedReplicas, "consecutiveFailures", status.ConsecutiveFailures)
+		decision.Error = err.Error()
+	} else {
+		f.resetFallback(item, status)
+		setCondition(item, status, v1alpha1.ScalingActiveCondition, metav1.ConditionTrue, "ValidMetricFound", fmt.Sprintf("replicas are calculated from metrics using the '%s' algorithm", spec.Algorithm.Type))
+		log.V(1).Info("desired max replicas", "replicas", desiredReplicas)
+
+		desiredReplicas = f.applyActivation(item, spec, status, desiredReplicas, f.clock.Now())
+		log.V(1).Info("desired replicas after activation", "replicas", desiredReplicas, "idleSince", status.IdleSince)
 
-	log.V(1).Info("recording max replicas recommendation")
-	f.recordRecommendation(desiredReplicas, status)
+		limitedReplicas := common.Min(common.Max(desiredReplicas, spec.GetMinReplicas()), spec.MaxReplicas)
 
-	log.V(1).Info("normalizing max replicas using behaviour policies")
-	normalizedReplicas := f.replicaNormalizer.NormalizeReplicas(spec, status, desiredReplicas)
-	log.V(1).Info("normalized replicas", "replicas", normalizedReplicas)
-	f.eventRecorder.Eventf(item, corev1.EventTypeNormal, "CalculateReplicas", "replicas - current: %d, metrics: %d, normalized: %d", status.CurrentReplicas, desiredReplicas, normalizedReplicas)
+		log.V(1).Info("recording max replicas recommendation")
+		f.recordRecommendation(limitedReplicas, status)
+
+		log.V(1).Info("normalizing max replicas using behaviour policies")
+		normalization := f.replicaNormalizer.Normalize(spec, status, limitedReplicas)
+		normalizedReplicas = normalization.Replicas
+		limitReason := normalization.Reason
+		log.V(1).Info("normalized replicas", "replicas", normalizedReplicas, "limitReason", limitReason)
+		f.eventRecorder.Eventf(item, corev1.EventTypeNormal, "CalculateReplicas", "replicas - current: %d, metrics: %d, normalized: %d", status.CurrentReplicas, limitedReplicas, normalizedReplicas)
+
+		setScalingLimitedCondition(item, spec, status, desiredReplicas, normalizedReplicas, limitReason)
+		reason := ScalingLimitReason(spec, desiredReplicas, limitReason)
+		if reason != normalizer.NotLimited {
+			recorder.RecordLimited(string(reason))
+		}
 
-	if normalizedReplicas != status.CurrentReplicas {
+		decision.DesiredReplicas = desiredReplicas
+		decision.Recommendation = limitedReplicas
+		decision.StabilizedReplicas = normalization.StabilizedReplicas
+		decision.LimitReason = string(reason)
+		decision.Limit = normalization.Limit
+	}
+	decision.NormalizedReplicas = normalizedReplicas
+	f.recordDecision(item, status, decision)
+
+	status.DesiredReplicas = normalizedReplicas
+	recorder.RecordReplicas(currentReplicas, normalizedReplicas, spec.GetMinReplicas(), spec.MaxReplicas)
+
+	// replicas of the target after this evaluation, the current ones when scaling failed
+	targetReplicas := currentReplicas
+
+	if !dryRun || normalizedReplicas == status.CurrentReplicas {
+		status.DryRunScale = nil
+	}
+
+	switch {
+	case normalizedReplicas == status.CurrentReplicas:
+		setCondition(item, status, v1alpha1.AbleToScaleCondition, metav1.ConditionTrue, "ReadyForNewScale", "target has the desired number of replicas")
+	case dryRun:
+		log.V(1).Info("dry run, skipping scale of target", "from", currentReplicas, "to", normalizedReplicas)
+		f.recordDryRunScale(item, recorder, status, currentReplicas, normalizedReplicas)
+	default:
 		scaleObject.Spec.Replicas = normalizedReplicas
 
 		log.V(1).Info("scaling target", "namespace", scaleObject.GetNamespace(), "name", scaleObject.GetName(), "replicas", normalizedReplicas)
-		err := f.scaleTarget.Scale(item.Namespace, groupResource, scaleObject)
+		err := f.scaleTarget.Scale(item.GetNamespace(), groupResource, scaleObject)
 
 		if err == nil {
+			targetReplicas = normalizedReplicas
+			status.LastScaleTime = &metav1.Time{Time: f.clock.Now()}
 			f.recordScaleEvent(currentReplicas, normalizedReplicas, status)
+			recorder.RecordScale(currentReplicas, normalizedReplicas)
+			setCondition(item, status, v1alpha1.AbleToScaleCondition, metav1.ConditionTrue, "SucceededRescale", fmt.Sprintf("scaled target from %d to %d replicas", currentReplicas, normalizedReplicas))
 		} else {
 			log.Error(err, "unable to scale target to desired replicas", "namespace", scaleObject.GetNamespace(), "name", scaleObject.GetName(), "replicas", normalizedReplicas)
 			f.eventRecorder.Eventf(item, corev1.EventTypeWarning, "ScaleError", "can't scale target: %v", err.Error())
+			setCondition(item, status, v1alpha1.AbleToScaleCondition, metav1.ConditionFalse, "FailedUpdateScale", fmt.Sprintf("can't scale target: %v", err))
 		}
 	}
+
+	status.Parked = targetReplicas == 0
+
+	return nil
 }
 
-func (f *ScaleFacade) unmarshall(data map[string]string) (*v1alpha1.KratosSpec, *v1alpha1.KratosStatus, error) {
+// UnmarshallConfigMapData reads the spec under the 'kratosSpec' key and the status under the 'kratosStatus' key of a ConfigMap.
+// A missing status results in an empty one.
+func UnmarshallConfigMapData(data map[string]string) (*v1alpha1.KratosSpec, *v1alpha1.KratosStatus, error) {
 	spec := &v1alpha1.KratosSpec{}
 	status := &v1alpha1.KratosStatus{
 		ScaleUpEvents:   make([]v1alpha1.ScaleChangeEvent, 0, 10),
@@ -181,59 +315,153 @@ func (f *ScaleFacade) marshall(spec *v1alpha1.KratosSpec, status *v1alpha1.Krato
 	return string(specAsBytes), string(statusAsBytes), nil
 }
 
-func (f *ScaleFacade) calculateMaxScaleReplicas(item *corev1.ConfigMap, currentReplicas int32, spec *v1alpha1.KratosSpec) int32 {
+// calculateMaxScaleReplicas returns the highest replica proposal of all metrics and records every evaluation in status.
+// The proposal isn't bounded by min and max replicas. An error is returned when none of the metrics could be evaluated
+// or when the pod selector of the target can't be retrieved.
+// Resource and pod metrics are skipped while the target has no replicas, they aren't reported as failures.
+// The evaluation of every metric and the metric with the highest proposal are explained in decision.
+func (f *ScaleFacade) calculateMaxScaleReplicas(item client.Object, algorithm replicas.Algorithm, currentReplicas int32, spec *v1alpha1.KratosSpec, status *v1alpha1.KratosStatus, decision *v1alpha1.ScalingDecision) (int32, error) {
 	log := f.log.WithValues("namespace", item.GetNamespace(), "name", item.GetName())
 	maxReplicaProposal := int32(0)
+	failedMetrics := make([]string, 0)
+
+	status.CurrentMetrics = make([]v1alpha1.MetricStatus, 0, len(spec.Metrics))
+	decision.Metrics = make([]v1alpha1.MetricDecision, 0, len(spec.Metrics))
 
-	selector, _ := f.scaleTarget.GetSelectorForTarget(item.GetNamespace(), &spec.Target)
+	selector, err := f.scaleTarget.GetSelectorForTarget(item.GetNamespace(), &spec.Target)
+	if err != nil {
+		f.eventRecorder.Eventf(item, corev1.EventTypeWarning, "SelectorError", "can't get pod selector of scale target: %v", err.Error())
+		setCondition(item, status, v1alpha1.MetricsAvailableCondition, metav1.ConditionFalse, "InvalidSelector", fmt.Sprintf("can't get pod selector of scale target: %v", err))
+		return 0, fmt.Errorf("can't get pod selector of scale target: %v", err)
+	}
 
 	requestedResources, _ := f.scaleTarget.GetRequestedResources(item.GetNamespace(), selector)
 
 	f.log.Info("Pods selector and total requested resources", "selector", selector, "requestedResource", requestedResources)
 
+	fetchContext := &metrics.FetchContext{
+		Namespace: item.GetNamespace(),
+		Selector:  selector,
+		Target:    spec.Target,
+		Replicas:  currentReplicas,
+	}
+
 	for _, metric := range spec.Metrics {
-		metricFetcher, err := f.metricsFactory.GetMetricsFetcher(&metric)
+		metricStatus := v1alpha1.MetricStatus{
+			Type: metric.Type,
+			Name: metric.GetMetricName(),
+		}
 
-		if err != nil {
-			log.Error(err, "No fetcher defined for metric type", "type", metric.Type)
-			f.eventRecorder.Eventf(item, corev1.EventTypeWarning, "MetricFetcherTypeError", "No fetcher defined for metric type: %v", err.Error())
+		metricDecision := v1alpha1.MetricDecision{
+			Type: metricStatus.Type,
+			Name: metricStatus.Name,
+		}
+
+		// pod metrics have no value without pods, a parked target is activated by the other metrics
+		if currentReplicas == 0 && requiresPods(&metric) {
+			log.V(1).Info("skipping pod metric of target without replicas", "metric", metric)
+			status.CurrentMetrics = append(status.CurrentMetrics, metricStatus)
+			decision.Metrics = append(decision.Metrics, metricDecision)
 			continue
 		}
 
-		metricValues, err := metricFetcher.Fetch(&metric, item.GetNamespace(), selector)
+		replicaProposal, err := f.evaluateMetric(item, algorithm, fetchContext, requestedResources, metric, &metricStatus, &metricDecision)
+		status.CurrentMetrics = append(status.CurrentMetrics, metricStatus)
+
+		metricDecision.Value = metricStatus.Value
+		metricDecision.Error = metricStatus.Error
+		if err == nil {
+			metricDecision.ProposedReplicas = &replicaProposal
+			metricDecision.ForecastApplied = metricStatus.Forecast != nil && metricStatus.Forecast.Applied
+		}
+		decision.Metrics = append(decision.Metrics, metricDecision)
 
 		if err != nil {
-			log.Error(err, "error on fetching metric", "metric", metric)
-			f.eventRecorder.Eventf(item, corev1.EventTypeWarning, "MetricFetchError", "error on fetching metric type: %s, error: %v", metric.Type, err.Error())
+			log.Error(err, "error on evaluating metric", "metric", metric)
+			failedMetrics = append(failedMetrics, fmt.Sprintf("%s %s", metric.Type, metricStatus.Name))
 			continue
 		}
 
-		replicaProposal, err := f.replicaCalculator.CalculateReplicas(currentReplicas, requestedResources, metric, metricValues)
+		if maxReplicaProposal < replicaProposal || decision.WinningMetric == "" {
+			maxReplicaProposal = common.Max(maxReplicaProposal, replicaProposal)
+			decision.WinningMetric = metricStatus.Name
+		}
+	}
 
-		log.V(1).Info("metric values and replica proposal", "replicas", replicaProposal, "metrics", metricValues)
+	switch {
+	case len(failedMetrics) == 0:
+		setCondition(item, status, v1alpha1.MetricsAvailableCondition, metav1.ConditionTrue, "AllMetricsAvailable", fmt.Sprintf("all %d metrics were evaluated", len(spec.Metrics)))
+	case len(failedMetrics) < len(spec.Metrics):
+		setCondition(item, status, v1alpha1.MetricsAvailableCondition, metav1.ConditionFalse, "SomeMetricsUnavailable", fmt.Sprintf("%d of %d metrics could not be evaluated: %s", len(failedMetrics), len(spec.Metrics), strings.Join(failedMetrics, ", ")))
+	default:
+		setCondition(item, status, v1alpha1.MetricsAvailableCondition, metav1.ConditionFalse, "MetricsUnavailable", fmt.Sprintf("none of the metrics could be evaluated: %s", strings.Join(failedMetrics, ", ")))
+		return 0, fmt.Errorf("none of the %d metrics could be evaluated", len(spec.Metrics))
+	}
 
+	return maxReplicaProposal, nil
+}
+
+// evaluateMetric fetches a metric and calculates its replica proposal, the outcome is recorded in metricStatus
+// and the calculation is explained in metricDecision
+func (f *ScaleFacade) evaluateMetric(item client.Object, algorithm replicas.Algorithm, fetchContext *metrics.FetchContext, requestedResources map[string]*corev1.ResourceList, metric v1alpha1.ScaleMetric, metricStatus *v1alpha1.MetricStatus, metricDecision *v1alpha1.MetricDecision) (int32, error) {
+	metricFetcher, err := f.metricsFactory.GetMetricsFetcher(&metric)
+
+	if err != nil {
+		f.eventRecorder.Eventf(item, corev1.EventTypeWarning, "MetricFetcherTypeError", "No fetcher defined for metric type: %v", err.Error())
+		metricStatus.Error = err.Error()
+		return 0, err
+	}
+
+	if metric.Prometheus != nil {
+		query, err := metrics.RenderQuery(metric.Prometheus.MetricQuery, fetchContext)
 		if err != nil {
-			log.Error(err, "error on calculating replicas proposal for metric", "metric", metric)
-			f.eventRecorder.Eventf(item, corev1.EventTypeWarning, "CalculateMetricReplicasError", "error on calculating replicas proposal for metric: %v, error: %v", metric, err.Error())
+			f.eventRecorder.Eventf(item, corev1.EventTypeWarning, "MetricQueryError", "can't render query of metric: %s, error: %v", metricStatus.Name, err.Error())
+			metricStatus.Error = err.Error()
+			return 0, err
 		}
+		metricStatus.Query = query
+		f.log.V(1).Info("rendered metric query", "metric", metricStatus.Name, "query", query)
+	}
 
-		if maxReplicaProposal < replicaProposal {
-			maxReplicaProposal = replicaProposal
-		}
+	fetchStart := f.clock.Now()
+	metricValues, err := metricFetcher.Fetch(&metric, fetchContext)
+	monitoring.ObserveFetch(string(metric.Type), f.clock.Since(fetchStart), err)
+
+	if err != nil {
+		f.eventRecorder.Eventf(item, corev1.EventTypeWarning, "MetricFetchError", "error on fetching metric type: %s, error: %v", metric.Type, err.Error())
+		metricStatus.Error = err.Error()
+		return 0, err
 	}
 
-	if maxReplicaProposal > spec.MaxReplicas {
-		return spec.MaxReplicas
+	metricStatus.Value = sumMetricValues(metricValues)
+	explainValues(metricValues, metricDecision)
+
+	calculation, err := replicas.ExplainReplicas(algorithm, fetchContext.Replicas, requestedResources, metric, metricValues)
+	replicaProposal := calculation.Replicas
+	explainCalculation(&metric, calculation, metricDecision)
+
+	f.log.V(1).Info("metric values and replica proposal", "replicas", replicaProposal, "metrics", metricValues)
+
+	if err != nil {
+		f.eventRecorder.Eventf(item, corev1.EventTypeWarning, "CalculateMetricReplicasError", "error on calculating replicas proposal for metric: %v, error: %v", metric, err.Error())
+		metricStatus.Error = err.Error()
+		return 0, err
 	}
 
-	if maxReplicaProposal < spec.MinReplicas {
-		return spec.MinReplicas
+	if limiter, ok := metricFetcher.(metrics.ReplicaLimiter); ok {
+		replicaProposal = f.limitProposal(item, limiter, fetchContext, metric, replicaProposal, metricDecision)
+	}
+
+	metricStatus.ProposedReplicas = &replicaProposal
+
+	if metric.Prometheus != nil && metric.Prometheus.Prediction != nil {
+		replicaProposal = f.applyForecast(item, algorithm, fetchContext, requestedResources, metric, replicaProposal, metricStatus)
 	}
 
-	return maxReplicaProposal
+	return replicaProposal, nil
 }
 
-func (f *ScaleFacade) updateObjects(originalItem *corev1.ConfigMap, spec *v1alpha1.KratosSpec, status *v1alpha1.KratosStatus) error {
+func (f *ScaleFacade) updateConfigMap(originalItem *corev1.ConfigMap, spec *v1alpha1.KratosSpec, status *v1alpha1.KratosStatus) error {
 	log := f.log.WithValues("item", originalItem.GetName())
 	_, statusAsString, err := f.marshall(spec, status)
 
@@ -244,7 +472,7 @@ func (f *ScaleFacade) updateObjects(originalItem *corev1.ConfigMap, spec *v1alph
 	log.V(1).Info("creating Patch...")
 
 	patchedConfigMap := originalItem.DeepCopy()
-	patchedConfigMap.Data["kratosStatus"] = statusAsString
+	patchedConfigMap.Data[statusKey] = statusAsString
 
 	log.V(1).Info("updating on server")
 
@@ -252,28 +480,62 @@ func (f *ScaleFacade) updateObjects(originalItem *corev1.ConfigMap, spec *v1alph
 	return err
 }
 
+func (f *ScaleFacade) updateKratosStatus(originalItem *v1alpha1.Kratos, status *v1alpha1.KratosStatus) error {
+	log := f.log.WithValues("item", originalItem.GetName())
+
+	log.V(1).Info("creating status Patch...")
+
+	patchedKratos := originalItem.DeepCopy()
+	patchedKratos.Status = *status
+	// status lists are required by the CRD schema, nil would remove them with the merge patch
+	initializeStatusLists(&patchedKratos.Status)
+
+	log.V(1).Info("updating status on server")
+
+	return f.client.Status().Patch(context.TODO(), patchedKratos, client.MergeFrom(originalItem))
+}
+
+func initializeStatusLists(status *v1alpha1.KratosStatus) {
+	if status.Recommendations == nil {
+		status.Recommendations = make([]v1alpha1.Recommendation, 0, 10)
+	}
+
+	if status.ScaleUpEvents == nil {
+		status.ScaleUpEvents = make([]v1alpha1.ScaleChangeEvent, 0, 10)
+	}
+
+	if status.ScaleDownEvents == nil {
+		status.ScaleDownEvents = make([]v1alpha1.ScaleChangeEvent, 0, 10)
+	}
+}
+
 func (f *ScaleFacade) expireRecommendationsAndScaleEvents(spec *v1alpha1.KratosSpec, status *v1alpha1.KratosStatus) {
+	ExpireHistory(spec, status, f.clock.Now())
+}
+
+// ExpireHistory drops the recommendations and scale events which are outside of the stabilization and policy windows of the spec at now
+func ExpireHistory(spec *v1alpha1.KratosSpec, status *v1alpha1.KratosStatus, now time.Time) {
 	longestScaleUpWindow := int32(0)
 	longestScaleDownWindow := int32(0)
 
 	if spec.Behavior != nil {
 		if spec.Behavior.ScaleUp != nil {
-			longestScaleUpWindow = f.findLongestPolicyWindow(spec.Behavior.ScaleUp.Policies)
+			longestScaleUpWindow = findLongestPolicyWindow(spec.Behavior.ScaleUp.Policies)
 		}
 
 		if spec.Behavior.ScaleDown != nil {
-			longestScaleDownWindow = f.findLongestPolicyWindow(spec.Behavior.ScaleDown.Policies)
+			longestScaleDownWindow = findLongestPolicyWindow(spec.Behavior.ScaleDown.Policies)
 		}
 	}
 
 	maxStabilizationWindowSeconds := common.Max(spec.StabilizationWindowSeconds, common.Max(longestScaleUpWindow, longestScaleDownWindow))
-	status.Recommendations = f.expireRecommendations(maxStabilizationWindowSeconds, status.Recommendations)
+	status.Recommendations = expireRecommendations(now, maxStabilizationWindowSeconds, status.Recommendations)
 
-	status.ScaleUpEvents = f.expireEvents(longestScaleUpWindow, status.ScaleUpEvents)
-	status.ScaleDownEvents = f.expireEvents(longestScaleDownWindow, status.ScaleDownEvents)
+	status.ScaleUpEvents = expireEvents(now, longestScaleUpWindow, status.ScaleUpEvents)
+	status.ScaleDownEvents = expireEvents(now, longestScaleDownWindow, status.ScaleDownEvents)
 }
 
-func (f *ScaleFacade) findLongestPolicyWindow(policies []v1alpha1.ScalingPolicy) int32 {
+func findLongestPolicyWindow(policies []v1alpha1.ScalingPolicy) int32 {
 	if policies == nil || len(policies) == 0 {
 		return 0
 	}
@@ -289,9 +551,9 @@ func (f *ScaleFacade) findLongestPolicyWindow(policies []v1alpha1.ScalingPolicy)
 	return longestWindow
 }
 
-func (f *ScaleFacade) expireRecommendations(windowSeconds int32, recommendations []v1alpha1.Recommendation) []v1alpha1.Recommendation {
+func expireRecommendations(now time.Time, windowSeconds int32, recommendations []v1alpha1.Recommendation) []v1alpha1.Recommendation {
 	result := recommendations[:0]
-	cutOff := time.Now().Add(time.Duration(-windowSeconds) * time.Second)
+	cutOff := now.Add(-time.Duration(windowSeconds) * time.Second)
 	for _, recommendation := range recommendations {
 		if recommendation.Timestamp.Time.After(cutOff) {
 			result = append(result, recommendation)
@@ -300,9 +562,9 @@ func (f *ScaleFacade) expireRecommendations(windowSeconds int32, recommendations
 	return result
 }
 
-func (f *ScaleFacade) expireEvents(windowSeconds int32, events []v1alpha1.ScaleChangeEvent) []v1alpha1.ScaleChangeEvent {
+func expireEvents(now time.Time, windowSeconds int32, events []v1alpha1.ScaleChangeEvent) []v1alpha1.ScaleChangeEvent {
 	result := events[:0]
-	cutOff := time.Now().Add(time.Duration(-windowSeconds))
+	cutOff := now.Add(-time.Duration(windowSeconds) * time.Second)
 	for _, event := range events {
 		if event.Timestamp.Time.After(cutOff) {
 			result = append(result, event)
@@ -312,14 +574,14 @@ func (f *ScaleFacade) expireEvents(windowSeconds int32, events []v1alpha1.ScaleC
 }
 
 func (f *ScaleFacade) recordRecommendation(proposedReplicas int32, status *v1alpha1.KratosStatus) {
-	recommendation := v1alpha1.Recommendation{Replicas: proposedReplicas, Timestamp: metav1.Now()}
+	recommendation := v1alpha1.Recommendation{Replicas: proposedReplicas, Timestamp: metav1.NewTime(f.clock.Now())}
 	status.Recommendations = append(status.Recommendations, recommendation)
 }
 
 func (f *ScaleFacade) recordScaleEvent(currentReplicas int32, proposedReplicas int32, status *v1alpha1.KratosStatus) {
 	if currentReplicas > proposedReplicas {
-		status.ScaleDownEvents = append(status.ScaleDownEvents, v1alpha1.ScaleChangeEvent{Timestamp: metav1.Now(), ReplicaChange: currentReplicas - proposedReplicas})
+		status.ScaleDownEvents = append(status.ScaleDownEvents, v1alpha1.ScaleChangeEvent{Timestamp: metav1.NewTime(f.clock.Now()), ReplicaChange: currentReplicas - proposedReplicas})
 	} else {
-		status.ScaleUpEvents = append(status.ScaleUpEvents, v1alpha1.ScaleChangeEvent{Timestamp: metav1.Now(), ReplicaChange: proposedReplicas - currentReplicas})
+		status.ScaleUpEvents = append(status.ScaleUpEvents, v1alpha1.ScaleChangeEvent{Timestamp: metav1.NewTime(f.clock.Now()), ReplicaChange: proposedReplicas - currentReplicas})
 	}
 }
@@ -0,0 +1,121 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package scale
+
+import (
+	"time"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakescale "k8s.io/client-go/scale/fake"
+	core "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+	testingclock "k8s.io/utils/clock/testing"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("Scale facade history", func() {
+	var facade *ScaleFacade
+	var fakeClock *testingclock.FakeClock
+
+	BeforeEach(func() {
+		fakeClock = testingclock.NewFakeClock(time.Date(2021, 7, 5, 10, 0, 0, 0, time.UTC))
+		facade = &ScaleFacade{clock: fakeClock}
+	})
+
+	ago := func(seconds int) metav1.Time {
+		return metav1.NewTime(fakeClock.Now().Add(-time.Duration(seconds) * time.Second))
+	}
+
+	It("Expire recommendations outside of window", func() {
+		recommendations := []v1alpha1.Recommendation{
+			{Timestamp: ago(61), Replicas: 1},
+			{Timestamp: ago(60), Replicas: 2},
+			{Timestamp: ago(59), Replicas: 3},
+		}
+
+		result := expireRecommendations(fakeClock.Now(), 60, recommendations)
+		Expect(result).To(HaveLen(1))
+		Expect(result[0].Replicas).To(Equal(int32(3)))
+	})
+
+	It("Expire scale events outside of window", func() {
+		events := []v1alpha1.ScaleChangeEvent{
+			{Timestamp: ago(61), ReplicaChange: 1},
+			{Timestamp: ago(60), ReplicaChange: 2},
+			{Timestamp: ago(59), ReplicaChange: 3},
+		}
+
+		result := expireEvents(fakeClock.Now(), 60, events)
+		Expect(result).To(HaveLen(1))
+		Expect(result[0].ReplicaChange).To(Equal(int32(3)))
+	})
+
+	It("Record history with the clock", func() {
+		status := &v1alpha1.KratosStatus{}
+
+		facade.recordRecommendation(3, status)
+		fakeClock.Step(time.Second)
+		facade.recordScaleEvent(1, 3, status)
+
+		Expect(status.Recommendations[0].Timestamp).To(Equal(ago(1)))
+		Expect(status.ScaleUpEvents[0].Timestamp).To(Equal(ago(0)))
+	})
+})
+
+var _ = Describe("Scale facade selector", func() {
+	rolloutKind := schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Rollout"}
+
+	It("Target without selector", func() {
+		mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{rolloutKind.GroupVersion()})
+		mapper.Add(rolloutKind, meta.RESTScopeNamespace)
+
+		scalesGetter := &fakescale.FakeScaleClient{}
+		scalesGetter.AddReactor("get", "rollouts", func(action core.Action) (bool, runtime.Object, error) {
+			return true, &autoscalingv1.Scale{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "checkout"},
+				Status:     autoscalingv1.ScaleStatus{Replicas: 2},
+			}, nil
+		})
+
+		recorder := record.NewFakeRecorder(10)
+		facade := &ScaleFacade{
+			log:           ctrl.Log.WithName("test"),
+			eventRecorder: recorder,
+			scaleTarget:   &ScaleTarget{log: ctrl.Log.WithName("test"), mapper: mapper, scalesGetter: scalesGetter},
+		}
+		item := &v1alpha1.Kratos{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "checkout"}}
+		spec := &v1alpha1.KratosSpec{
+			Target: v1alpha1.ScaleTargetReference{Kind: "Rollout", Name: "checkout", APIVersion: "argoproj.io/v1alpha1"},
+			Metrics: []v1alpha1.ScaleMetric{{
+				Type:     v1alpha1.ResourceScaleMetricType,
+				Resource: &v1alpha1.ResourceMetricSource{Name: corev1.ResourceCPU},
+			}},
+		}
+		status := &v1alpha1.KratosStatus{}
+
+		_, err := facade.calculateMaxScaleReplicas(item, nil, 2, spec, status, &v1alpha1.ScalingDecision{})
+
+		Expect(err).To(MatchError(ContainSubstring("can't get pod selector of scale target")), "metrics should not be fetched without a selector")
+		Expect(meta.FindStatusCondition(status.Conditions, v1alpha1.MetricsAvailableCondition).Reason).To(Equal("InvalidSelector"))
+		Expect(recorder.Events).To(Receive(ContainSubstring("SelectorError")))
+	})
+})
@@ -46,6 +46,8 @@ type ScaleTarget struct {
 	mapper       meta.RESTMapper
 	scalesGetter scale.ScalesGetter
 	informersMap map[string]cache.SharedIndexInformer
+	hpaIndexer   cache.Indexer
+	owners       *targetOwners
 }
 
 const (
@@ -104,11 +106,27 @@ func NewScaleTarget(params *common.KratosParameters) (*ScaleTarget, error) {
 		}
 	}
 
+	// HorizontalPodAutoscalers are indexed by target to detect conflicts, every API version is served as autoscaling/v1
+	hpaInformer := factory.Autoscaling().V1().HorizontalPodAutoscalers().Informer()
+	if err := hpaInformer.AddIndexers(cache.Indexers{hpaTargetIndex: indexHPAByTarget}); err != nil {
+		return nil, err
+	}
+
+	hpaStopCh := make(chan struct{})
+	go hpaInformer.Run(hpaStopCh)
+	if !cache.WaitForCacheSync(hpaStopCh, hpaInformer.HasSynced) {
+		log.Info(fmt.Sprintf("Could not sync cache for %s", hpaKind))
+	} else {
+		log.Info(fmt.Sprintf("Initial sync of %s completed", hpaKind))
+	}
+
 	facade := &ScaleTarget{
 		client:       params.Client,
 		log:          log,
 		mapper:       params.RestMapper,
 		scalesGetter: scalesGetter,
+		hpaIndexer:   hpaInformer.GetIndexer(),
+		owners:       newTargetOwners(),
 	}
 
 	return facade, nil
@@ -0,0 +1,125 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package scale
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/adobe/kratos/cron"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// MaxScheduleDuration bounds the search for the start of an active schedule window
+const MaxScheduleDuration = 7 * 24 * time.Hour
+
+// applySchedules overrides the replica limits of the spec with the first active schedule and reports it in status.
+// Events are emitted when a schedule window opens or closes, invalid schedules are reported and skipped.
+func (f *ScaleFacade) applySchedules(item client.Object, spec *v1alpha1.KratosSpec, status *v1alpha1.KratosStatus, now time.Time) {
+	var active *v1alpha1.Schedule
+	var windowEnd time.Time
+
+	for i := range spec.Schedules {
+		schedule := &spec.Schedules[i]
+
+		end, isActive, err := getScheduleWindowEnd(schedule, now)
+		if err != nil {
+			f.eventRecorder.Eventf(item, corev1.EventTypeWarning, "InvalidSchedule", "schedule %s is ignored: %v", schedule.Name, err.Error())
+			continue
+		}
+
+		if isActive {
+			active = schedule
+			windowEnd = end
+			break
+		}
+	}
+
+	previous := status.ActiveSchedule
+
+	if previous != "" && (active == nil || active.Name != previous) {
+		f.eventRecorder.Eventf(item, corev1.EventTypeNormal, "ScheduleEnded", "schedule %s is no longer active", previous)
+	}
+
+	if active == nil {
+		status.ActiveSchedule = ""
+		return
+	}
+
+	overrideReplicaLimits(spec, active)
+
+	if active.Name != previous {
+		f.eventRecorder.Eventf(item, corev1.EventTypeNormal, "ScheduleStarted", "schedule %s is active until %s, replicas - min: %d, max: %d",
+			active.Name, windowEnd.Format(time.RFC3339), spec.GetMinReplicas(), spec.MaxReplicas)
+	}
+
+	status.ActiveSchedule = active.Name
+}
+
+// getScheduleWindowEnd returns the end of the schedule window containing now, if there is one
+func getScheduleWindowEnd(schedule *v1alpha1.Schedule, now time.Time) (time.Time, bool, error) {
+	expression, err := cron.Parse(schedule.Cron)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	location, err := time.LoadLocation(schedule.TimeZone)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid time zone '%s': %v", schedule.TimeZone, err)
+	}
+
+	duration := schedule.Duration.Duration
+	if duration <= 0 || duration > MaxScheduleDuration {
+		return time.Time{}, false, fmt.Errorf("duration %s must be greater than 0 and not longer than %s", duration, MaxScheduleDuration)
+	}
+
+	// a window is active when it started within the last duration, the start itself is included
+	start, found := expression.Previous(now.In(location), now.Add(-duration).Add(time.Nanosecond))
+	if !found {
+		return time.Time{}, false, nil
+	}
+
+	return start.Add(duration), true, nil
+}
+
+// overrideReplicaLimits sets the replica limits of the schedule, a limit set by the schedule wins over the spec
+func overrideReplicaLimits(spec *v1alpha1.KratosSpec, schedule *v1alpha1.Schedule) {
+	minReplicas := spec.GetMinReplicas()
+
+	switch {
+	case schedule.Replicas != nil:
+		minReplicas = *schedule.Replicas
+		spec.MaxReplicas = *schedule.Replicas
+	default:
+		if schedule.MinReplicas != nil {
+			minReplicas = *schedule.MinReplicas
+		}
+
+		if schedule.MaxReplicas != nil {
+			spec.MaxReplicas = *schedule.MaxReplicas
+		}
+
+		switch {
+		case minReplicas <= spec.MaxReplicas:
+		case schedule.MaxReplicas != nil:
+			minReplicas = spec.MaxReplicas
+		default:
+			spec.MaxReplicas = minReplicas
+		}
+	}
+
+	spec.MinReplicas = &minReplicas
+}
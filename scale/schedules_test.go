@@ -0,0 +1,129 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package scale
+
+import (
+	"time"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+)
+
+var _ = Describe("Schedules", func() {
+	var facade *ScaleFacade
+	var recorder *record.FakeRecorder
+	var spec *v1alpha1.KratosSpec
+	var status *v1alpha1.KratosStatus
+	item := &v1alpha1.Kratos{}
+	businessHoursMin := int32(20)
+	pinnedReplicas := int32(2)
+	// 2021-07-05 is a monday
+	monday := func(hour int, minute int) time.Time {
+		return time.Date(2021, 7, 5, hour, minute, 0, 0, time.UTC)
+	}
+
+	BeforeEach(func() {
+		recorder = record.NewFakeRecorder(10)
+		facade = &ScaleFacade{eventRecorder: recorder}
+		spec = &v1alpha1.KratosSpec{
+			MinReplicas: pointer.Int32Ptr(2),
+			MaxReplicas: 10,
+			Schedules: []v1alpha1.Schedule{
+				{
+					Name:        "business-hours",
+					Cron:        "0 8 * * MON-FRI",
+					Duration:    metav1.Duration{Duration: 10 * time.Hour},
+					MinReplicas: &businessHoursMin,
+				},
+				{
+					Name:     "maintenance",
+					Cron:     "0 2 * * *",
+					Duration: metav1.Duration{Duration: time.Hour},
+					Replicas: &pinnedReplicas,
+				},
+			},
+		}
+		status = &v1alpha1.KratosStatus{}
+	})
+
+	It("Window opens", func() {
+		facade.applySchedules(item, spec, status, monday(8, 0))
+
+		Expect(status.ActiveSchedule).To(Equal("business-hours"))
+		Expect(spec.GetMinReplicas()).To(Equal(int32(20)))
+		Expect(spec.MaxReplicas).To(Equal(int32(20)), "max replicas should be raised to the scheduled min replicas")
+		Expect(recorder.Events).To(Receive(ContainSubstring("ScheduleStarted")))
+	})
+
+	It("Window closes", func() {
+		status.ActiveSchedule = "business-hours"
+
+		facade.applySchedules(item, spec, status, monday(18, 0))
+
+		Expect(status.ActiveSchedule).To(Equal(""))
+		Expect(spec.GetMinReplicas()).To(Equal(int32(2)))
+		Expect(recorder.Events).To(Receive(ContainSubstring("ScheduleEnded")))
+	})
+
+	It("Active window without new events", func() {
+		status.ActiveSchedule = "business-hours"
+
+		facade.applySchedules(item, spec, status, monday(12, 0))
+
+		Expect(status.ActiveSchedule).To(Equal("business-hours"))
+		Expect(recorder.Events).NotTo(Receive())
+	})
+
+	It("Pinned replicas", func() {
+		facade.applySchedules(item, spec, status, monday(2, 30))
+
+		Expect(status.ActiveSchedule).To(Equal("maintenance"))
+		Expect(spec.GetMinReplicas()).To(Equal(int32(2)))
+		Expect(spec.MaxReplicas).To(Equal(int32(2)))
+	})
+
+	It("Time zone", func() {
+		spec.Schedules[0].TimeZone = "America/New_York"
+
+		facade.applySchedules(item, spec, status, monday(8, 0))
+		Expect(status.ActiveSchedule).To(Equal(""), "08:00 UTC is before business hours in New York")
+
+		facade.applySchedules(item, spec, status, monday(12, 0))
+		Expect(status.ActiveSchedule).To(Equal("business-hours"))
+	})
+
+	It("Invalid schedule is skipped", func() {
+		spec.Schedules[0].Cron = "0 8 * *"
+
+		facade.applySchedules(item, spec, status, monday(8, 0))
+
+		Expect(status.ActiveSchedule).To(Equal(""))
+		Expect(spec.GetMinReplicas()).To(Equal(int32(2)))
+		Expect(recorder.Events).To(Receive(ContainSubstring("InvalidSchedule")))
+	})
+
+	It("Scheduled max replicas below spec min replicas", func() {
+		maxReplicas := int32(1)
+		schedule := &v1alpha1.Schedule{MaxReplicas: &maxReplicas}
+
+		overrideReplicaLimits(spec, schedule)
+
+		Expect(spec.GetMinReplicas()).To(Equal(int32(1)))
+		Expect(spec.MaxReplicas).To(Equal(int32(1)))
+	})
+})
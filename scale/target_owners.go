@@ -0,0 +1,222 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package scale
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+)
+
+const (
+	hpaKind = "HorizontalPodAutoscaler"
+
+	// hpaTargetIndex indexes HorizontalPodAutoscalers by the key of their scale target
+	hpaTargetIndex = "scaleTarget"
+)
+
+// TargetOwner identifies a controller of a scale target, an autoscaler or a HorizontalPodAutoscaler
+type TargetOwner struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+func (o TargetOwner) String() string {
+	return fmt.Sprintf("%s %s/%s", o.Kind, o.Namespace, o.Name)
+}
+
+// targetKey identifies a scale target, the API group is left out since the same workload may be referenced by several versions
+type targetKey struct {
+	Namespace string
+	Kind      string
+	Name      string
+}
+
+func newTargetKey(namespace string, targetRef *v1alpha1.ScaleTargetReference) targetKey {
+	return targetKey{Namespace: namespace, Kind: targetRef.Kind, Name: targetRef.Name}
+}
+
+func (k targetKey) String() string {
+	return fmt.Sprintf("%s/%s/%s", k.Namespace, k.Kind, k.Name)
+}
+
+// ownerClaim is the claim of an autoscaler on a scale target
+type ownerClaim struct {
+	allowTakeover bool
+	created       time.Time
+}
+
+// targetOwners indexes the claims of autoscalers on scale targets, every autoscaler claims at most one target.
+// The owner of a target is derived from all its claims, so it doesn't depend on the order in which autoscalers are reconciled.
+type targetOwners struct {
+	mutex   sync.Mutex
+	claims  map[targetKey]map[TargetOwner]ownerClaim
+	targets map[TargetOwner]targetKey
+}
+
+func newTargetOwners() *targetOwners {
+	return &targetOwners{
+		claims:  make(map[targetKey]map[TargetOwner]ownerClaim),
+		targets: make(map[TargetOwner]targetKey),
+	}
+}
+
+// claim records the claim of the autoscaler on the target, its claim on a previous target is released. Autoscalers which
+// allow a takeover own the target over the ones which don't, otherwise the oldest autoscaler owns it and ties are broken by
+// kind, namespace and name. Returns whether the autoscaler owns the target along with the owner when it doesn't, or the
+// autoscaler it took the target over from when it does.
+func (o *targetOwners) claim(autoscaler TargetOwner, allowTakeover bool, created time.Time, target targetKey) (*TargetOwner, bool) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	o.release(autoscaler)
+	if o.claims[target] == nil {
+		o.claims[target] = make(map[TargetOwner]ownerClaim)
+	}
+	o.claims[target][autoscaler] = ownerClaim{allowTakeover: allowTakeover, created: created}
+	o.targets[autoscaler] = target
+
+	claims := o.claims[target]
+	var owner, runnerUp *TargetOwner
+	for claimant := range claims {
+		claimant := claimant
+		switch {
+		case owner == nil || precedes(claimant, claims[claimant], *owner, claims[*owner]):
+			owner, runnerUp = &claimant, owner
+		case runnerUp == nil || precedes(claimant, claims[claimant], *runnerUp, claims[*runnerUp]):
+			runnerUp = &claimant
+		}
+	}
+
+	if *owner != autoscaler {
+		return owner, false
+	}
+
+	// the other claimants don't allow takeovers themselves when they're refused by an owner allowing takeovers
+	if !allowTakeover {
+		return nil, true
+	}
+
+	return runnerUp, true
+}
+
+// precedes returns true when the first autoscaler owns a target claimed by both autoscalers
+func precedes(first TargetOwner, firstClaim ownerClaim, second TargetOwner, secondClaim ownerClaim) bool {
+	switch {
+	case firstClaim.allowTakeover != secondClaim.allowTakeover:
+		return firstClaim.allowTakeover
+	case !firstClaim.created.Equal(secondClaim.created):
+		return firstClaim.created.Before(secondClaim.created)
+	case first.Kind != second.Kind:
+		return first.Kind < second.Kind
+	case first.Namespace != second.Namespace:
+		return first.Namespace < second.Namespace
+	default:
+		return first.Name < second.Name
+	}
+}
+
+// remove releases the target claimed by the autoscaler
+func (o *targetOwners) remove(autoscaler TargetOwner) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	o.release(autoscaler)
+}
+
+func (o *targetOwners) release(autoscaler TargetOwner) {
+	target, found := o.targets[autoscaler]
+	if !found {
+		return
+	}
+
+	delete(o.targets, autoscaler)
+	delete(o.claims[target], autoscaler)
+	if len(o.claims[target]) == 0 {
+		delete(o.claims, target)
+	}
+}
+
+// indexHPAByTarget is the index function of HorizontalPodAutoscalers by scale target
+func indexHPAByTarget(obj interface{}) ([]string, error) {
+	hpa, ok := obj.(*autoscalingv1.HorizontalPodAutoscaler)
+	if !ok {
+		return nil, nil
+	}
+
+	key := targetKey{Namespace: hpa.Namespace, Kind: hpa.Spec.ScaleTargetRef.Kind, Name: hpa.Spec.ScaleTargetRef.Name}
+	return []string{key.String()}, nil
+}
+
+// ClaimTarget records the claim of the autoscaler, created at the given time, on its scale target. The claim fails when a
+// HorizontalPodAutoscaler or an older autoscaler controls the same target, unless the autoscaler allows a takeover. A claim
+// refused by a HorizontalPodAutoscaler still releases the previous target of the autoscaler. Returns the other controller of the target, if any, and whether the autoscaler may scale the target.
+func (st *ScaleTarget) ClaimTarget(autoscaler TargetOwner, allowTakeover bool, created time.Time, namespace string, targetRef *v1alpha1.ScaleTargetReference) (*TargetOwner, bool) {
+	target := newTargetKey(namespace, targetRef)
+
+	hpa := st.findHPA(target)
+	if hpa != nil && !allowTakeover {
+		st.owners.remove(autoscaler)
+		return hpa, false
+	}
+
+	previous, claimed := st.owners.claim(autoscaler, allowTakeover, created, target)
+	if !claimed {
+		return previous, false
+	}
+
+	if previous != nil {
+		st.log.V(1).Info("scale target taken over", "target", target.String(), "owner", autoscaler.String(), "previousOwner", previous.String())
+		return previous, true
+	}
+
+	return hpa, true
+}
+
+// ReleaseTarget forgets the scale target owned by an autoscaler, used when the autoscaler is deleted
+func (st *ScaleTarget) ReleaseTarget(autoscaler TargetOwner) {
+	st.owners.remove(autoscaler)
+}
+
+// findHPA returns the first HorizontalPodAutoscaler of the target by name, nil when there is none
+func (st *ScaleTarget) findHPA(target targetKey) *TargetOwner {
+	if st.hpaIndexer == nil {
+		return nil
+	}
+
+	objects, err := st.hpaIndexer.ByIndex(hpaTargetIndex, target.String())
+	if err != nil {
+		st.log.Error(err, "can't look up HorizontalPodAutoscalers", "target", target.String())
+		return nil
+	}
+
+	names := make([]string, 0, len(objects))
+	for _, obj := range objects {
+		if hpa, ok := obj.(*autoscalingv1.HorizontalPodAutoscaler); ok {
+			names = append(names, hpa.Name)
+		}
+	}
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	sort.Strings(names)
+	return &TargetOwner{Kind: hpaKind, Namespace: target.Namespace, Name: names[0]}
+}
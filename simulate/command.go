@@ -0,0 +1,251 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package simulate
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/adobe/kratos/api/common"
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/adobe/kratos/replicas"
+	"github.com/adobe/kratos/scale"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	TableOutput = "table"
+	CSVOutput   = "csv"
+)
+
+var resultHeader = []string{"time", "current", "proposals", "desired", "recommendation", "normalized", "limit", "errors"}
+
+// manifest is a Kratos resource, a ConfigMap with a 'kratosSpec' key or a bare spec
+type manifest struct {
+	Kind string            `json:"kind"`
+	Spec json.RawMessage   `json:"spec"`
+	Data map[string]string `json:"data"`
+}
+
+// Run executes the simulate command with the arguments following it on the command line
+func Run(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+
+	specPath := flags.String("spec", "", "Kratos resource, ConfigMap or bare spec in YAML, the first document of the file is used.")
+	seriesPath := flags.String("series", "", "Metric time series with a column or value per spec metric, in the order of the spec.")
+	seriesFormat := flags.String("series-format", "", "Format of the series: csv or json. Detected from the file extension by default.")
+	output := flags.String("output", TableOutput, "Output format: table or csv.")
+	outputPath := flags.String("output-file", "", "File to write the results to instead of stdout.")
+	initialReplicas := flags.Int("replicas", -1, "Replicas of the target before the first point. Defaults to minReplicas.")
+	stabilizationWindowSeconds := flags.Int("stabilization-window-seconds", 300, "Default stabilization window in seconds, as set on the operator.")
+
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: kratos simulate --spec <file> --series <file> [flags]\n\n"+
+			"Replays a metric time series through the scaling algorithm and behavior of a spec and prints\n"+
+			"the recommendation, normalized replicas and limit reason at every point.\n\n")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	if *specPath == "" || *seriesPath == "" {
+		flags.Usage()
+		return fmt.Errorf("--spec and --series are required")
+	}
+
+	if *output != TableOutput && *output != CSVOutput {
+		return fmt.Errorf("unknown output format '%s', supported: %s, %s", *output, TableOutput, CSVOutput)
+	}
+
+	spec, err := readSpec(*specPath)
+	if err != nil {
+		return err
+	}
+
+	scale.NewDefaultsUpdater(&common.KratosParameters{StabilizationWindowSeconds: int32(*stabilizationWindowSeconds)}).UpdateSpecWithDefaults(spec)
+
+	if *seriesFormat == "" {
+		*seriesFormat = CSVFormat
+		if strings.EqualFold(filepath.Ext(*seriesPath), ".json") {
+			*seriesFormat = JSONFormat
+		}
+	}
+
+	seriesFile, err := os.Open(*seriesPath)
+	if err != nil {
+		return err
+	}
+	defer seriesFile.Close()
+
+	series, err := ReadSeries(seriesFile, *seriesFormat, len(spec.Metrics))
+	if err != nil {
+		return fmt.Errorf("can't read series %s: %v", *seriesPath, err)
+	}
+
+	replicaCount := int32(*initialReplicas)
+	if replicaCount < 0 {
+		replicaCount = spec.GetMinReplicas()
+	}
+
+	simulator, err := NewSimulator(spec, replicas.NewAlgorithmRegistry(), replicaCount)
+	if err != nil {
+		return err
+	}
+
+	results := simulator.Run(series)
+
+	writer := stdout
+	if *outputPath != "" {
+		outputFile, err := os.Create(*outputPath)
+		if err != nil {
+			return err
+		}
+		defer outputFile.Close()
+		writer = outputFile
+	}
+
+	if *output == CSVOutput {
+		return WriteCSV(writer, series, results)
+	}
+
+	return WriteTable(writer, series, results)
+}
+
+// readSpec reads the spec of the first document in a YAML file
+func readSpec(path string) (*v1alpha1.KratosSpec, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	document := strings.Split(string(content), "\n---")[0]
+
+	parsed := &manifest{}
+	if err := yaml.Unmarshal([]byte(document), parsed); err != nil {
+		return nil, fmt.Errorf("can't parse %s: %v", path, err)
+	}
+
+	var specAsBytes []byte
+	switch parsed.Kind {
+	case "Kratos":
+		specAsBytes = parsed.Spec
+	case "ConfigMap":
+		specAsString, found := parsed.Data["kratosSpec"]
+		if !found {
+			return nil, fmt.Errorf("'kratosSpec' key not found in ConfigMap %s", path)
+		}
+		specAsBytes = []byte(specAsString)
+	case "":
+		specAsBytes = []byte(document)
+	default:
+		return nil, fmt.Errorf("unsupported kind %s in %s, expected Kratos, ConfigMap or a bare spec", parsed.Kind, path)
+	}
+
+	spec := &v1alpha1.KratosSpec{}
+	if err := yaml.Unmarshal(specAsBytes, spec); err != nil {
+		return nil, fmt.Errorf("can't parse spec in %s: %v", path, err)
+	}
+
+	if len(spec.Metrics) == 0 {
+		return nil, fmt.Errorf("spec in %s has no metrics", path)
+	}
+
+	return spec, nil
+}
+
+// WriteTable writes the results as aligned columns
+func WriteTable(writer io.Writer, series *Series, results []Result) error {
+	tableWriter := tabwriter.NewWriter(writer, 0, 8, 2, ' ', 0)
+
+	fmt.Fprintln(tableWriter, strings.ToUpper(strings.Join(resultHeader, "\t")))
+	for _, result := range results {
+		fmt.Fprintln(tableWriter, strings.Join(formatResult(series, result), "\t"))
+	}
+
+	return tableWriter.Flush()
+}
+
+// WriteCSV writes the results with a header row
+func WriteCSV(writer io.Writer, series *Series, results []Result) error {
+	csvWriter := csv.NewWriter(writer)
+
+	if err := csvWriter.Write(resultHeader); err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		if err := csvWriter.Write(formatResult(series, result)); err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+func formatResult(series *Series, result Result) []string {
+	pointTime := result.Time.Format(time.RFC3339)
+	if series.Relative {
+		pointTime = strconv.FormatFloat(result.Time.Sub(time.Unix(0, 0)).Seconds(), 'f', -1, 64)
+	}
+
+	proposals := make([]string, len(result.Proposals))
+	for i, proposal := range result.Proposals {
+		proposals[i] = "-"
+		if proposal != nil {
+			proposals[i] = strconv.Itoa(int(*proposal))
+		}
+	}
+
+	limit := string(result.LimitReason)
+	switch {
+	case limit == "":
+		limit = "-"
+	case result.Limit != "":
+		limit = fmt.Sprintf("%s: %s", limit, result.Limit)
+	}
+
+	errs := strings.Join(result.Errors, "; ")
+	if errs == "" {
+		errs = "-"
+	}
+
+	return []string{
+		pointTime,
+		strconv.Itoa(int(result.CurrentReplicas)),
+		strings.Join(proposals, ","),
+		strconv.Itoa(int(result.DesiredReplicas)),
+		strconv.Itoa(int(result.Recommendation)),
+		strconv.Itoa(int(result.NormalizedReplicas)),
+		limit,
+		errs,
+	}
+}
@@ -0,0 +1,204 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package simulate
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	CSVFormat  = "csv"
+	JSONFormat = "json"
+)
+
+// Point holds the value of every metric of the spec at a time, in the order of the spec metrics. Missing values are NaN.
+type Point struct {
+	Time   time.Time
+	Values []float64
+}
+
+// Series is a metric time series, times of a relative series are offsets from the unix epoch
+type Series struct {
+	Points   []Point
+	Relative bool
+}
+
+// jsonPoint is a point of a JSON series, time is a RFC 3339 timestamp or seconds since the start of the series
+type jsonPoint struct {
+	Time   json.RawMessage `json:"time"`
+	Values []*float64      `json:"values"`
+}
+
+// ReadSeries reads a CSV or JSON series with a value for each of metricCount metrics at every point.
+// CSV series start with a header row, the first column is the time followed by a column per metric.
+// JSON series are a list of objects with a time and a list of values, null is a missing value.
+// Times are either RFC 3339 timestamps or seconds since the start of the series, they must increase.
+func ReadSeries(reader io.Reader, format string, metricCount int) (*Series, error) {
+	var series *Series
+	var err error
+
+	switch format {
+	case CSVFormat:
+		series, err = readCSV(reader, metricCount)
+	case JSONFormat:
+		series, err = readJSON(reader, metricCount)
+	default:
+		return nil, fmt.Errorf("unknown series format '%s', supported: %s, %s", format, CSVFormat, JSONFormat)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(series.Points) == 0 {
+		return nil, fmt.Errorf("series has no points")
+	}
+
+	for i := 1; i < len(series.Points); i++ {
+		if !series.Points[i].Time.After(series.Points[i-1].Time) {
+			return nil, fmt.Errorf("point %d: time %s is not after the previous point", i+1, series.Points[i].Time.Format(time.RFC3339))
+		}
+	}
+
+	return series, nil
+}
+
+func readCSV(reader io.Reader, metricCount int) (*Series, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = metricCount + 1
+	csvReader.TrimLeadingSpace = true
+
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv series with %d metric columns: %v", metricCount, err)
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("csv series has no header")
+	}
+
+	series := &Series{Points: make([]Point, 0, len(records)-1)}
+
+	for i, record := range records[1:] {
+		line := i + 2
+
+		pointTime, relative, err := parseTime(record[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+
+		if err := series.setRelative(relative, i); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+
+		point := Point{Time: pointTime, Values: make([]float64, metricCount)}
+		for j, cell := range record[1:] {
+			if strings.TrimSpace(cell) == "" {
+				point.Values[j] = math.NaN()
+				continue
+			}
+
+			if point.Values[j], err = strconv.ParseFloat(strings.TrimSpace(cell), 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid value '%s' of metric %d", line, cell, j+1)
+			}
+		}
+
+		series.Points = append(series.Points, point)
+	}
+
+	return series, nil
+}
+
+func readJSON(reader io.Reader, metricCount int) (*Series, error) {
+	var jsonPoints []jsonPoint
+	if err := json.NewDecoder(reader).Decode(&jsonPoints); err != nil {
+		return nil, fmt.Errorf("invalid json series: %v", err)
+	}
+
+	series := &Series{Points: make([]Point, 0, len(jsonPoints))}
+
+	for i, jsonPoint := range jsonPoints {
+		var timeValue string
+		if err := json.Unmarshal(jsonPoint.Time, &timeValue); err != nil {
+			// not a string, it's parsed as seconds
+			timeValue = string(jsonPoint.Time)
+		}
+
+		pointTime, relative, err := parseTime(timeValue)
+		if err != nil {
+			return nil, fmt.Errorf("point %d: %v", i+1, err)
+		}
+
+		if err := series.setRelative(relative, i); err != nil {
+			return nil, fmt.Errorf("point %d: %v", i+1, err)
+		}
+
+		if len(jsonPoint.Values) != metricCount {
+			return nil, fmt.Errorf("point %d: expected %d values, got %d", i+1, metricCount, len(jsonPoint.Values))
+		}
+
+		point := Point{Time: pointTime, Values: make([]float64, metricCount)}
+		for j, value := range jsonPoint.Values {
+			if value == nil {
+				point.Values[j] = math.NaN()
+			} else {
+				point.Values[j] = *value
+			}
+		}
+
+		series.Points = append(series.Points, point)
+	}
+
+	return series, nil
+}
+
+// setRelative checks that all points use the same kind of time
+func (s *Series) setRelative(relative bool, index int) error {
+	if index == 0 {
+		s.Relative = relative
+		return nil
+	}
+
+	if s.Relative != relative {
+		return fmt.Errorf("timestamps and seconds can't be mixed")
+	}
+
+	return nil
+}
+
+// parseTime parses a RFC 3339 timestamp or a number of seconds, seconds are returned as relative time
+func parseTime(value string) (time.Time, bool, error) {
+	value = strings.TrimSpace(value)
+
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		if seconds < 0 || math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+			return time.Time{}, false, fmt.Errorf("invalid time '%s': seconds must be a non negative number", value)
+		}
+		return time.Unix(0, 0).UTC().Add(time.Duration(seconds * float64(time.Second))), true, nil
+	}
+
+	timestamp, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid time '%s': expected a RFC 3339 timestamp or seconds", value)
+	}
+
+	return timestamp, false, nil
+}
@@ -0,0 +1,70 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package simulate
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Series", func() {
+	It("Read csv with timestamps", func() {
+		input := "time,requests,queue\n" +
+			"2021-07-05T10:00:00Z,120,4\n" +
+			"2021-07-05T10:01:00Z,,5\n"
+
+		series, err := ReadSeries(strings.NewReader(input), CSVFormat, 2)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(series.Relative).To(BeFalse())
+		Expect(series.Points).To(HaveLen(2))
+		Expect(series.Points[1].Time).To(Equal(time.Date(2021, 7, 5, 10, 1, 0, 0, time.UTC)))
+		Expect(math.IsNaN(series.Points[1].Values[0])).To(BeTrue(), "empty cell should be a missing value")
+		Expect(series.Points[1].Values[1]).To(Equal(float64(5)))
+	})
+
+	It("Read json with seconds", func() {
+		input := `[{"time": 0, "values": [1.5]}, {"time": 30, "values": [null]}]`
+
+		series, err := ReadSeries(strings.NewReader(input), JSONFormat, 1)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(series.Relative).To(BeTrue())
+		Expect(series.Points[1].Time.Sub(series.Points[0].Time)).To(Equal(30 * time.Second))
+		Expect(series.Points[0].Values[0]).To(Equal(1.5))
+		Expect(math.IsNaN(series.Points[1].Values[0])).To(BeTrue())
+	})
+
+	It("Reject wrong number of columns", func() {
+		_, err := ReadSeries(strings.NewReader("time,requests\n0,1\n"), CSVFormat, 2)
+
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Reject decreasing time", func() {
+		_, err := ReadSeries(strings.NewReader("time,requests\n60,1\n30,1\n"), CSVFormat, 1)
+
+		Expect(err).To(MatchError(ContainSubstring("is not after the previous point")))
+	})
+
+	It("Reject mixed time formats", func() {
+		_, err := ReadSeries(strings.NewReader("time,requests\n0,1\n2021-07-05T10:00:00Z,1\n"), CSVFormat, 1)
+
+		Expect(err).To(MatchError(ContainSubstring("can't be mixed")))
+	})
+})
@@ -0,0 +1,179 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package simulate
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/adobe/kratos/api/common"
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/adobe/kratos/metrics"
+	"github.com/adobe/kratos/normalizer"
+	"github.com/adobe/kratos/replicas"
+	"github.com/adobe/kratos/scale"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+// Result is the outcome of a simulation step
+type Result struct {
+	Time            time.Time
+	CurrentReplicas int32
+	// replicas proposed for every metric, nil when the metric couldn't be evaluated
+	Proposals []*int32
+	// highest proposal of all metrics
+	DesiredReplicas int32
+	// desired replicas bounded by min and max replicas, recorded for the stabilization window
+	Recommendation     int32
+	NormalizedReplicas int32
+	LimitReason        normalizer.LimitReason
+	// policy which limited the normalized replicas, e.g. 'Pods 4 per 60s'
+	Limit  string
+	Errors []string
+}
+
+// Simulator replays metric values through the scaling algorithm and the replica normalizer of a spec.
+// The normalizer runs on a fake clock set to the time of every point. The target is assumed to reach the
+// normalized replicas before the next step. Schedules, predictions and scale to zero are not simulated.
+type Simulator struct {
+	spec       *v1alpha1.KratosSpec
+	algorithm  replicas.Algorithm
+	clock      *testingclock.FakeClock
+	normalizer *normalizer.ReplicaNormalizer
+	status     *v1alpha1.KratosStatus
+}
+
+// NewSimulator creates a simulator for a spec with defaults applied, the target starts with initialReplicas
+func NewSimulator(spec *v1alpha1.KratosSpec, algorithms *replicas.AlgorithmRegistry, initialReplicas int32) (*Simulator, error) {
+	algorithm, err := algorithms.GetAlgorithm(&spec.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	clock := testingclock.NewFakeClock(time.Time{})
+
+	return &Simulator{
+		spec:       spec,
+		algorithm:  algorithm,
+		clock:      clock,
+		normalizer: normalizer.NewReplicaNormalizer(clock),
+		status:     &v1alpha1.KratosStatus{CurrentReplicas: initialReplicas},
+	}, nil
+}
+
+// Run simulates all points of a series
+func (s *Simulator) Run(series *Series) []Result {
+	results := make([]Result, 0, len(series.Points))
+
+	for _, point := range series.Points {
+		results = append(results, s.Step(point))
+	}
+
+	return results
+}
+
+// Step evaluates the metric values of a point, the current replicas are kept when no metric can be evaluated
+func (s *Simulator) Step(point Point) Result {
+	s.advance(point.Time)
+
+	result := Result{
+		Time:            point.Time,
+		CurrentReplicas: s.status.CurrentReplicas,
+		Proposals:       make([]*int32, len(s.spec.Metrics)),
+	}
+
+	evaluated := 0
+	for i := range s.spec.Metrics {
+		proposal, err := s.evaluateMetric(&s.spec.Metrics[i], point.Values[i])
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("metric %d: %v", i+1, err))
+			continue
+		}
+
+		result.Proposals[i] = &proposal
+		evaluated++
+
+		if result.DesiredReplicas < proposal {
+			result.DesiredReplicas = proposal
+		}
+	}
+
+	if evaluated == 0 {
+		result.DesiredReplicas = s.status.CurrentReplicas
+		result.Recommendation = s.status.CurrentReplicas
+		result.NormalizedReplicas = s.status.CurrentReplicas
+		return result
+	}
+
+	result.Recommendation = common.Min(common.Max(result.DesiredReplicas, s.spec.GetMinReplicas()), s.spec.MaxReplicas)
+	s.status.Recommendations = append(s.status.Recommendations, v1alpha1.Recommendation{Replicas: result.Recommendation, Timestamp: metav1.NewTime(s.clock.Now())})
+
+	normalization := s.normalizer.Normalize(s.spec, s.status, result.Recommendation)
+	result.NormalizedReplicas = normalization.Replicas
+	result.LimitReason = scale.ScalingLimitReason(s.spec, result.DesiredReplicas, normalization.Reason)
+	result.Limit = normalization.Limit
+
+	s.recordScaleEvent(normalization.Replicas)
+
+	return result
+}
+
+// evaluateMetric calculates the proposal of a metric from its summed value. The value of a utilization
+// metric is the average utilization of the pods in percent of their requests.
+func (s *Simulator) evaluateMetric(metric *v1alpha1.ScaleMetric, value float64) (int32, error) {
+	metricValue, err := metrics.NewMetricValue(value)
+	if err != nil {
+		return 0, fmt.Errorf("no value")
+	}
+
+	currentReplicas := s.status.CurrentReplicas
+	requestedResources := map[string]*corev1.ResourceList{}
+
+	target, err := metric.GetMetricTarget()
+	if err != nil {
+		return 0, err
+	}
+
+	if target.Type == v1alpha1.UtilizationMetricType && metric.Resource != nil {
+		// every pod requests one unit of the resource, the usage is the utilization of all pods
+		requests := corev1.ResourceList{metric.Resource.Name: *resource.NewQuantity(int64(currentReplicas), resource.DecimalSI)}
+		requestedResources[metric.Resource.Container] = &requests
+		metricValue.Value = value / 100 * float64(currentReplicas)
+	}
+
+	return s.algorithm.CalculateReplicas(currentReplicas, requestedResources, *metric, []metrics.MetricValue{metricValue})
+}
+
+// advance moves the clock to t and expires the history like the operator
+func (s *Simulator) advance(t time.Time) {
+	s.clock.SetTime(t)
+	scale.ExpireHistory(s.spec, s.status, t)
+}
+
+func (s *Simulator) recordScaleEvent(normalizedReplicas int32) {
+	currentReplicas := s.status.CurrentReplicas
+
+	switch {
+	case normalizedReplicas > currentReplicas:
+		s.status.ScaleUpEvents = append(s.status.ScaleUpEvents, v1alpha1.ScaleChangeEvent{Timestamp: metav1.NewTime(s.clock.Now()), ReplicaChange: normalizedReplicas - currentReplicas})
+	case normalizedReplicas < currentReplicas:
+		s.status.ScaleDownEvents = append(s.status.ScaleDownEvents, v1alpha1.ScaleChangeEvent{Timestamp: metav1.NewTime(s.clock.Now()), ReplicaChange: currentReplicas - normalizedReplicas})
+	}
+
+	s.status.DesiredReplicas = normalizedReplicas
+	s.status.CurrentReplicas = normalizedReplicas
+}
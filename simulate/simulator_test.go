@@ -0,0 +1,172 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package simulate
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/adobe/kratos/api/common"
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/adobe/kratos/normalizer"
+	"github.com/adobe/kratos/replicas"
+	"github.com/adobe/kratos/scale"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/pointer"
+)
+
+var _ = Describe("Simulator", func() {
+	var spec *v1alpha1.KratosSpec
+	start := time.Date(2021, 7, 5, 10, 0, 0, 0, time.UTC)
+
+	point := func(seconds int, values ...float64) Point {
+		return Point{Time: start.Add(time.Duration(seconds) * time.Second), Values: values}
+	}
+
+	BeforeEach(func() {
+		averageValue := resource.MustParse("10")
+		spec = &v1alpha1.KratosSpec{
+			MinReplicas: pointer.Int32Ptr(1),
+			MaxReplicas: 20,
+			Metrics: []v1alpha1.ScaleMetric{
+				{
+					Type: v1alpha1.PrometheusScaleMetricType,
+					Prometheus: &v1alpha1.PrometheusMetricSource{
+						MetricQuery: "sum(rate(nginx_server_requests[1m]))",
+						Target:      v1alpha1.MetricTarget{Type: v1alpha1.AverageValueMetricType, AverageValue: &averageValue},
+					},
+				},
+			},
+			Behavior: &v1alpha1.ScaleBehavior{
+				ScaleUp: &v1alpha1.ScaleRules{
+					StabilizationWindowSeconds: 1,
+					Policies:                   []v1alpha1.ScalingPolicy{{Type: v1alpha1.PodsScalingPolicy, Value: 2, PeriodSeconds: 60}},
+				},
+			},
+		}
+		scale.NewDefaultsUpdater(&common.KratosParameters{StabilizationWindowSeconds: 300}).UpdateSpecWithDefaults(spec)
+	})
+
+	It("Limit scale up by policy period", func() {
+		simulator, err := NewSimulator(spec, replicas.NewAlgorithmRegistry(), 2)
+		Expect(err).NotTo(HaveOccurred())
+
+		results := simulator.Run(&Series{Points: []Point{point(0, 100), point(30, 100), point(60, 100)}})
+
+		Expect(results[0].DesiredReplicas).To(Equal(int32(10)))
+		Expect(results[0].NormalizedReplicas).To(Equal(int32(4)))
+		Expect(results[0].LimitReason).To(Equal(normalizer.ScaleUpLimit))
+		Expect(results[0].Limit).To(Equal("Pods 2 per 60s"))
+		Expect(results[1].CurrentReplicas).To(Equal(int32(4)))
+		Expect(results[1].NormalizedReplicas).To(Equal(int32(4)), "scale up within the policy period should be limited")
+		Expect(results[2].NormalizedReplicas).To(Equal(int32(6)), "a new policy period should allow 2 more pods")
+	})
+
+	It("Expire history with the windows of the spec", func() {
+		simulator, _ := NewSimulator(spec, replicas.NewAlgorithmRegistry(), 2)
+
+		simulator.Step(point(0, 100))
+		simulator.Step(point(int(spec.StabilizationWindowSeconds)+1, 100))
+
+		Expect(simulator.status.Recommendations).To(HaveLen(1), "recommendations outside of the stabilization window should expire")
+		Expect(simulator.status.ScaleUpEvents).To(HaveLen(1), "scale events outside of the policy period should expire")
+	})
+
+	It("Bound recommendation by max replicas", func() {
+		spec.Behavior = nil
+		simulator, _ := NewSimulator(spec, replicas.NewAlgorithmRegistry(), 10)
+
+		result := simulator.Step(point(0, 500))
+
+		Expect(result.DesiredReplicas).To(Equal(int32(50)))
+		Expect(result.Recommendation).To(Equal(int32(20)))
+		Expect(result.NormalizedReplicas).To(Equal(int32(20)))
+		Expect(result.LimitReason).To(Equal(normalizer.MaxReplicasLimit))
+	})
+
+	It("Hold replicas without values", func() {
+		simulator, _ := NewSimulator(spec, replicas.NewAlgorithmRegistry(), 3)
+
+		result := simulator.Step(point(0, math.NaN()))
+
+		Expect(result.Proposals[0]).To(BeNil())
+		Expect(result.Errors).To(ConsistOf("metric 1: no value"))
+		Expect(result.NormalizedReplicas).To(Equal(int32(3)))
+	})
+
+	It("Utilization in percent of requests", func() {
+		utilization := int32(50)
+		spec.Behavior = nil
+		spec.Metrics = []v1alpha1.ScaleMetric{
+			{
+				Type: v1alpha1.ResourceScaleMetricType,
+				Resource: &v1alpha1.ResourceMetricSource{
+					Name:   "cpu",
+					Target: v1alpha1.MetricTarget{Type: v1alpha1.UtilizationMetricType, AverageUtilization: &utilization},
+				},
+			},
+		}
+		simulator, _ := NewSimulator(spec, replicas.NewAlgorithmRegistry(), 4)
+
+		result := simulator.Step(point(0, 100))
+
+		Expect(*result.Proposals[0]).To(Equal(int32(8)), "double the target utilization should double the replicas")
+	})
+
+	It("Run command", func() {
+		directory, err := ioutil.TempDir("", "simulate")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(directory)
+
+		specPath := filepath.Join(directory, "scaler.yaml")
+		Expect(ioutil.WriteFile(specPath, []byte(`apiVersion: scaling.core.adobe.com/v1alpha1
+kind: Kratos
+metadata:
+  name: simulated
+spec:
+  minReplicas: 1
+  maxReplicas: 10
+  target:
+    kind: Deployment
+    name: nginx
+  metrics:
+    - type: Prometheus
+      prometheus:
+        metricQuery: sum(rate(nginx_server_requests[1m]))
+        target:
+          type: AverageValue
+          averageValue: 10
+---
+apiVersion: v1
+kind: ConfigMap
+`), 0600)).To(Succeed())
+
+		seriesPath := filepath.Join(directory, "series.csv")
+		Expect(ioutil.WriteFile(seriesPath, []byte("time,requests\n0,40\n60,\n"), 0600)).To(Succeed())
+
+		output := &bytes.Buffer{}
+		err = Run([]string{"--spec", specPath, "--series", seriesPath, "--replicas", "2", "--output", "csv"}, output)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(output.String()).To(Equal("time,current,proposals,desired,recommendation,normalized,limit,errors\n" +
+			"0,2,4,4,4,4,-,-\n" +
+			"60,4,-,4,4,4,-,metric 1: no value\n"))
+	})
+})
@@ -0,0 +1,73 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/adobe/kratos/replicas"
+	"github.com/adobe/kratos/scale"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/yaml"
+)
+
+const specKey = "kratosSpec"
+
+// +kubebuilder:webhook:path=/validate-v1-configmap,mutating=false,failurePolicy=ignore,sideEffects=None,groups="",resources=configmaps,verbs=create;update,versions=v1,name=vconfigmap.scaling.core.adobe.com,admissionReviewVersions=v1
+
+// configMapValidator rejects ConfigMaps whose 'kratosSpec' key holds unknown fields or an invalid spec.
+// Defaults aren't written back, the spec in the ConfigMap is left the way it was authored. Only ConfigMaps with the
+// KratosSpecLabel are sent to the webhook, the marker can't express the object selector of config/webhook/manifests.yaml.
+type configMapValidator struct {
+	defaultsUpdater *scale.DefaultsUpdater
+	algorithms      *replicas.AlgorithmRegistry
+}
+
+func (v *configMapValidator) Handle(_ context.Context, req admission.Request) admission.Response {
+	if req.Operation == admissionv1.Delete {
+		return admission.Allowed("")
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := json.Unmarshal(req.Object.Raw, configMap); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	specAsString, found := configMap.Data[specKey]
+	if !found {
+		return admission.Allowed("")
+	}
+
+	specPath := field.NewPath("data").Key(specKey)
+	spec := &v1alpha1.KratosSpec{}
+
+	if err := yaml.UnmarshalStrict([]byte(specAsString), spec); err != nil {
+		return invalidResponse(schema.GroupKind{Kind: "ConfigMap"}, configMap.Name, field.ErrorList{field.Invalid(specPath, "", err.Error())})
+	}
+
+	v.defaultsUpdater.SetDefaults(spec)
+
+	if errs := ValidateSpec(spec, v.algorithms, specPath); len(errs) > 0 {
+		return invalidResponse(schema.GroupKind{Kind: "ConfigMap"}, configMap.Name, errs)
+	}
+
+	return admission.Allowed("")
+}
@@ -0,0 +1,81 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/adobe/kratos/replicas"
+	"github.com/adobe/kratos/scale"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// +kubebuilder:webhook:path=/mutate-scaling-core-adobe-com-v1alpha1-kratos,mutating=true,failurePolicy=fail,sideEffects=None,groups=scaling.core.adobe.com,resources=kratos,verbs=create;update,versions=v1alpha1,name=mkratos.scaling.core.adobe.com,admissionReviewVersions=v1
+
+// kratosDefaulter applies the spec defaults of the DefaultsUpdater to Kratos resources
+type kratosDefaulter struct {
+	defaultsUpdater *scale.DefaultsUpdater
+}
+
+func (d *kratosDefaulter) Handle(_ context.Context, req admission.Request) admission.Response {
+	kratos := &v1alpha1.Kratos{}
+	if err := json.Unmarshal(req.Object.Raw, kratos); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	// only unset fields are defaulted, invalid values are left for the validating webhook to reject
+	d.defaultsUpdater.SetDefaults(&kratos.Spec)
+
+	defaulted, err := json.Marshal(kratos)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	return admission.PatchResponseFromRaw(req.Object.Raw, defaulted)
+}
+
+// +kubebuilder:webhook:path=/validate-scaling-core-adobe-com-v1alpha1-kratos,mutating=false,failurePolicy=fail,sideEffects=None,groups=scaling.core.adobe.com,resources=kratos,verbs=create;update,versions=v1alpha1,name=vkratos.scaling.core.adobe.com,admissionReviewVersions=v1
+
+// kratosValidator rejects Kratos resources with an invalid spec. Unknown fields can't be rejected, the API server prunes
+// them from custom resources before admission.
+type kratosValidator struct {
+	defaultsUpdater *scale.DefaultsUpdater
+	algorithms      *replicas.AlgorithmRegistry
+}
+
+func (v *kratosValidator) Handle(_ context.Context, req admission.Request) admission.Response {
+	if req.Operation == admissionv1.Delete {
+		return admission.Allowed("")
+	}
+
+	kratos := &v1alpha1.Kratos{}
+	if err := json.Unmarshal(req.Object.Raw, kratos); err != nil {
+		return invalidResponse(v1alpha1.GroupVersion.WithKind("Kratos").GroupKind(), req.Name,
+			field.ErrorList{field.Invalid(field.NewPath("spec"), "", err.Error())})
+	}
+
+	// the validating webhook may run without the mutating one, the spec is validated the way it's evaluated
+	v.defaultsUpdater.SetDefaults(&kratos.Spec)
+
+	if errs := ValidateSpec(&kratos.Spec, v.algorithms, field.NewPath("spec")); len(errs) > 0 {
+		return invalidResponse(v1alpha1.GroupVersion.WithKind("Kratos").GroupKind(), kratos.Name, errs)
+	}
+
+	return admission.Allowed("")
+}
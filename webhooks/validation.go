@@ -0,0 +1,542 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package webhooks
+
+import (
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/adobe/kratos/cron"
+	"github.com/adobe/kratos/metrics"
+	"github.com/adobe/kratos/replicas"
+	"github.com/adobe/kratos/scale"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	maxStabilizationWindowSeconds = 3600
+	maxPolicyPeriodSeconds        = 1800
+	maxIdleCooldownSeconds        = 86400
+	maxHistorySeasons             = 4
+	// bounds the values fetched per season, e.g. a week of 5 minute steps
+	maxSeasonSteps = 2016
+	maxRangeWindow = time.Hour
+	maxRangeSteps  = 1000
+)
+
+var (
+	metricTypes      = []string{string(v1alpha1.ResourceScaleMetricType), string(v1alpha1.PodScaleMetricType), string(v1alpha1.ObjectScaleMetricType), string(v1alpha1.ExternalScaleMetricType), string(v1alpha1.PrometheusScaleMetricType), string(v1alpha1.KafkaScaleMetricType), string(v1alpha1.RabbitMQScaleMetricType)}
+	targetTypes      = []string{string(v1alpha1.UtilizationMetricType), string(v1alpha1.ValueMetricType), string(v1alpha1.AverageValueMetricType)}
+	policySelects    = []string{string(v1alpha1.MaxPolicySelect), string(v1alpha1.MinPolicySelect), string(v1alpha1.DisabledPolicySelect)}
+	policyTypes      = []string{string(v1alpha1.PodsScalingPolicy), string(v1alpha1.PercentScalingPolicy)}
+	predictionModes  = []string{string(v1alpha1.ObservePredictionMode), string(v1alpha1.ScalePredictionMode)}
+	predictionModels = []string{string(v1alpha1.SeasonalNaivePredictionModel), string(v1alpha1.HoltWintersPredictionModel)}
+	aggregations     = []string{string(v1alpha1.AvgAggregation), string(v1alpha1.MaxAggregation), string(v1alpha1.P95Aggregation), string(v1alpha1.LastAggregation), string(v1alpha1.EWMAAggregation)}
+	kafkaLagModes    = []string{string(v1alpha1.TotalKafkaLag), string(v1alpha1.PartitionKafkaLag)}
+	queueMetrics     = []string{string(v1alpha1.MessagesRabbitMQQueueMetric), string(v1alpha1.MessagesReadyRabbitMQQueueMetric), string(v1alpha1.PublishRateRabbitMQQueueMetric), string(v1alpha1.DeliverRateRabbitMQQueueMetric)}
+	autoscalerModes  = []string{string(v1alpha1.ActiveAutoscalerMode), string(v1alpha1.DryRunAutoscalerMode)}
+	fallbackPolicies = []string{string(v1alpha1.HoldFallbackPolicy), string(v1alpha1.ReplicasFallbackPolicy), string(v1alpha1.MaxFallbackPolicy)}
+)
+
+// ValidateSpec checks a spec with the defaults of unset fields applied, the errors point to the invalid fields under fldPath
+func ValidateSpec(spec *v1alpha1.KratosSpec, algorithms *replicas.AlgorithmRegistry, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	allErrs = append(allErrs, validateTargetReference(&spec.Target, fldPath.Child("target"))...)
+	allErrs = append(allErrs, validateReplicas(spec, fldPath)...)
+
+	if _, err := algorithms.GetAlgorithm(&spec.Algorithm); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("algorithm"), spec.Algorithm, err.Error()))
+	}
+
+	if spec.Mode != "" && spec.Mode != v1alpha1.ActiveAutoscalerMode && spec.Mode != v1alpha1.DryRunAutoscalerMode {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("mode"), spec.Mode, autoscalerModes))
+	}
+
+	if spec.StabilizationWindowSeconds < 0 || spec.StabilizationWindowSeconds > maxStabilizationWindowSeconds {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("stabilizationWindowSeconds"), spec.StabilizationWindowSeconds, "must be between 0 and 3600"))
+	}
+
+	if len(spec.Metrics) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("metrics"), "at least one metric is required"))
+	}
+
+	if spec.IdleCooldownSeconds < 0 || spec.IdleCooldownSeconds > maxIdleCooldownSeconds {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("idleCooldownSeconds"), spec.IdleCooldownSeconds, "must be between 0 and 86400"))
+	}
+
+	if spec.GetMinReplicas() == 0 && len(spec.Metrics) > 0 && !hasActivationMetric(spec.Metrics) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minReplicas"), spec.GetMinReplicas(),
+			"scaling to zero requires a Prometheus, Kafka, RabbitMQ, Object or External metric, Resource and Pod metrics have no value without pods"))
+	}
+
+	for i := range spec.Metrics {
+		allErrs = append(allErrs, validateMetric(&spec.Metrics[i], fldPath.Child("metrics").Index(i))...)
+	}
+
+	if spec.Behavior != nil {
+		allErrs = append(allErrs, validateScaleRules(spec.Behavior.ScaleUp, fldPath.Child("behavior", "scaleUp"))...)
+		allErrs = append(allErrs, validateScaleRules(spec.Behavior.ScaleDown, fldPath.Child("behavior", "scaleDown"))...)
+	}
+
+	if spec.Fallback != nil {
+		allErrs = append(allErrs, validateFallback(spec, fldPath.Child("fallback"))...)
+	}
+
+	allErrs = append(allErrs, validateSchedules(spec.Schedules, fldPath.Child("schedules"))...)
+
+	return allErrs
+}
+
+func validateTargetReference(target *v1alpha1.ScaleTargetReference, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if target.Kind == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("kind"), ""))
+	}
+
+	if target.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("name"), ""))
+	}
+
+	return allErrs
+}
+
+func validateReplicas(spec *v1alpha1.KratosSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if spec.GetMinReplicas() < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minReplicas"), spec.GetMinReplicas(), "must be greater than or equal to 0"))
+	}
+
+	if spec.MaxReplicas < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxReplicas"), spec.MaxReplicas, "must be greater than 0"))
+	}
+
+	if spec.GetMinReplicas() > spec.MaxReplicas {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minReplicas"), spec.GetMinReplicas(), "must be less than or equal to maxReplicas"))
+	}
+
+	return allErrs
+}
+
+// hasActivationMetric returns true when a metric can be measured while the target has no pods
+func hasActivationMetric(metrics []v1alpha1.ScaleMetric) bool {
+	for _, metric := range metrics {
+		if metric.Type != v1alpha1.ResourceScaleMetricType && metric.Type != v1alpha1.PodScaleMetricType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func validateMetric(metric *v1alpha1.ScaleMetric, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	switch metric.Type {
+	case v1alpha1.ResourceScaleMetricType:
+		if metric.Resource == nil {
+			return append(allErrs, field.Required(fldPath.Child("resource"), "must be set for metric type Resource"))
+		}
+		if metric.Resource.Name == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("resource", "name"), ""))
+		}
+		allErrs = append(allErrs, validateMetricTarget(&metric.Resource.Target, true, fldPath.Child("resource", "target"))...)
+	case v1alpha1.PodScaleMetricType:
+		if metric.Pods == nil {
+			return append(allErrs, field.Required(fldPath.Child("pods"), "must be set for metric type Pod"))
+		}
+		allErrs = append(allErrs, validateMetricIdentifier(&metric.Pods.Metric, fldPath.Child("pods", "metric"))...)
+		allErrs = append(allErrs, validateMetricTarget(&metric.Pods.Target, false, fldPath.Child("pods", "target"))...)
+	case v1alpha1.ObjectScaleMetricType:
+		if metric.Object == nil {
+			return append(allErrs, field.Required(fldPath.Child("object"), "must be set for metric type Object"))
+		}
+		allErrs = append(allErrs, validateTargetReference(&metric.Object.DescribedObject, fldPath.Child("object", "describedObject"))...)
+		allErrs = append(allErrs, validateMetricIdentifier(&metric.Object.Metric, fldPath.Child("object", "metric"))...)
+		allErrs = append(allErrs, validateMetricTarget(&metric.Object.Target, false, fldPath.Child("object", "target"))...)
+	case v1alpha1.ExternalScaleMetricType:
+		if metric.External == nil {
+			return append(allErrs, field.Required(fldPath.Child("external"), "must be set for metric type External"))
+		}
+		allErrs = append(allErrs, validateMetricIdentifier(&metric.External.Metric, fldPath.Child("external", "metric"))...)
+		allErrs = append(allErrs, validateMetricTarget(&metric.External.Target, false, fldPath.Child("external", "target"))...)
+	case v1alpha1.PrometheusScaleMetricType:
+		if metric.Prometheus == nil {
+			return append(allErrs, field.Required(fldPath.Child("prometheus"), "must be set for metric type Prometheus"))
+		}
+		if metric.Prometheus.MetricQuery == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("prometheus", "metricQuery"), ""))
+		} else if _, err := metrics.RenderQuery(metric.Prometheus.MetricQuery, &metrics.FetchContext{Selector: labels.Everything()}); err != nil {
+			// placeholders are checked against an empty context, their values are only known when the metric is fetched
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("prometheus", "metricQuery"), metric.Prometheus.MetricQuery, err.Error()))
+		}
+		allErrs = append(allErrs, validateMetricTarget(&metric.Prometheus.Target, false, fldPath.Child("prometheus", "target"))...)
+		if metric.Prometheus.Prediction != nil {
+			allErrs = append(allErrs, validatePrediction(metric.Prometheus.Prediction, fldPath.Child("prometheus", "prediction"))...)
+		}
+		if metric.Prometheus.Range != nil {
+			allErrs = append(allErrs, validatePrometheusRange(metric.Prometheus.Range, fldPath.Child("prometheus", "range"))...)
+		}
+		if metric.Prometheus.AuthSecretName != "" {
+			for _, msg := range validation.IsDNS1123Subdomain(metric.Prometheus.AuthSecretName) {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("prometheus", "authSecretName"), metric.Prometheus.AuthSecretName, msg))
+			}
+		}
+	case v1alpha1.KafkaScaleMetricType:
+		if metric.Kafka == nil {
+			return append(allErrs, field.Required(fldPath.Child("kafka"), "must be set for metric type Kafka"))
+		}
+		allErrs = append(allErrs, validateKafka(metric.Kafka, fldPath.Child("kafka"))...)
+	case v1alpha1.RabbitMQScaleMetricType:
+		if metric.RabbitMQ == nil {
+			return append(allErrs, field.Required(fldPath.Child("rabbitmq"), "must be set for metric type RabbitMQ"))
+		}
+		allErrs = append(allErrs, validateRabbitMQ(metric.RabbitMQ, fldPath.Child("rabbitmq"))...)
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("type"), metric.Type, metricTypes))
+	}
+
+	return allErrs
+}
+
+func validateKafka(source *v1alpha1.KafkaMetricSource, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if len(source.Brokers) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("brokers"), "at least one broker is required"))
+	}
+
+	for i, broker := range source.Brokers {
+		if _, port, err := net.SplitHostPort(broker); err != nil || port == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("brokers").Index(i), broker, "must be a host:port address"))
+		}
+	}
+
+	if source.ConsumerGroup == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("consumerGroup"), ""))
+	}
+
+	if len(source.Topics) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("topics"), "at least one topic is required"))
+	}
+
+	for i, topic := range source.Topics {
+		if topic == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("topics").Index(i), ""))
+		}
+	}
+
+	switch source.Lag {
+	case v1alpha1.TotalKafkaLag, v1alpha1.PartitionKafkaLag:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("lag"), source.Lag, kafkaLagModes))
+	}
+
+	if source.AuthSecretName != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(source.AuthSecretName) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("authSecretName"), source.AuthSecretName, msg))
+		}
+	}
+
+	allErrs = append(allErrs, validateMetricTarget(&source.Target, false, fldPath.Child("target"))...)
+
+	return allErrs
+}
+
+func validateRabbitMQ(source *v1alpha1.RabbitMQMetricSource, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if source.ManagementUrl == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("managementUrl"), ""))
+	} else if managementUrl, err := url.Parse(source.ManagementUrl); err != nil || (managementUrl.Scheme != "http" && managementUrl.Scheme != "https") || managementUrl.Host == "" {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("managementUrl"), source.ManagementUrl, "must be an http or https url"))
+	}
+
+	if source.Queue == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("queue"), ""))
+	}
+
+	switch source.QueueMetric {
+	case v1alpha1.MessagesRabbitMQQueueMetric, v1alpha1.MessagesReadyRabbitMQQueueMetric, v1alpha1.PublishRateRabbitMQQueueMetric, v1alpha1.DeliverRateRabbitMQQueueMetric:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("queueMetric"), source.QueueMetric, queueMetrics))
+	}
+
+	if source.AuthSecretName == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("authSecretName"), "the management API requires credentials"))
+	} else {
+		for _, msg := range validation.IsDNS1123Subdomain(source.AuthSecretName) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("authSecretName"), source.AuthSecretName, msg))
+		}
+	}
+
+	allErrs = append(allErrs, validateMetricTarget(&source.Target, false, fldPath.Child("target"))...)
+
+	return allErrs
+}
+
+func validatePrediction(prediction *v1alpha1.Prediction, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	switch prediction.Mode {
+	case v1alpha1.ObservePredictionMode, v1alpha1.ScalePredictionMode:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("mode"), prediction.Mode, predictionModes))
+	}
+
+	switch prediction.Model {
+	case v1alpha1.SeasonalNaivePredictionModel, v1alpha1.HoltWintersPredictionModel:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("model"), prediction.Model, predictionModels))
+	}
+
+	step := prediction.Step.Duration
+	season := prediction.Season.Duration
+
+	if step <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("step"), step.String(), "must be greater than 0"))
+	} else if season <= 0 || season%step != 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("season"), season.String(), "must be a multiple of step"))
+	} else if season/step > maxSeasonSteps {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("season"), season.String(), "must not have more than 2016 steps"))
+	} else if prediction.LeadTime.Duration <= 0 || prediction.LeadTime.Duration > season-step {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("leadTime"), prediction.LeadTime.Duration.String(), "must be greater than 0 and at least a step shorter than the season"))
+	}
+
+	if prediction.HistorySeasons < 2 || prediction.HistorySeasons > maxHistorySeasons {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("historySeasons"), prediction.HistorySeasons, "must be between 2 and 4"))
+	}
+
+	if prediction.MinConfidence < 0 || prediction.MinConfidence > 100 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minConfidence"), prediction.MinConfidence, "must be between 0 and 100"))
+	}
+
+	return allErrs
+}
+
+func validatePrometheusRange(window *v1alpha1.PrometheusRange, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	switch window.Aggregation {
+	case v1alpha1.AvgAggregation, v1alpha1.MaxAggregation, v1alpha1.P95Aggregation, v1alpha1.LastAggregation, v1alpha1.EWMAAggregation:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("aggregation"), window.Aggregation, aggregations))
+	}
+
+	length := window.Window.Duration
+	step := window.Step.Duration
+
+	if length <= 0 || length > maxRangeWindow {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("window"), length.String(), "must be greater than 0 and at most 1h"))
+	} else if step <= 0 || step > length {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("step"), step.String(), "must be greater than 0 and at most the window"))
+	} else if length/step > maxRangeSteps {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("step"), step.String(), "must not divide the window in more than 1000 steps"))
+	}
+
+	if window.Aggregation == v1alpha1.EWMAAggregation && window.HalfLife.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("halfLife"), window.HalfLife.Duration.String(), "must be greater than 0"))
+	}
+
+	return allErrs
+}
+
+func validateMetricIdentifier(metric *v1alpha1.MetricIdentifier, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if metric.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("name"), ""))
+	}
+
+	if metric.Selector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(metric.Selector); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("selector"), metric.Selector, err.Error()))
+		}
+	}
+
+	return allErrs
+}
+
+// validateMetricTarget checks that the value required by the target type is set, utilization is only allowed for resource metrics
+func validateMetricTarget(target *v1alpha1.MetricTarget, allowUtilization bool, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	switch target.Type {
+	case v1alpha1.UtilizationMetricType:
+		if !allowUtilization {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("type"), target.Type, "is only supported for metric type Resource"))
+		} else if target.AverageUtilization == nil {
+			allErrs = append(allErrs, field.Required(fldPath.Child("averageUtilization"), "must be set for target type Utilization"))
+		} else if *target.AverageUtilization <= 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("averageUtilization"), *target.AverageUtilization, "must be greater than 0"))
+		}
+	case v1alpha1.ValueMetricType:
+		allErrs = append(allErrs, validateTargetQuantity(target.Value, fldPath.Child("value"), target.Type)...)
+	case v1alpha1.AverageValueMetricType:
+		allErrs = append(allErrs, validateTargetQuantity(target.AverageValue, fldPath.Child("averageValue"), target.Type)...)
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("type"), target.Type, targetTypes))
+	}
+
+	if target.ActivationThreshold != nil && target.ActivationThreshold.Sign() < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("activationThreshold"), target.ActivationThreshold.String(), "must be greater than or equal to 0"))
+	}
+
+	return allErrs
+}
+
+func validateTargetQuantity(quantity *resource.Quantity, fldPath *field.Path, targetType v1alpha1.MetricTargetType) field.ErrorList {
+	if quantity == nil {
+		return field.ErrorList{field.Required(fldPath, "must be set for target type "+string(targetType))}
+	}
+
+	if quantity.Sign() <= 0 {
+		return field.ErrorList{field.Invalid(fldPath, quantity.String(), "must be greater than 0")}
+	}
+
+	return nil
+}
+
+func validateScaleRules(rules *v1alpha1.ScaleRules, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if rules == nil {
+		return allErrs
+	}
+
+	if rules.StabilizationWindowSeconds < 0 || rules.StabilizationWindowSeconds > maxStabilizationWindowSeconds {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("stabilizationWindowSeconds"), rules.StabilizationWindowSeconds, "must be between 0 and 3600"))
+	}
+
+	switch rules.SelectPolicy {
+	case v1alpha1.MaxPolicySelect, v1alpha1.MinPolicySelect, v1alpha1.DisabledPolicySelect:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("selectPolicy"), rules.SelectPolicy, policySelects))
+	}
+
+	if rules.SelectPolicy != v1alpha1.DisabledPolicySelect && len(rules.Policies) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("policies"), "at least one policy is required unless selectPolicy is Disabled"))
+	}
+
+	for i, policy := range rules.Policies {
+		policyPath := fldPath.Child("policies").Index(i)
+
+		switch policy.Type {
+		case v1alpha1.PodsScalingPolicy, v1alpha1.PercentScalingPolicy:
+		default:
+			allErrs = append(allErrs, field.NotSupported(policyPath.Child("type"), policy.Type, policyTypes))
+		}
+
+		if policy.Value <= 0 {
+			allErrs = append(allErrs, field.Invalid(policyPath.Child("value"), policy.Value, "must be greater than 0"))
+		}
+
+		if policy.PeriodSeconds <= 0 || policy.PeriodSeconds > maxPolicyPeriodSeconds {
+			allErrs = append(allErrs, field.Invalid(policyPath.Child("periodSeconds"), policy.PeriodSeconds, "must be greater than 0 and less than or equal to 1800"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateFallback(spec *v1alpha1.KratosSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	fallback := spec.Fallback
+
+	switch fallback.Policy {
+	case v1alpha1.HoldFallbackPolicy, v1alpha1.MaxFallbackPolicy:
+	case v1alpha1.ReplicasFallbackPolicy:
+		if fallback.Replicas < spec.GetMinReplicas() || fallback.Replicas > spec.MaxReplicas {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("replicas"), fallback.Replicas, "must be between minReplicas and maxReplicas"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("policy"), fallback.Policy, fallbackPolicies))
+	}
+
+	if fallback.FailureThreshold < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("failureThreshold"), fallback.FailureThreshold, "must be greater than 0"))
+	}
+
+	return allErrs
+}
+
+func validateSchedules(schedules []v1alpha1.Schedule, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	names := make(map[string]bool)
+
+	for i, schedule := range schedules {
+		schedulePath := fldPath.Index(i)
+
+		if schedule.Name == "" {
+			allErrs = append(allErrs, field.Required(schedulePath.Child("name"), ""))
+		} else if names[schedule.Name] {
+			allErrs = append(allErrs, field.Duplicate(schedulePath.Child("name"), schedule.Name))
+		}
+		names[schedule.Name] = true
+
+		if _, err := cron.Parse(schedule.Cron); err != nil {
+			allErrs = append(allErrs, field.Invalid(schedulePath.Child("cron"), schedule.Cron, err.Error()))
+		}
+
+		if _, err := time.LoadLocation(schedule.TimeZone); err != nil {
+			allErrs = append(allErrs, field.Invalid(schedulePath.Child("timeZone"), schedule.TimeZone, err.Error()))
+		}
+
+		if schedule.Duration.Duration <= 0 || schedule.Duration.Duration > scale.MaxScheduleDuration {
+			allErrs = append(allErrs, field.Invalid(schedulePath.Child("duration"), schedule.Duration.Duration.String(), "must be greater than 0 and not longer than 168h"))
+		}
+
+		allErrs = append(allErrs, validateScheduleReplicas(&schedule, schedulePath)...)
+	}
+
+	return allErrs
+}
+
+func validateScheduleReplicas(schedule *v1alpha1.Schedule, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if schedule.Replicas == nil && schedule.MinReplicas == nil && schedule.MaxReplicas == nil {
+		return append(allErrs, field.Required(fldPath, "one of replicas, minReplicas or maxReplicas is required"))
+	}
+
+	if schedule.Replicas != nil && (schedule.MinReplicas != nil || schedule.MaxReplicas != nil) {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("replicas"), "may not be set together with minReplicas or maxReplicas"))
+	}
+
+	if schedule.Replicas != nil && *schedule.Replicas < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("replicas"), *schedule.Replicas, "must be greater than or equal to 0"))
+	}
+
+	if schedule.MinReplicas != nil && *schedule.MinReplicas < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minReplicas"), *schedule.MinReplicas, "must be greater than or equal to 0"))
+	}
+
+	if schedule.MaxReplicas != nil && *schedule.MaxReplicas < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxReplicas"), *schedule.MaxReplicas, "must be greater than 0"))
+	}
+
+	if schedule.MinReplicas != nil && schedule.MaxReplicas != nil && *schedule.MinReplicas > *schedule.MaxReplicas {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minReplicas"), *schedule.MinReplicas, "must be less than or equal to maxReplicas"))
+	}
+
+	return allErrs
+}
@@ -0,0 +1,281 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package webhooks
+
+import (
+	"time"
+
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/adobe/kratos/replicas"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
+)
+
+func validSpec() *v1alpha1.KratosSpec {
+	averageValue := resource.MustParse("100m")
+
+	return &v1alpha1.KratosSpec{
+		Target:                     v1alpha1.ScaleTargetReference{Kind: "Deployment", Name: "nginx"},
+		Algorithm:                  v1alpha1.Algorithm{Type: v1alpha1.HpaAlgorithmType},
+		MinReplicas:                pointer.Int32Ptr(1),
+		MaxReplicas:                4,
+		StabilizationWindowSeconds: 300,
+		Metrics: []v1alpha1.ScaleMetric{
+			{
+				Type: v1alpha1.ResourceScaleMetricType,
+				Resource: &v1alpha1.ResourceMetricSource{
+					Name:   "cpu",
+					Target: v1alpha1.MetricTarget{Type: v1alpha1.AverageValueMetricType, AverageValue: &averageValue},
+				},
+			},
+		},
+	}
+}
+
+func prometheusMetric(prediction *v1alpha1.Prediction) v1alpha1.ScaleMetric {
+	averageValue := resource.MustParse("100")
+
+	return v1alpha1.ScaleMetric{
+		Type: v1alpha1.PrometheusScaleMetricType,
+		Prometheus: &v1alpha1.PrometheusMetricSource{
+			MetricQuery: "sum(rate(nginx_server_requests[1m]))",
+			Target:      v1alpha1.MetricTarget{Type: v1alpha1.AverageValueMetricType, AverageValue: &averageValue},
+			Prediction:  prediction,
+		},
+	}
+}
+
+func kafkaMetric() v1alpha1.ScaleMetric {
+	value := resource.MustParse("1000")
+
+	return v1alpha1.ScaleMetric{
+		Type: v1alpha1.KafkaScaleMetricType,
+		Kafka: &v1alpha1.KafkaMetricSource{
+			Brokers:       []string{"kafka-0.kafka:9092"},
+			ConsumerGroup: "processors",
+			Topics:        []string{"orders"},
+			Lag:           v1alpha1.TotalKafkaLag,
+			Target:        v1alpha1.MetricTarget{Type: v1alpha1.AverageValueMetricType, AverageValue: &value},
+		},
+	}
+}
+
+func rabbitMQMetric() v1alpha1.ScaleMetric {
+	averageValue := resource.MustParse("20")
+
+	return v1alpha1.ScaleMetric{
+		Type: v1alpha1.RabbitMQScaleMetricType,
+		RabbitMQ: &v1alpha1.RabbitMQMetricSource{
+			ManagementUrl:  "http://rabbitmq.messaging:15672",
+			Queue:          "orders",
+			QueueMetric:    v1alpha1.MessagesRabbitMQQueueMetric,
+			AuthSecretName: "rabbitmq-credentials",
+			Target:         v1alpha1.MetricTarget{Type: v1alpha1.AverageValueMetricType, AverageValue: &averageValue},
+		},
+	}
+}
+
+var _ = Describe("Validation", func() {
+	algorithms := replicas.NewAlgorithmRegistry()
+
+	It("Valid spec", func() {
+		Expect(ValidateSpec(validSpec(), algorithms, field.NewPath("spec"))).To(BeEmpty())
+	})
+
+	It("Valid scale to zero spec", func() {
+		spec := validSpec()
+		spec.MinReplicas = pointer.Int32Ptr(0)
+		spec.Metrics = append(spec.Metrics, prometheusMetric(nil))
+
+		Expect(ValidateSpec(spec, algorithms, field.NewPath("spec"))).To(BeEmpty())
+	})
+
+	It("Valid Kafka spec scaling to zero", func() {
+		spec := validSpec()
+		spec.MinReplicas = pointer.Int32Ptr(0)
+		spec.Metrics = []v1alpha1.ScaleMetric{kafkaMetric()}
+
+		Expect(ValidateSpec(spec, algorithms, field.NewPath("spec"))).To(BeEmpty())
+	})
+
+	It("Valid RabbitMQ spec", func() {
+		spec := validSpec()
+		spec.Metrics = append(spec.Metrics, rabbitMQMetric())
+
+		Expect(ValidateSpec(spec, algorithms, field.NewPath("spec"))).To(BeEmpty())
+	})
+
+	DescribeTable("Invalid spec",
+		func(update func(spec *v1alpha1.KratosSpec), expectedField string, expectedType field.ErrorType) {
+			spec := validSpec()
+			update(spec)
+
+			errs := ValidateSpec(spec, algorithms, field.NewPath("spec"))
+
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Field).To(Equal(expectedField))
+			Expect(errs[0].Type).To(Equal(expectedType))
+		},
+		Entry("missing target name", func(spec *v1alpha1.KratosSpec) { spec.Target.Name = "" },
+			"spec.target.name", field.ErrorTypeRequired),
+		Entry("min greater than max", func(spec *v1alpha1.KratosSpec) { spec.MinReplicas = pointer.Int32Ptr(5) },
+			"spec.minReplicas", field.ErrorTypeInvalid),
+		Entry("unknown algorithm", func(spec *v1alpha1.KratosSpec) { spec.Algorithm.Type = "linear" },
+			"spec.algorithm", field.ErrorTypeInvalid),
+		Entry("invalid algorithm option", func(spec *v1alpha1.KratosSpec) { spec.Algorithm.Options = map[string]string{"tolerance": "2"} },
+			"spec.algorithm", field.ErrorTypeInvalid),
+		Entry("no metrics", func(spec *v1alpha1.KratosSpec) { spec.Metrics = nil },
+			"spec.metrics", field.ErrorTypeRequired),
+		Entry("unknown metric type", func(spec *v1alpha1.KratosSpec) { spec.Metrics[0].Type = "Queue" },
+			"spec.metrics[0].type", field.ErrorTypeNotSupported),
+		Entry("missing metric source", func(spec *v1alpha1.KratosSpec) { spec.Metrics[0].Type = v1alpha1.PrometheusScaleMetricType },
+			"spec.metrics[0].prometheus", field.ErrorTypeRequired),
+		Entry("missing average value", func(spec *v1alpha1.KratosSpec) { spec.Metrics[0].Resource.Target.AverageValue = nil },
+			"spec.metrics[0].resource.target.averageValue", field.ErrorTypeRequired),
+		Entry("missing average utilization", func(spec *v1alpha1.KratosSpec) {
+			spec.Metrics[0].Resource.Target.Type = v1alpha1.UtilizationMetricType
+		}, "spec.metrics[0].resource.target.averageUtilization", field.ErrorTypeRequired),
+		Entry("utilization of external metric", func(spec *v1alpha1.KratosSpec) {
+			utilization := int32(50)
+			spec.Metrics[0] = v1alpha1.ScaleMetric{
+				Type: v1alpha1.ExternalScaleMetricType,
+				External: &v1alpha1.ExternalMetricSource{
+					Metric: v1alpha1.MetricIdentifier{Name: "queue_length"},
+					Target: v1alpha1.MetricTarget{Type: v1alpha1.UtilizationMetricType, AverageUtilization: &utilization},
+				},
+			}
+		}, "spec.metrics[0].external.target.type", field.ErrorTypeInvalid),
+		Entry("scale to zero with resource metrics only", func(spec *v1alpha1.KratosSpec) { spec.MinReplicas = pointer.Int32Ptr(0) },
+			"spec.minReplicas", field.ErrorTypeInvalid),
+		Entry("negative activation threshold", func(spec *v1alpha1.KratosSpec) {
+			threshold := resource.MustParse("-1")
+			spec.Metrics[0].Resource.Target.ActivationThreshold = &threshold
+		}, "spec.metrics[0].resource.target.activationThreshold", field.ErrorTypeInvalid),
+		Entry("unknown mode", func(spec *v1alpha1.KratosSpec) { spec.Mode = "Shadow" },
+			"spec.mode", field.ErrorTypeNotSupported),
+		Entry("idle cooldown out of range", func(spec *v1alpha1.KratosSpec) { spec.IdleCooldownSeconds = 100000 },
+			"spec.idleCooldownSeconds", field.ErrorTypeInvalid),
+		Entry("policy period out of range", func(spec *v1alpha1.KratosSpec) {
+			spec.Behavior = &v1alpha1.ScaleBehavior{
+				ScaleUp: &v1alpha1.ScaleRules{
+					SelectPolicy: v1alpha1.MaxPolicySelect,
+					Policies:     []v1alpha1.ScalingPolicy{{Type: v1alpha1.PodsScalingPolicy, Value: 2, PeriodSeconds: 3600}},
+				},
+			}
+		}, "spec.behavior.scaleUp.policies[0].periodSeconds", field.ErrorTypeInvalid),
+		Entry("enabled scale rules without policies", func(spec *v1alpha1.KratosSpec) {
+			spec.Behavior = &v1alpha1.ScaleBehavior{ScaleDown: &v1alpha1.ScaleRules{SelectPolicy: v1alpha1.MinPolicySelect}}
+		}, "spec.behavior.scaleDown.policies", field.ErrorTypeRequired),
+		Entry("fallback replicas out of range", func(spec *v1alpha1.KratosSpec) {
+			spec.Fallback = &v1alpha1.Fallback{Policy: v1alpha1.ReplicasFallbackPolicy, Replicas: 10, FailureThreshold: 3}
+		}, "spec.fallback.replicas", field.ErrorTypeInvalid),
+		Entry("prediction season not a multiple of step", func(spec *v1alpha1.KratosSpec) {
+			spec.Metrics[0] = prometheusMetric(&v1alpha1.Prediction{
+				Mode: v1alpha1.ScalePredictionMode, Model: v1alpha1.HoltWintersPredictionModel, HistorySeasons: 2,
+				Season: metav1.Duration{Duration: 24 * time.Hour}, Step: metav1.Duration{Duration: 7 * time.Minute}, LeadTime: metav1.Duration{Duration: 10 * time.Minute},
+			})
+		}, "spec.metrics[0].prometheus.prediction.season", field.ErrorTypeInvalid),
+		Entry("prediction lead time longer than season", func(spec *v1alpha1.KratosSpec) {
+			spec.Metrics[0] = prometheusMetric(&v1alpha1.Prediction{
+				Mode: v1alpha1.ObservePredictionMode, Model: v1alpha1.SeasonalNaivePredictionModel, HistorySeasons: 2,
+				Season: metav1.Duration{Duration: time.Hour}, Step: metav1.Duration{Duration: 5 * time.Minute}, LeadTime: metav1.Duration{Duration: 2 * time.Hour},
+			})
+		}, "spec.metrics[0].prometheus.prediction.leadTime", field.ErrorTypeInvalid),
+		Entry("prediction with one season of history", func(spec *v1alpha1.KratosSpec) {
+			spec.Metrics[0] = prometheusMetric(&v1alpha1.Prediction{
+				Mode: v1alpha1.ObservePredictionMode, Model: v1alpha1.SeasonalNaivePredictionModel, HistorySeasons: 1,
+				Season: metav1.Duration{Duration: time.Hour}, Step: metav1.Duration{Duration: 5 * time.Minute}, LeadTime: metav1.Duration{Duration: 5 * time.Minute},
+			})
+		}, "spec.metrics[0].prometheus.prediction.historySeasons", field.ErrorTypeInvalid),
+		Entry("invalid auth secret name", func(spec *v1alpha1.KratosSpec) {
+			spec.Metrics[0] = prometheusMetric(nil)
+			spec.Metrics[0].Prometheus.AuthSecretName = "Prometheus_Credentials"
+		}, "spec.metrics[0].prometheus.authSecretName", field.ErrorTypeInvalid),
+		Entry("prometheus range without window", func(spec *v1alpha1.KratosSpec) {
+			spec.Metrics[0] = prometheusMetric(nil)
+			spec.Metrics[0].Prometheus.Range = &v1alpha1.PrometheusRange{Aggregation: v1alpha1.MaxAggregation}
+		}, "spec.metrics[0].prometheus.range.window", field.ErrorTypeInvalid),
+		Entry("prometheus range with too many steps", func(spec *v1alpha1.KratosSpec) {
+			spec.Metrics[0] = prometheusMetric(nil)
+			spec.Metrics[0].Prometheus.Range = &v1alpha1.PrometheusRange{Window: metav1.Duration{Duration: time.Hour},
+				Step: metav1.Duration{Duration: time.Second}, Aggregation: v1alpha1.MaxAggregation}
+		}, "spec.metrics[0].prometheus.range.step", field.ErrorTypeInvalid),
+		Entry("unknown prometheus range aggregation", func(spec *v1alpha1.KratosSpec) {
+			spec.Metrics[0] = prometheusMetric(nil)
+			spec.Metrics[0].Prometheus.Range = &v1alpha1.PrometheusRange{Window: metav1.Duration{Duration: 5 * time.Minute},
+				Step: metav1.Duration{Duration: 30 * time.Second}, Aggregation: "Median"}
+		}, "spec.metrics[0].prometheus.range.aggregation", field.ErrorTypeNotSupported),
+		Entry("unknown query placeholder", func(spec *v1alpha1.KratosSpec) {
+			spec.Metrics[0] = prometheusMetric(nil)
+			spec.Metrics[0].Prometheus.MetricQuery = `sum(rate(requests{namespace="{{ .Namespace }}",deployment="{{ .Deployment }}"}[1m]))`
+		}, "spec.metrics[0].prometheus.metricQuery", field.ErrorTypeInvalid),
+		Entry("kafka broker without port", func(spec *v1alpha1.KratosSpec) {
+			spec.Metrics[0] = kafkaMetric()
+			spec.Metrics[0].Kafka.Brokers = []string{"kafka-0.kafka"}
+		}, "spec.metrics[0].kafka.brokers[0]", field.ErrorTypeInvalid),
+		Entry("kafka without consumer group", func(spec *v1alpha1.KratosSpec) {
+			spec.Metrics[0] = kafkaMetric()
+			spec.Metrics[0].Kafka.ConsumerGroup = ""
+		}, "spec.metrics[0].kafka.consumerGroup", field.ErrorTypeRequired),
+		Entry("kafka without topics", func(spec *v1alpha1.KratosSpec) {
+			spec.Metrics[0] = kafkaMetric()
+			spec.Metrics[0].Kafka.Topics = nil
+		}, "spec.metrics[0].kafka.topics", field.ErrorTypeRequired),
+		Entry("unknown kafka lag", func(spec *v1alpha1.KratosSpec) {
+			spec.Metrics[0] = kafkaMetric()
+			spec.Metrics[0].Kafka.Lag = "Average"
+		}, "spec.metrics[0].kafka.lag", field.ErrorTypeNotSupported),
+		Entry("utilization of kafka metric", func(spec *v1alpha1.KratosSpec) {
+			utilization := int32(50)
+			spec.Metrics[0] = kafkaMetric()
+			spec.Metrics[0].Kafka.Target = v1alpha1.MetricTarget{Type: v1alpha1.UtilizationMetricType, AverageUtilization: &utilization}
+		}, "spec.metrics[0].kafka.target.type", field.ErrorTypeInvalid),
+		Entry("rabbitmq management url without scheme", func(spec *v1alpha1.KratosSpec) {
+			spec.Metrics[0] = rabbitMQMetric()
+			spec.Metrics[0].RabbitMQ.ManagementUrl = "rabbitmq.messaging:15672"
+		}, "spec.metrics[0].rabbitmq.managementUrl", field.ErrorTypeInvalid),
+		Entry("rabbitmq without queue", func(spec *v1alpha1.KratosSpec) {
+			spec.Metrics[0] = rabbitMQMetric()
+			spec.Metrics[0].RabbitMQ.Queue = ""
+		}, "spec.metrics[0].rabbitmq.queue", field.ErrorTypeRequired),
+		Entry("unknown rabbitmq queue metric", func(spec *v1alpha1.KratosSpec) {
+			spec.Metrics[0] = rabbitMQMetric()
+			spec.Metrics[0].RabbitMQ.QueueMetric = "Consumers"
+		}, "spec.metrics[0].rabbitmq.queueMetric", field.ErrorTypeNotSupported),
+		Entry("rabbitmq without auth secret", func(spec *v1alpha1.KratosSpec) {
+			spec.Metrics[0] = rabbitMQMetric()
+			spec.Metrics[0].RabbitMQ.AuthSecretName = ""
+		}, "spec.metrics[0].rabbitmq.authSecretName", field.ErrorTypeRequired),
+		Entry("invalid schedule cron", func(spec *v1alpha1.KratosSpec) {
+			replicas := int32(2)
+			spec.Schedules = []v1alpha1.Schedule{{Name: "night", Cron: "0 22 * * * *", Duration: metav1.Duration{Duration: 8 * time.Hour}, Replicas: &replicas}}
+		}, "spec.schedules[0].cron", field.ErrorTypeInvalid),
+		Entry("invalid schedule time zone", func(spec *v1alpha1.KratosSpec) {
+			replicas := int32(2)
+			spec.Schedules = []v1alpha1.Schedule{{Name: "night", Cron: "0 22 * * *", TimeZone: "Mars/Olympus", Duration: metav1.Duration{Duration: 8 * time.Hour}, Replicas: &replicas}}
+		}, "spec.schedules[0].timeZone", field.ErrorTypeInvalid),
+		Entry("schedule duration too long", func(spec *v1alpha1.KratosSpec) {
+			replicas := int32(2)
+			spec.Schedules = []v1alpha1.Schedule{{Name: "night", Cron: "0 22 * * *", Duration: metav1.Duration{Duration: 200 * time.Hour}, Replicas: &replicas}}
+		}, "spec.schedules[0].duration", field.ErrorTypeInvalid),
+		Entry("schedule without replicas", func(spec *v1alpha1.KratosSpec) {
+			spec.Schedules = []v1alpha1.Schedule{{Name: "night", Cron: "0 22 * * *", Duration: metav1.Duration{Duration: 8 * time.Hour}}}
+		}, "spec.schedules[0]", field.ErrorTypeRequired),
+	)
+})
@@ -0,0 +1,50 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package webhooks
+
+import (
+	"github.com/adobe/kratos/api/common"
+	"github.com/adobe/kratos/replicas"
+	"github.com/adobe/kratos/scale"
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupWithManager registers the defaulting and validating webhooks with the webhook server of the manager
+func SetupWithManager(mgr ctrl.Manager, params *common.KratosParameters) {
+	defaultsUpdater := scale.NewDefaultsUpdater(params)
+	algorithms := replicas.NewAlgorithmRegistry()
+
+	server := mgr.GetWebhookServer()
+	server.Register("/mutate-scaling-core-adobe-com-v1alpha1-kratos", &webhook.Admission{Handler: &kratosDefaulter{defaultsUpdater: defaultsUpdater}})
+	server.Register("/validate-scaling-core-adobe-com-v1alpha1-kratos", &webhook.Admission{Handler: &kratosValidator{defaultsUpdater: defaultsUpdater, algorithms: algorithms}})
+	server.Register("/validate-v1-configmap", &webhook.Admission{Handler: &configMapValidator{defaultsUpdater: defaultsUpdater, algorithms: algorithms}})
+}
+
+// invalidResponse denies the request with the field errors, kubectl shows them like errors of built-in resources
+func invalidResponse(groupKind schema.GroupKind, name string, errs field.ErrorList) admission.Response {
+	status := apierrors.NewInvalid(groupKind, name, errs).Status()
+
+	return admission.Response{
+		AdmissionResponse: admissionv1.AdmissionResponse{
+			Allowed: false,
+			Result:  &status,
+		},
+	}
+}
@@ -0,0 +1,198 @@
+/*
+
+Copyright 2020 Adobe
+All Rights Reserved.
+
+NOTICE: Adobe permits you to use, modify, and distribute this file in
+accordance with the terms of the Adobe license agreement accompanying
+it. If you have received this file from a source other than Adobe,
+then your use, modification, or distribution of it requires the prior
+written permission of Adobe.
+
+*/
+
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/adobe/kratos/api/common"
+	"github.com/adobe/kratos/api/v1alpha1"
+	"github.com/adobe/kratos/replicas"
+	"github.com/adobe/kratos/scale"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func admissionRequest(object interface{}) admission.Request {
+	raw, err := json.Marshal(object)
+	Expect(err).To(BeNil())
+
+	return admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	}
+}
+
+const resourceScalerSpec = `
+minReplicas: 1
+maxReplicas: 4
+target:
+  kind: Deployment
+  name: nginx
+metrics:
+  - type: Resource
+    resource:
+      name: memory
+      target:
+        type: Utilization
+        %s: 50
+`
+
+var _ = Describe("Webhooks", func() {
+	defaultsUpdater := scale.NewDefaultsUpdater(&common.KratosParameters{StabilizationWindowSeconds: 120})
+	algorithms := replicas.NewAlgorithmRegistry()
+
+	It("Kratos defaults", func() {
+		kratos := &v1alpha1.Kratos{ObjectMeta: metav1.ObjectMeta{Name: "nginx"}, Spec: *validSpec()}
+		kratos.Spec.Algorithm.Type = ""
+		kratos.Spec.StabilizationWindowSeconds = 0
+
+		response := (&kratosDefaulter{defaultsUpdater: defaultsUpdater}).Handle(context.TODO(), admissionRequest(kratos))
+
+		patchedPaths := make([]string, 0)
+		for _, patch := range response.Patches {
+			patchedPaths = append(patchedPaths, patch.Path)
+		}
+
+		Expect(response.Allowed).To(BeTrue())
+		Expect(patchedPaths).To(ContainElement("/spec/algorithm/type"))
+		Expect(patchedPaths).To(ContainElement("/spec/stabilizationWindowSeconds"))
+	})
+
+	It("Invalid Kratos denied with field errors", func() {
+		kratos := &v1alpha1.Kratos{ObjectMeta: metav1.ObjectMeta{Name: "nginx"}, Spec: *validSpec()}
+		kratos.Spec.MinReplicas = pointer.Int32Ptr(10)
+
+		response := (&kratosValidator{defaultsUpdater: defaultsUpdater, algorithms: algorithms}).Handle(context.TODO(), admissionRequest(kratos))
+
+		Expect(response.Allowed).To(BeFalse())
+		Expect(response.Result.Reason).To(Equal(metav1.StatusReasonInvalid))
+		Expect(response.Result.Details.Causes).To(HaveLen(1))
+		Expect(response.Result.Details.Causes[0].Field).To(Equal("spec.minReplicas"))
+	})
+
+	DescribeTable("Negative values denied",
+		func(update func(spec *v1alpha1.KratosSpec), fieldPath string) {
+			kratos := &v1alpha1.Kratos{ObjectMeta: metav1.ObjectMeta{Name: "nginx"}, Spec: *validSpec()}
+			update(&kratos.Spec)
+
+			defaulted := (&kratosDefaulter{defaultsUpdater: defaultsUpdater}).Handle(context.TODO(), admissionRequest(kratos))
+			for _, patch := range defaulted.Patches {
+				Expect(patch.Path).NotTo(Equal("/"+strings.ReplaceAll(fieldPath, ".", "/")), "negative value should not be defaulted")
+			}
+
+			response := (&kratosValidator{defaultsUpdater: defaultsUpdater, algorithms: algorithms}).Handle(context.TODO(), admissionRequest(kratos))
+
+			Expect(response.Allowed).To(BeFalse())
+			fields := make([]string, 0)
+			for _, cause := range response.Result.Details.Causes {
+				fields = append(fields, cause.Field)
+			}
+			Expect(fields).To(ContainElement(fieldPath))
+		},
+		Entry("min replicas", func(spec *v1alpha1.KratosSpec) { spec.MinReplicas = pointer.Int32Ptr(-1) }, "spec.minReplicas"),
+		Entry("max replicas", func(spec *v1alpha1.KratosSpec) { spec.MaxReplicas = -4 }, "spec.maxReplicas"),
+		Entry("stabilization window", func(spec *v1alpha1.KratosSpec) { spec.StabilizationWindowSeconds = -60 }, "spec.stabilizationWindowSeconds"),
+		Entry("idle cooldown", func(spec *v1alpha1.KratosSpec) { spec.IdleCooldownSeconds = -300 }, "spec.idleCooldownSeconds"),
+		Entry("fallback failure threshold", func(spec *v1alpha1.KratosSpec) {
+			spec.Fallback = &v1alpha1.Fallback{Policy: v1alpha1.HoldFallbackPolicy, FailureThreshold: -3}
+		}, "spec.fallback.failureThreshold"),
+	)
+
+	It("ConfigMap with negative replicas denied", func() {
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "nginx-scaler"},
+			Data:       map[string]string{specKey: strings.Replace(fmt.Sprintf(resourceScalerSpec, "averageUtilization"), "minReplicas: 1", "minReplicas: -1", 1)},
+		}
+
+		response := (&configMapValidator{defaultsUpdater: defaultsUpdater, algorithms: algorithms}).Handle(context.TODO(), admissionRequest(configMap))
+
+		Expect(response.Allowed).To(BeFalse())
+		Expect(response.Result.Details.Causes[0].Field).To(Equal("data[kratosSpec].minReplicas"))
+	})
+
+	It("Resource metrics without minReplicas allowed", func() {
+		// a spec written before scaling to zero, min replicas default to 1
+		kratos := &v1alpha1.Kratos{ObjectMeta: metav1.ObjectMeta{Name: "nginx"}, Spec: *validSpec()}
+		kratos.Spec.MinReplicas = nil
+
+		defaulted := (&kratosDefaulter{defaultsUpdater: defaultsUpdater}).Handle(context.TODO(), admissionRequest(kratos))
+
+		patchedPaths := make([]string, 0)
+		for _, patch := range defaulted.Patches {
+			patchedPaths = append(patchedPaths, patch.Path)
+		}
+
+		Expect(defaulted.Allowed).To(BeTrue())
+		Expect(patchedPaths).To(ContainElement("/spec/minReplicas"))
+
+		response := (&kratosValidator{defaultsUpdater: defaultsUpdater, algorithms: algorithms}).Handle(context.TODO(), admissionRequest(kratos))
+		Expect(response.Allowed).To(BeTrue(), "omitted min replicas should not enable scaling to zero")
+
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "nginx-scaler"},
+			Data:       map[string]string{specKey: strings.Replace(fmt.Sprintf(resourceScalerSpec, "averageUtilization"), "minReplicas: 1\n", "", 1)},
+		}
+
+		response = (&configMapValidator{defaultsUpdater: defaultsUpdater, algorithms: algorithms}).Handle(context.TODO(), admissionRequest(configMap))
+		Expect(response.Allowed).To(BeTrue(), "omitted min replicas should not enable scaling to zero")
+	})
+
+	It("Valid ConfigMap allowed", func() {
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "nginx-scaler"},
+			Data:       map[string]string{specKey: fmt.Sprintf(resourceScalerSpec, "averageUtilization")},
+		}
+
+		response := (&configMapValidator{defaultsUpdater: defaultsUpdater, algorithms: algorithms}).Handle(context.TODO(), admissionRequest(configMap))
+
+		Expect(response.Allowed).To(BeTrue())
+	})
+
+	It("ConfigMap with unknown spec field denied", func() {
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "nginx-scaler"},
+			Data:       map[string]string{specKey: fmt.Sprintf(resourceScalerSpec, "averageUalue")},
+		}
+
+		response := (&configMapValidator{defaultsUpdater: defaultsUpdater, algorithms: algorithms}).Handle(context.TODO(), admissionRequest(configMap))
+
+		Expect(response.Allowed).To(BeFalse())
+		Expect(response.Result.Details.Causes[0].Field).To(Equal("data[kratosSpec]"))
+		Expect(response.Result.Details.Causes[0].Message).To(ContainSubstring("averageUalue"))
+	})
+
+	It("ConfigMap without spec allowed", func() {
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "settings"},
+			Data:       map[string]string{"key": "value"},
+		}
+
+		response := (&configMapValidator{defaultsUpdater: defaultsUpdater, algorithms: algorithms}).Handle(context.TODO(), admissionRequest(configMap))
+
+		Expect(response.Allowed).To(BeTrue())
+	})
+})